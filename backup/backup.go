@@ -0,0 +1,48 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidBackup is returned when archive can't be restored
+var ErrInvalidBackup = errors.New("Invalid backup")
+
+const (
+	// KindDFPConfig is the DFP configs of archive
+	KindDFPConfig = "dfp_configs"
+
+	// KindDFPState is the DFP states of archive
+	KindDFPState = "dfp_states"
+
+	// KindTFPConfig is the TFP configs of archive
+	KindTFPConfig = "tfp_configs"
+
+	// KindTFPState is the TFP states of archive
+	KindTFPState = "tfp_states"
+
+	// KindTankConfig is the tank configs of archive
+	KindTankConfig = "tank_configs"
+)
+
+// Usecase is the backup interface
+type Usecase interface {
+	// Register add the usecase used to backup and restore the records of kind
+	Register(kind string, us usecase.UsecaseCRUD)
+
+	// Backup return the archive of all records, with the events of last days when eventsDays > 0
+	Backup(ctx context.Context, eventsDays int) (*models.Backup, error)
+
+	// Restore check the archive, then write all records through their usecase.
+	// It return ErrInvalidBackup when archive is not valid
+	Restore(ctx context.Context, archive *models.Backup) error
+
+	// Start write backup on local directory periodically
+	Start(ctx context.Context) error
+
+	// Stop the periodic backup
+	Stop(ctx context.Context)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/disaster37/gobot-fat/backup"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/google/jsonapi"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// BackupHandler represent the httphandler for backup
+type BackupHandler struct {
+	dUsecase backup.Usecase
+}
+
+// NewBackupHandler will initialize the backup and restore endpoints
+func NewBackupHandler(e *echo.Group, us backup.Usecase, m ...echo.MiddlewareFunc) {
+	handler := &BackupHandler{
+		dUsecase: us,
+	}
+	e.GET("/backup", handler.Backup, m...)
+	e.POST("/restore", handler.Restore, m...)
+}
+
+// Backup return the JSON archive of all configs and states
+// The query parameter `events_days` add the events of last days
+func (h *BackupHandler) Backup(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	eventsDays := 0
+	if c.QueryParam("events_days") != "" {
+		days, err := strconv.Atoi(c.QueryParam("events_days"))
+		if err != nil || days < 0 {
+			return marshalError(c, http.StatusBadRequest, "Error when backup", fmt.Errorf("Invalid events_days: %s", c.QueryParam("events_days")))
+		}
+		eventsDays = days
+	}
+
+	archive, err := h.dUsecase.Backup(ctx, eventsDays)
+	if err != nil {
+		log.Errorf("Error when backup: %s", err.Error())
+		return marshalError(c, http.StatusInternalServerError, "Error when backup", err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"backup-%s.json\"", archive.CreatedAt.Format("20060102-150405")))
+	return c.JSON(http.StatusOK, archive)
+}
+
+// Restore check the archive and write all configs and states
+func (h *BackupHandler) Restore(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	archive := &models.Backup{}
+	if err := json.NewDecoder(c.Request().Body).Decode(archive); err != nil {
+		return marshalError(c, http.StatusBadRequest, "Error when restore", err)
+	}
+
+	if err := h.dUsecase.Restore(ctx, archive); err != nil {
+		if errors.Is(err, backup.ErrInvalidBackup) {
+			return marshalError(c, http.StatusBadRequest, "Error when restore", err)
+		}
+		log.Errorf("Error when restore: %s", err.Error())
+		return marshalError(c, http.StatusInternalServerError, "Error when restore", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// marshalError write the JSON:API error with status
+func marshalError(c echo.Context, status int, title string, err error) error {
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+	c.Response().WriteHeader(status)
+	return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+		{
+			Status: fmt.Sprintf("%d", status),
+			Title:  title,
+			Detail: err.Error(),
+		},
+	})
+}
@@ -0,0 +1,354 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/backup"
+	"github.com/disaster37/gobot-fat/event"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	fileNamePrefix = "backup-"
+	fileNameSuffix = ".json"
+	fileTimeLayout = "20060102-150405"
+)
+
+// Options are the settings of periodic backup on local directory
+type Options struct {
+	// Path is the directory where backup are written
+	Path string
+
+	// Interval is the duration between two backups
+	Interval time.Duration
+
+	// Keep is the number of backups kept on directory, older are removed
+	Keep int
+
+	// EventsDays is the number of days of events added on backup, 0 to not add them
+	EventsDays int
+}
+
+type backupUsecase struct {
+	usecases     map[string]usecase.UsecaseCRUD
+	eventUsecase event.Usecase
+	options      Options
+	chStop       chan bool
+	isStarted    bool
+	sync.Mutex
+}
+
+// NewBackupUsecase will create new backupUsecase object of backup.Usecase interface
+// The eventUsecase is used to add recent events on backup, it can be nil
+func NewBackupUsecase(eventUsecase event.Usecase, options Options) backup.Usecase {
+	return &backupUsecase{
+		usecases:     make(map[string]usecase.UsecaseCRUD),
+		eventUsecase: eventUsecase,
+		options:      options,
+	}
+}
+
+// Register add the usecase used to backup and restore the records of kind
+func (h *backupUsecase) Register(kind string, us usecase.UsecaseCRUD) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.usecases[kind] = us
+}
+
+// Backup return the archive of all records, with the events of last days when eventsDays > 0
+func (h *backupUsecase) Backup(ctx context.Context, eventsDays int) (*models.Backup, error) {
+
+	archive := &models.Backup{
+		FormatVersion: models.BackupFormatVersion,
+		CreatedAt:     time.Now(),
+		DFPConfigs:    make([]*models.DFPConfig, 0),
+		DFPStates:     make([]*models.DFPState, 0),
+		TFPConfigs:    make([]*models.TFPConfig, 0),
+		TFPStates:     make([]*models.TFPState, 0),
+		TankConfigs:   make([]*models.TankConfig, 0),
+	}
+
+	lists := map[string]interface{}{
+		backup.KindDFPConfig:  &archive.DFPConfigs,
+		backup.KindDFPState:   &archive.DFPStates,
+		backup.KindTFPConfig:  &archive.TFPConfigs,
+		backup.KindTFPState:   &archive.TFPStates,
+		backup.KindTankConfig: &archive.TankConfigs,
+	}
+	for kind, listData := range lists {
+		us := h.usecase(kind)
+		if us == nil {
+			continue
+		}
+		if err := us.List(ctx, listData); err != nil {
+			return nil, errors.Wrapf(err, "Error when list %s", kind)
+		}
+	}
+
+	if eventsDays > 0 && h.eventUsecase != nil {
+		events, err := h.events(ctx, time.Now().AddDate(0, 0, -eventsDays))
+		if err != nil {
+			return nil, errors.Wrap(err, "Error when search events")
+		}
+		archive.Events = events
+	}
+
+	return archive, nil
+}
+
+// Restore check the archive, then write all records through their usecase.
+// Configs are restored before states. Events are not restored.
+func (h *backupUsecase) Restore(ctx context.Context, archive *models.Backup) error {
+
+	records, err := h.check(archive)
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range restoreOrder {
+		us := h.usecase(kind)
+		for _, record := range records[kind] {
+			if err = h.restore(ctx, us, record); err != nil {
+				return errors.Wrapf(err, "Error when restore %s %d", kind, record.GetID())
+			}
+		}
+		log.Infof("Restore %d %s successfully", len(records[kind]), kind)
+	}
+
+	return nil
+}
+
+// Start write backup on local directory now, then every interval
+func (h *backupUsecase) Start(ctx context.Context) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.isStarted {
+		return nil
+	}
+	if h.options.Interval <= 0 {
+		return errors.New("Backup interval must be greater than 0")
+	}
+	if err := os.MkdirAll(h.options.Path, 0750); err != nil {
+		return err
+	}
+	h.chStop = make(chan bool)
+	h.isStarted = true
+
+	go func(chStop chan bool) {
+		ticker := time.NewTicker(h.options.Interval)
+		defer ticker.Stop()
+		for {
+			if err := h.write(ctx); err != nil {
+				log.Errorf("Error when write backup: %s", err.Error())
+			}
+			select {
+			case <-chStop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}(h.chStop)
+
+	return nil
+}
+
+// Stop periodic backup
+func (h *backupUsecase) Stop(ctx context.Context) {
+	h.Lock()
+	defer h.Unlock()
+
+	if !h.isStarted {
+		return
+	}
+	close(h.chStop)
+	h.isStarted = false
+}
+
+// restoreOrder is the order records are restored, configs before states
+var restoreOrder = []string{
+	backup.KindDFPConfig,
+	backup.KindTFPConfig,
+	backup.KindTankConfig,
+	backup.KindDFPState,
+	backup.KindTFPState,
+}
+
+// configKinds are the kinds validated before restore, like when they are updated from API
+var configKinds = map[string]bool{
+	backup.KindDFPConfig:  true,
+	backup.KindTFPConfig:  true,
+	backup.KindTankConfig: true,
+}
+
+func (h *backupUsecase) usecase(kind string) usecase.UsecaseCRUD {
+	h.Lock()
+	defer h.Unlock()
+
+	return h.usecases[kind]
+}
+
+// check return the records of archive by kind, or all problems found wrapped with ErrInvalidBackup
+func (h *backupUsecase) check(archive *models.Backup) (map[string][]models.Model, error) {
+
+	if archive == nil {
+		return nil, errors.Wrap(backup.ErrInvalidBackup, "Backup can't be null")
+	}
+	if archive.FormatVersion != models.BackupFormatVersion {
+		return nil, errors.Wrapf(backup.ErrInvalidBackup, "Format version %d not supported, expected %d", archive.FormatVersion, models.BackupFormatVersion)
+	}
+
+	records := map[string][]models.Model{
+		backup.KindDFPConfig:  toModels(archive.DFPConfigs),
+		backup.KindDFPState:   toModels(archive.DFPStates),
+		backup.KindTFPConfig:  toModels(archive.TFPConfigs),
+		backup.KindTFPState:   toModels(archive.TFPStates),
+		backup.KindTankConfig: toModels(archive.TankConfigs),
+	}
+
+	problems := make([]string, 0)
+	for _, kind := range restoreOrder {
+		if len(records[kind]) > 0 && h.usecase(kind) == nil {
+			problems = append(problems, fmt.Sprintf("%s can't be restored on this instance", kind))
+			continue
+		}
+		for i, record := range records[kind] {
+			if record == nil || reflect.ValueOf(record).IsNil() {
+				problems = append(problems, fmt.Sprintf("%s[%d] can't be null", kind, i))
+				continue
+			}
+			if record.GetID() == 0 {
+				problems = append(problems, fmt.Sprintf("%s[%d] has no ID", kind, i))
+			}
+			if !configKinds[kind] {
+				continue
+			}
+			if err := models.Validate(record); err != nil {
+				problems = append(problems, fmt.Sprintf("%s[%d] %s", kind, i, err.Error()))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, errors.Wrap(backup.ErrInvalidBackup, strings.Join(problems, "; "))
+	}
+
+	return records, nil
+}
+
+// restore update the current record, or create it if not exist
+// The version of archive is ignored, the record get the next version
+func (h *backupUsecase) restore(ctx context.Context, us usecase.UsecaseCRUD, record models.Model) error {
+
+	current := reflect.New(reflect.TypeOf(record).Elem()).Interface().(models.Model)
+	err := us.Get(ctx, record.GetID(), current)
+	if err != nil {
+		if repository.IsRecordNotFoundError(err) {
+			return us.Create(ctx, record)
+		}
+		return err
+	}
+
+	record.SetVersion(current.GetVersion())
+	return us.Update(ctx, record)
+}
+
+// events return all events since date, from the oldest
+func (h *backupUsecase) events(ctx context.Context, from time.Time) ([]*models.Event, error) {
+	events := make([]*models.Event, 0)
+	for page := 1; ; page++ {
+		result, total, err := h.eventUsecase.Search(ctx, &models.EventFilter{
+			From: from,
+			Sort: "timestamp",
+			Page: page,
+			Size: event.MaxPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, result...)
+		if len(result) == 0 || int64(len(events)) >= total {
+			return events, nil
+		}
+	}
+}
+
+// write backup on local directory, then remove the older ones
+func (h *backupUsecase) write(ctx context.Context) error {
+
+	archive, err := h.Backup(ctx, h.options.EventsDays)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(archive)
+	if err != nil {
+		return err
+	}
+
+	// Write on temporary file first, so a partial backup is never kept
+	fileName := filepath.Join(h.options.Path, fmt.Sprintf("%s%s%s", fileNamePrefix, archive.CreatedAt.Format(fileTimeLayout), fileNameSuffix))
+	if err = os.WriteFile(fileName+".tmp", b, 0640); err != nil {
+		return err
+	}
+	if err = os.Rename(fileName+".tmp", fileName); err != nil {
+		return err
+	}
+	log.Infof("Write backup %s successfully", fileName)
+
+	return h.prune()
+}
+
+// prune remove the older backups to only keep the number expected
+func (h *backupUsecase) prune() error {
+	if h.options.Keep <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(h.options.Path)
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), fileNamePrefix) && strings.HasSuffix(entry.Name(), fileNameSuffix) {
+			files = append(files, entry.Name())
+		}
+	}
+	if len(files) <= h.options.Keep {
+		return nil
+	}
+
+	// File name contain the date, so the oldest are first
+	sort.Strings(files)
+	for _, file := range files[:len(files)-h.options.Keep] {
+		if err = os.Remove(filepath.Join(h.options.Path, file)); err != nil {
+			return err
+		}
+		log.Infof("Remove old backup %s", file)
+	}
+
+	return nil
+}
+
+// toModels convert the slice of records to slice of models.Model
+func toModels(list interface{}) []models.Model {
+	value := reflect.ValueOf(list)
+	records := make([]models.Model, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		records = append(records, value.Index(i).Interface().(models.Model))
+	}
+	return records
+}
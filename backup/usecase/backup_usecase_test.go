@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/backup"
+	eventusecase "github.com/disaster37/gobot-fat/event/usecase"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gobot.io/x/gobot/v2"
+)
+
+func TestBackup(t *testing.T) {
+	conn, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "gobot-fat.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.AutoMigrate(&models.TankConfig{}, &models.DFPState{}, &models.Event{}); err != nil {
+		t.Fatal(err)
+	}
+	eventer := gobot.NewEventer()
+	eventer.AddEvent("newTankConfig")
+	eventer.AddEvent("newDFPState")
+	chEvent := eventer.Subscribe()
+
+	tankConfigUsecase := usecase.NewUsecase(repository.NewSQLRepository(conn), repository.NewNoopRepository(), 10*time.Second, eventer, "newTankConfig")
+	dfpStateUsecase := usecase.NewUsecase(repository.NewSQLRepository(conn), repository.NewNoopRepository(), 10*time.Second, eventer, "newDFPState")
+	eventRepo := repository.NewSQLRepository(conn)
+	backupUsecase := NewBackupUsecase(eventusecase.NewEventUsecase(eventRepo, 10*time.Second), Options{})
+	backupUsecase.Register(backup.KindTankConfig, tankConfigUsecase)
+	backupUsecase.Register(backup.KindDFPState, dfpStateUsecase)
+
+	config := &models.TankConfig{Name: "pond", Depth: 200, LiterPerCm: 50}
+	config.ID = 1
+	assert.NoError(t, tankConfigUsecase.Create(context.Background(), config))
+	config.Depth = 150
+	assert.NoError(t, tankConfigUsecase.Update(context.Background(), config))
+	state := &models.DFPState{Name: "dfp", IsRunning: true}
+	state.ID = 1
+	assert.NoError(t, dfpStateUsecase.Create(context.Background(), state))
+	assert.NoError(t, eventRepo.Create(context.Background(), &models.Event{SourceName: "dfp", EventKind: "wash", Timestamp: time.Now().Add(-1 * time.Hour)}))
+	assert.NoError(t, eventRepo.Create(context.Background(), &models.Event{SourceName: "dfp", EventKind: "wash", Timestamp: time.Now().AddDate(0, 0, -3)}))
+
+	// Backup
+	archive, err := backupUsecase.Backup(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BackupFormatVersion, archive.FormatVersion)
+	assert.Len(t, archive.TankConfigs, 1)
+	assert.Equal(t, int64(150), archive.TankConfigs[0].Depth)
+	assert.Len(t, archive.DFPStates, 1)
+	assert.Empty(t, archive.DFPConfigs)
+	assert.Nil(t, archive.Events)
+
+	// Backup with events of last days
+	archiveWithEvents, err := backupUsecase.Backup(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Len(t, archiveWithEvents.Events, 1)
+
+	// Restore update records with next version and publish them
+	config.Depth = 100
+	assert.NoError(t, tankConfigUsecase.Update(context.Background(), config))
+	for len(chEvent) > 0 {
+		<-chEvent
+	}
+	assert.NoError(t, backupUsecase.Restore(context.Background(), archive))
+	restoredConfig := &models.TankConfig{}
+	assert.NoError(t, tankConfigUsecase.Get(context.Background(), 1, restoredConfig))
+	assert.Equal(t, int64(150), restoredConfig.Depth)
+	assert.Equal(t, int64(3), restoredConfig.Version)
+	timeout := time.After(1 * time.Second)
+	isPublished := false
+	for !isPublished {
+		select {
+		case evt := <-chEvent:
+			if evt.Name == "newTankConfig" && evt.Data.(*models.TankConfig).Version == 3 {
+				isPublished = true
+			}
+		case <-timeout:
+			t.Fatal("Restored config not published")
+		}
+	}
+
+	// Restore create missing records
+	newConfig := &models.TankConfig{Name: "garden", Depth: 120, LiterPerCm: 30}
+	newConfig.ID = 2
+	archive.TankConfigs = append(archive.TankConfigs, newConfig)
+	assert.NoError(t, backupUsecase.Restore(context.Background(), archive))
+	createdConfig := &models.TankConfig{}
+	assert.NoError(t, tankConfigUsecase.Get(context.Background(), 2, createdConfig))
+	assert.Equal(t, "garden", createdConfig.Name)
+
+	// Invalid archives are not restored
+	err = backupUsecase.Restore(context.Background(), &models.Backup{FormatVersion: 99})
+	assert.True(t, errors.Is(err, backup.ErrInvalidBackup))
+	invalidConfig := &models.TankConfig{Name: "pond", Depth: 0, LiterPerCm: 50}
+	invalidConfig.ID = 1
+	err = backupUsecase.Restore(context.Background(), &models.Backup{FormatVersion: models.BackupFormatVersion, TankConfigs: []*models.TankConfig{invalidConfig}})
+	assert.True(t, errors.Is(err, backup.ErrInvalidBackup))
+	err = backupUsecase.Restore(context.Background(), &models.Backup{FormatVersion: models.BackupFormatVersion, TFPConfigs: []*models.TFPConfig{{Mode: "none"}}})
+	assert.True(t, errors.Is(err, backup.ErrInvalidBackup))
+	restoredConfig = &models.TankConfig{}
+	assert.NoError(t, tankConfigUsecase.Get(context.Background(), 1, restoredConfig))
+	assert.Equal(t, int64(150), restoredConfig.Depth)
+}
+
+func TestBackupOnDirectory(t *testing.T) {
+	path := t.TempDir()
+	us := NewBackupUsecase(nil, Options{Path: path, Keep: 2}).(*backupUsecase)
+
+	// Keep only the newest backups
+	for _, name := range []string{"backup-20200101-000000.json", "backup-20200102-000000.json", "other.json"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(path, name), []byte("{}"), 0640))
+	}
+	assert.NoError(t, us.write(context.Background()))
+	entries, err := os.ReadDir(path)
+	assert.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Len(t, names, 3)
+	assert.Contains(t, names, "backup-20200102-000000.json")
+	assert.Contains(t, names, "other.json")
+	assert.NotContains(t, names, "backup-20200101-000000.json")
+
+	// Start need interval
+	assert.Error(t, us.Start(context.Background()))
+}
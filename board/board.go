@@ -24,6 +24,9 @@ type Board interface {
 
 	// Board return the board data
 	Board() *models.Board
+
+	// Diagnostics return the board diagnostics
+	Diagnostics() *Diagnostics
 }
 
 // NewHandler is a generic handler that run in background
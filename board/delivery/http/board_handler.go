@@ -18,12 +18,16 @@ type BoardHandler struct {
 }
 
 // NewBoardHandler will initialize the board endpoint
-func NewBoardHandler(e *echo.Group, us board.Usecase) {
+// The middlewares are only applied on actions
+func NewBoardHandler(e *echo.Group, us board.Usecase, m ...echo.MiddlewareFunc) {
 	handler := &BoardHandler{
 		dUsecase: us,
 	}
 	e.GET("/boards", handler.Boards)
 	e.GET("/boards/:id", handler.Board)
+	e.POST("/boards/:id/action/start", handler.Start, m...)
+	e.POST("/boards/:id/action/stop", handler.Stop, m...)
+	e.POST("/boards/:id/action/restart", handler.Restart, m...)
 
 }
 
@@ -66,6 +70,7 @@ func (h *BoardHandler) Board(c echo.Context) error {
 	values, err := h.dUsecase.GetBoards(ctx)
 	if err != nil {
 		log.Errorf("Error when get Board values: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -90,3 +95,69 @@ func (h *BoardHandler) Board(c echo.Context) error {
 	c.Response().WriteHeader(http.StatusOK)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), data)
 }
+
+// Start start the board on background
+func (h *BoardHandler) Start(c echo.Context) error {
+	return h.action(c, "start", h.dUsecase.Start)
+}
+
+// Stop stop the board
+func (h *BoardHandler) Stop(c echo.Context) error {
+	return h.action(c, "stop", h.dUsecase.Stop)
+}
+
+// Restart stop the board, then start it on background
+func (h *BoardHandler) Restart(c echo.Context) error {
+	return h.action(c, "restart", h.dUsecase.Restart)
+}
+
+// action run the action on board and return the board with its new state
+func (h *BoardHandler) action(c echo.Context, name string, run func(ctx context.Context, name string) error) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	boardName := c.Param("id")
+
+	if err := run(ctx, boardName); err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case board.ErrBoardNotFound:
+			status = http.StatusNotFound
+		case board.ErrBoardAlreadyStarted, board.ErrBoardAlreadyStopped, board.ErrBoardStopping:
+			status = http.StatusConflict
+		}
+		log.Errorf("Error when %s board %s: %s", name, boardName, err.Error())
+		c.Response().WriteHeader(status)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", status),
+				Title:  fmt.Sprintf("Error when %s board", name),
+				Detail: err.Error(),
+			},
+		})
+	}
+
+	values, err := h.dUsecase.GetBoards(ctx)
+	if err != nil {
+		log.Errorf("Error when get Board values: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
+				Title:  "Error when get Boards values",
+				Detail: err.Error(),
+			},
+		})
+	}
+	for _, value := range values {
+		if value.Name == boardName {
+			c.Response().WriteHeader(http.StatusOK)
+			return jsonapi.MarshalOnePayloadEmbedded(c.Response(), value)
+		}
+	}
+
+	return c.NoContent(http.StatusNotFound)
+}
@@ -0,0 +1,130 @@
+package board
+
+import (
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/models"
+)
+
+// Diagnostics record the board activity, to diagnose flaky boards
+type Diagnostics struct {
+	data models.BoardDiagnostic
+	sync.RWMutex
+}
+
+// NewDiagnostics create empty diagnostics for board
+func NewDiagnostics(name string) *Diagnostics {
+	return &Diagnostics{
+		data: models.BoardDiagnostic{
+			Name: name,
+		},
+	}
+}
+
+// Load restore the diagnostics saved before restart
+func (h *Diagnostics) Load(data *models.BoardDiagnostic) {
+	h.Lock()
+	defer h.Unlock()
+
+	name := h.data.Name
+	h.data = *data
+	h.data.Name = name
+}
+
+// Diagnostic return a copy of diagnostics
+func (h *Diagnostics) Diagnostic() *models.BoardDiagnostic {
+	h.RLock()
+	defer h.RUnlock()
+
+	data := h.data
+	return &data
+}
+
+// Polled record that board was read successfully now
+func (h *Diagnostics) Polled() {
+	h.Lock()
+	defer h.Unlock()
+
+	now := time.Now()
+	h.data.LastPoll = &now
+}
+
+// LastPoll return the last time board was read successfully, nil if never
+func (h *Diagnostics) LastPoll() *time.Time {
+	h.RLock()
+	defer h.RUnlock()
+
+	return h.data.LastPoll
+}
+
+// Requested record the time of request on board, to compute average latency
+func (h *Diagnostics) Requested(duration time.Duration) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.data.Requests++
+	latency := float64(duration) / float64(time.Millisecond)
+	h.data.AverageLatency += (latency - h.data.AverageLatency) / float64(h.data.Requests)
+}
+
+// Started record that board is started now
+func (h *Diagnostics) Started() {
+	h.Lock()
+	defer h.Unlock()
+
+	now := time.Now()
+	h.data.StartedAt = &now
+}
+
+// Stopped record that board is stopped
+func (h *Diagnostics) Stopped() {
+	h.Lock()
+	defer h.Unlock()
+
+	h.data.StartedAt = nil
+}
+
+// Rebooted record a board reboot
+func (h *Diagnostics) Rebooted() {
+	h.Lock()
+	defer h.Unlock()
+
+	h.data.RebootCount++
+}
+
+// Offline record board went offline because of error
+func (h *Diagnostics) Offline(err error) {
+	h.Lock()
+	h.data.OfflineCount++
+	h.Unlock()
+
+	h.Failed(err)
+}
+
+// Failed record the last error
+func (h *Diagnostics) Failed(err error) {
+	h.Lock()
+	defer h.Unlock()
+
+	now := time.Now()
+	h.data.LastError = err.Error()
+	h.data.LastErrorAt = &now
+}
+
+// Fill set diagnostics on board data
+func (h *Diagnostics) Fill(board *models.Board) {
+	h.RLock()
+	defer h.RUnlock()
+
+	board.LastPoll = h.data.LastPoll
+	board.StartedAt = h.data.StartedAt
+	if h.data.StartedAt != nil {
+		board.Uptime = int64(time.Since(*h.data.StartedAt).Seconds())
+	}
+	board.RebootCount = h.data.RebootCount
+	board.OfflineCount = h.data.OfflineCount
+	board.LastError = h.data.LastError
+	board.LastErrorAt = h.data.LastErrorAt
+	board.AverageLatency = h.data.AverageLatency
+}
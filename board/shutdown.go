@@ -0,0 +1,29 @@
+package board
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	// ShutdownRelaysOff force relays off when board is stopped
+	ShutdownRelaysOff = "off"
+
+	// ShutdownRelaysKeep leave relays as they are when board is stopped
+	ShutdownRelaysKeep = "keep"
+)
+
+// ShutdownRelays return the relays policy from `shutdown.relays` board setting
+// It return defaultPolicy when setting is not set or not valid
+func ShutdownRelays(configHandler *viper.Viper, defaultPolicy string) string {
+	policy := configHandler.GetString("shutdown.relays")
+	switch policy {
+	case ShutdownRelaysOff, ShutdownRelaysKeep:
+		return policy
+	case "":
+		return defaultPolicy
+	default:
+		log.Warnf("Shutdown relays policy %s not supported, use %s", policy, defaultPolicy)
+		return defaultPolicy
+	}
+}
@@ -2,21 +2,52 @@ package board
 
 import (
 	"context"
+	"errors"
 
 	"github.com/disaster37/gobot-fat/models"
 )
 
+var (
+	// ErrBoardNotFound is returned when there are no board with this name
+	ErrBoardNotFound = errors.New("Board not found")
+
+	// ErrBoardAlreadyStarted is returned when start board that is already started or try to start
+	ErrBoardAlreadyStarted = errors.New("Board is already started")
+
+	// ErrBoardAlreadyStopped is returned when stop board that is not started
+	ErrBoardAlreadyStopped = errors.New("Board is already stopped")
+
+	// ErrBoardStopping is returned when start or stop board that is stopping on background
+	ErrBoardStopping = errors.New("Board is stopping")
+)
+
 // Usecase is the board usecase interface
 type Usecase interface {
 	// GetBoards return the public board data
 	GetBoards(ctx context.Context) ([]*models.Board, error)
 
+	// Boards return the list of boards
+	Boards() []Board
+
 	// AddBoard add board on list
 	AddBoard(board Board)
 
+	// RemoveBoard stop board if needed, then remove it from list
+	RemoveBoard(ctx context.Context, name string) error
+
 	// Starts start each board
 	Starts(ctx context.Context)
 
-	// Stops stop each board
+	// Stops stop each board, in reverse order they are added
 	Stops(ctx context.Context)
+
+	// Start start board on background, and try again while it failed
+	Start(ctx context.Context, name string) error
+
+	// Stop stop board on background, or cancel its start in progress
+	// The board is on stopping state until it's stopped
+	Stop(ctx context.Context, name string) error
+
+	// Restart stop board on background if needed, then start it
+	Restart(ctx context.Context, name string) error
 }
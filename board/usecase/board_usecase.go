@@ -2,22 +2,73 @@ package usecase
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/disaster37/gobot-fat/board"
 	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// defaultRetryInterval is the time to wait before try again to start board
+	defaultRetryInterval = 10 * time.Second
+
+	// defaultDiagnosticsInterval is the time between two saves of boards diagnostics
+	defaultDiagnosticsInterval = 1 * time.Minute
+)
+
 type boardUsecase struct {
-	boards []board.Board
+	boards              []board.Board
+	lifecycles          map[string]*lifecycle
+	ctx                 context.Context
+	retryInterval       time.Duration
+	repo                repository.SearchRepository
+	contextTimeout      time.Duration
+	diagnosticsInterval time.Duration
+	chStop              chan bool
+	sync.RWMutex
+}
+
+// lifecycle handle the state of one board
+type lifecycle struct {
+	board  board.Board
+	state  string
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// action serialize start and stop of board
+	action sync.Mutex
+	sync.RWMutex
+}
+
+func (h *lifecycle) getState() string {
+	h.RLock()
+	defer h.RUnlock()
+
+	return h.state
+}
+
+func (h *lifecycle) setState(state string) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.state = state
 }
 
 // NewBoardUsecase implement board usecase
-func NewBoardUsecase() board.Usecase {
+// The boards diagnostics are saved on repo to keep them across restarts. It can be nil to not save them.
+func NewBoardUsecase(repo repository.SearchRepository, timeout time.Duration) board.Usecase {
 
 	return &boardUsecase{
-		boards: make([]board.Board, 0, 1),
+		boards:              make([]board.Board, 0, 1),
+		lifecycles:          make(map[string]*lifecycle),
+		ctx:                 context.Background(),
+		retryInterval:       defaultRetryInterval,
+		repo:                repo,
+		contextTimeout:      timeout,
+		diagnosticsInterval: defaultDiagnosticsInterval,
 	}
 
 }
@@ -29,70 +80,396 @@ func (h *boardUsecase) GetBoards(ctx context.Context) ([]*models.Board, error) {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		boardsData := make([]*models.Board, 0, len(h.boards))
+		boards := h.Boards()
+		boardsData := make([]*models.Board, 0, len(boards))
 
-		for _, board := range h.boards {
-			boardsData = append(boardsData, board.Board())
+		for _, board := range boards {
+			data := board.Board()
+			if lc := h.lifecycle(board.Name()); lc != nil {
+				data.State = lc.getState()
+			}
+			boardsData = append(boardsData, data)
 		}
 
 		return boardsData, nil
 	}
 }
 
+// Boards return the list of boards
+func (h *boardUsecase) Boards() []board.Board {
+	h.RLock()
+	defer h.RUnlock()
+
+	return h.boards
+}
+
 // AddBoard add board on list
+// It restore the board diagnostics saved before restart
 func (h *boardUsecase) AddBoard(board board.Board) {
+	if err := h.loadDiagnostics(board); err != nil {
+		log.Errorf("Error when load diagnostics of board %s: %s", board.Name(), err.Error())
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
 	h.boards = append(h.boards, board)
+	h.lifecycles[board.Name()] = &lifecycle{
+		board: board,
+		state: models.BoardStateStopped,
+	}
+}
+
+// RemoveBoard stop board if it's started, then remove it from list
+// Its diagnostics are saved, to restore them if board is added again
+func (h *boardUsecase) RemoveBoard(ctx context.Context, name string) error {
+	lc := h.lifecycle(name)
+	if lc == nil {
+		return board.ErrBoardNotFound
+	}
+
+	if err := h.stop(context.WithoutCancel(ctx), lc); err != nil && err != board.ErrBoardAlreadyStopped {
+		return err
+	}
+
+	h.Lock()
+	boards := make([]board.Board, 0, len(h.boards))
+	for _, b := range h.boards {
+		if b.Name() != name {
+			boards = append(boards, b)
+		}
+	}
+	h.boards = boards
+	delete(h.lifecycles, name)
+	h.Unlock()
+
+	if h.repo != nil {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.contextTimeout)
+		defer cancel()
+		h.saveDiagnostic(ctx, lc.board)
+	}
+
+	return nil
 }
 
 // Starts start each board on background
 // If board failed to start, it try again while context not canceled
+// The context is kept to start boards later from API
 func (h *boardUsecase) Starts(ctx context.Context) {
 	select {
 	case <-ctx.Done():
 		log.Infof("Context canceled: %s", ctx.Err())
 		return
 	default:
-		for _, board := range h.boards {
-			go h.startBoard(ctx, board)
+		h.Lock()
+		h.ctx = ctx
+		if h.repo != nil && h.chStop == nil {
+			h.chStop = make(chan bool)
+			go h.runDiagnostics(ctx, h.chStop)
+		}
+		h.Unlock()
+
+		for _, board := range h.Boards() {
+			if err := h.start(h.lifecycle(board.Name())); err != nil {
+				log.Errorf("Failed to start board %s: %s", board.Name(), err.Error())
+			}
 		}
 		return
 	}
 }
 
-// Stops stop each board
+// Stops stop each board one by one, in reverse order they are added
+// So boards that depend on other, like TFP on tank, are added after and stopped before
 func (h *boardUsecase) Stops(ctx context.Context) {
 	select {
 	case <-ctx.Done():
 		log.Infof("Context canceled: %s", ctx.Err())
 		return
 	default:
-		for _, board := range h.boards {
-			err := board.Stop(ctx)
-			if err != nil {
-				log.Errorf("Failed to stop successfully board %s: %s", board.Name(), err.Error())
+		boards := h.Boards()
+		for i := len(boards) - 1; i >= 0; i-- {
+			b := boards[i]
+			log.Infof("Stop board %s", b.Name())
+			if err := h.stop(ctx, h.lifecycle(b.Name())); err != nil && err != board.ErrBoardAlreadyStopped {
+				log.Errorf("Failed to stop successfully board %s: %s", b.Name(), err.Error())
 			}
 		}
+
+		h.Lock()
+		if h.chStop != nil {
+			close(h.chStop)
+			h.chStop = nil
+		}
+		h.Unlock()
+		h.saveDiagnostics(ctx)
 		return
 	}
 }
 
+// Start start board on background
+// It return ErrBoardAlreadyStarted if board is running or try to start
+func (h *boardUsecase) Start(ctx context.Context, name string) error {
+	lc := h.lifecycle(name)
+	if lc == nil {
+		return board.ErrBoardNotFound
+	}
+	if lc.getState() == models.BoardStateStopping {
+		return board.ErrBoardStopping
+	}
+
+	return h.start(lc)
+}
+
+// Stop stop board on background, or cancel the start in progress
+// It not wait the board is stopped, because it can take a while, like DFP that finish its wash.
+// The board is stopped even if context is canceled, to not leave it half stopped
+func (h *boardUsecase) Stop(ctx context.Context, name string) error {
+	lc := h.lifecycle(name)
+	if lc == nil {
+		return board.ErrBoardNotFound
+	}
+	if lc.getState() == models.BoardStateStopping {
+		return board.ErrBoardStopping
+	}
+
+	return h.stopOnBackground(context.WithoutCancel(ctx), lc, nil)
+}
+
+// Restart stop board on background if it's started, then start it
+func (h *boardUsecase) Restart(ctx context.Context, name string) error {
+	lc := h.lifecycle(name)
+	if lc == nil {
+		return board.ErrBoardNotFound
+	}
+	if lc.getState() == models.BoardStateStopping {
+		return board.ErrBoardStopping
+	}
+
+	err := h.stopOnBackground(context.WithoutCancel(ctx), lc, func() {
+		if err := h.start(lc); err != nil {
+			log.Errorf("Failed to start board %s: %s", lc.board.Name(), err.Error())
+		}
+	})
+	if err == board.ErrBoardAlreadyStopped {
+		return h.start(lc)
+	}
+
+	return err
+}
+
+// lifecycle return the lifecycle of board, or nil if not exist
+func (h *boardUsecase) lifecycle(name string) *lifecycle {
+	h.RLock()
+	defer h.RUnlock()
+
+	return h.lifecycles[name]
+}
+
+// start run the start loop of board on background
+func (h *boardUsecase) start(lc *lifecycle) error {
+	lc.action.Lock()
+	defer lc.action.Unlock()
+
+	if lc.cancel != nil {
+		return board.ErrBoardAlreadyStarted
+	}
+
+	h.RLock()
+	ctx, cancel := context.WithCancel(h.ctx)
+	h.RUnlock()
+	lc.cancel = cancel
+	lc.done = make(chan struct{})
+	lc.setState(models.BoardStateStarting)
+
+	go h.startBoard(ctx, lc)
+
+	return nil
+}
+
+// stop stop board if it's running, after cancel the start loop
+func (h *boardUsecase) stop(ctx context.Context, lc *lifecycle) error {
+	lc.action.Lock()
+	defer lc.action.Unlock()
+
+	isRunning, err := h.beginStop(lc)
+	if err != nil {
+		return err
+	}
+
+	return h.endStop(ctx, lc, isRunning)
+}
+
+// stopOnBackground put board on stopping state, then stop it on background
+// The next function is called when board is stopped successfully
+func (h *boardUsecase) stopOnBackground(ctx context.Context, lc *lifecycle, next func()) error {
+	lc.action.Lock()
+
+	isRunning, err := h.beginStop(lc)
+	if err != nil {
+		lc.action.Unlock()
+		return err
+	}
+
+	// The action lock is released when board is stopped
+	go func() {
+		err := h.endStop(ctx, lc, isRunning)
+		lc.action.Unlock()
+		if err != nil {
+			log.Errorf("Failed to stop successfully board %s: %s", lc.board.Name(), err.Error())
+			return
+		}
+		if next != nil {
+			next()
+		}
+	}()
+
+	return nil
+}
+
+// beginStop put board on stopping state, it need the action lock
+// It return true if board is running
+func (h *boardUsecase) beginStop(lc *lifecycle) (isRunning bool, err error) {
+	if lc.cancel == nil {
+		return false, board.ErrBoardAlreadyStopped
+	}
+
+	isRunning = lc.getState() == models.BoardStateRunning
+	lc.setState(models.BoardStateStopping)
+
+	return isRunning, nil
+}
+
+// endStop stop board if it's running, after cancel the start loop, it need the action lock
+func (h *boardUsecase) endStop(ctx context.Context, lc *lifecycle, isRunning bool) (err error) {
+
+	// Wait the start loop exit, the board can be started during this time
+	if !isRunning {
+		lc.cancel()
+		<-lc.done
+		if lc.getState() == models.BoardStateRunning {
+			isRunning = true
+			lc.setState(models.BoardStateStopping)
+		}
+	}
+
+	if isRunning {
+		err = lc.board.Stop(ctx)
+	}
+	lc.cancel()
+	lc.cancel = nil
+
+	if err != nil {
+		lc.setState(models.BoardStateFailed)
+		return err
+	}
+	lc.setState(models.BoardStateStopped)
+	lc.board.Diagnostics().Stopped()
+
+	return nil
+}
+
 // startBoard start board and try while context not canceled
-func (h *boardUsecase) startBoard(ctx context.Context, board board.Board) {
+func (h *boardUsecase) startBoard(ctx context.Context, lc *lifecycle) {
+	defer close(lc.done)
+
 	for {
 		select {
 		case <-ctx.Done():
 			log.Infof("Context canceled: %s", ctx.Err())
 			return
 		default:
-			log.Infof("Start board %s", board.Name())
+			log.Infof("Start board %s", lc.board.Name())
+
+			err := lc.board.Start(ctx)
+			if err == nil {
+				lc.board.Diagnostics().Started()
+				lc.setState(models.BoardStateRunning)
+				return
+			}
+			log.Errorf("Failed to init board %s: %s", lc.board.Name(), err.Error())
+			lc.setState(models.BoardStateRetrying)
 
-			err := board.Start(ctx)
-			if err != nil {
-				log.Errorf("Failed to init board %s: %s", board.Name(), err.Error())
-				time.Sleep(10 * time.Second)
-			} else {
+			select {
+			case <-ctx.Done():
+				log.Infof("Context canceled: %s", ctx.Err())
 				return
+			case <-time.After(h.retryInterval):
 			}
 		}
 	}
 }
+
+// loadDiagnostics restore the diagnostics saved for board, or create them
+func (h *boardUsecase) loadDiagnostics(b board.Board) error {
+	if h.repo == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), h.contextTimeout)
+	defer cancel()
+
+	diagnostics := make([]*models.BoardDiagnostic, 0, 1)
+	if _, err := h.repo.Search(ctx, &repository.Query{
+		Filters: map[string]interface{}{
+			"name": b.Name(),
+		},
+		Size: 1,
+	}, &diagnostics); err != nil {
+		return err
+	}
+
+	if len(diagnostics) == 0 {
+		diagnostic := b.Diagnostics().Diagnostic()
+		if err := h.repo.Create(ctx, diagnostic); err != nil {
+			return err
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	// Board is not started yet
+	diagnostics[0].StartedAt = nil
+	b.Diagnostics().Load(diagnostics[0])
+
+	return nil
+}
+
+// saveDiagnostics save the diagnostics of all boards
+func (h *boardUsecase) saveDiagnostics(c context.Context) {
+	if h.repo == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, h.contextTimeout)
+	defer cancel()
+
+	for _, b := range h.Boards() {
+		h.saveDiagnostic(ctx, b)
+	}
+}
+
+// saveDiagnostic save the diagnostics of one board, if they are loaded from repo
+func (h *boardUsecase) saveDiagnostic(ctx context.Context, b board.Board) {
+	diagnostic := b.Diagnostics().Diagnostic()
+	if diagnostic.ID == 0 {
+		return
+	}
+
+	if err := h.repo.Update(ctx, diagnostic); err != nil {
+		log.Errorf("Error when save diagnostics of board %s: %s", b.Name(), err.Error())
+	}
+}
+
+// runDiagnostics save boards diagnostics periodically, while not stopped
+func (h *boardUsecase) runDiagnostics(ctx context.Context, chStop chan bool) {
+	ticker := time.NewTicker(h.diagnosticsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-chStop:
+			return
+		case <-ticker.C:
+			h.saveDiagnostics(ctx)
+		}
+	}
+}
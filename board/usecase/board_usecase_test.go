@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBoard fail to start while failures is not 0, and wait chStop on stop if it's set
+type fakeBoard struct {
+	name        string
+	diagnostics *board.Diagnostics
+	failures    int
+	starts      int
+	stops       int
+	stopErr     error
+	chStop      chan struct{}
+	sync.Mutex
+}
+
+func (h *fakeBoard) IsOnline() bool { return true }
+func (h *fakeBoard) Name() string   { return h.name }
+func (h *fakeBoard) Board() *models.Board {
+	data := &models.Board{Name: h.name}
+	h.diagnostics.Fill(data)
+	return data
+}
+func (h *fakeBoard) Diagnostics() *board.Diagnostics {
+	return h.diagnostics
+}
+func (h *fakeBoard) Start(ctx context.Context) error {
+	h.Lock()
+	defer h.Unlock()
+	if h.failures > 0 {
+		h.failures--
+		return errors.New("test")
+	}
+	h.starts++
+	return nil
+}
+func (h *fakeBoard) Stop(ctx context.Context) error {
+	if h.chStop != nil {
+		<-h.chStop
+	}
+	h.Lock()
+	defer h.Unlock()
+	h.stops++
+	return h.stopErr
+}
+
+func getState(t *testing.T, us board.Usecase, name string) string {
+	boards, err := us.GetBoards(context.Background())
+	assert.NoError(t, err)
+	for _, b := range boards {
+		if b.Name == name {
+			return b.State
+		}
+	}
+	return ""
+}
+
+func TestBoardLifecycle(t *testing.T) {
+	tank := &fakeBoard{name: "tank", failures: 1, diagnostics: board.NewDiagnostics("tank")}
+	tfp := &fakeBoard{name: "tfp", diagnostics: board.NewDiagnostics("tfp")}
+	us := NewBoardUsecase(nil, 10*time.Second)
+	us.(*boardUsecase).retryInterval = 100 * time.Millisecond
+	us.AddBoard(tank)
+	us.AddBoard(tfp)
+	assert.Equal(t, models.BoardStateStopped, getState(t, us, "tank"))
+
+	// Starts try again the board that failed
+	us.Starts(context.Background())
+	assert.Eventually(t, func() bool { return getState(t, us, "tfp") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, models.BoardStateRetrying, getState(t, us, "tank"))
+	assert.Eventually(t, func() bool { return getState(t, us, "tank") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+
+	// Start when already started
+	assert.Equal(t, board.ErrBoardAlreadyStarted, us.Start(context.Background(), "tank"))
+
+	// Unknown board
+	assert.Equal(t, board.ErrBoardNotFound, us.Stop(context.Background(), "dfp"))
+
+	// Stop and start
+	assert.NoError(t, us.Stop(context.Background(), "tank"))
+	assert.Eventually(t, func() bool { return getState(t, us, "tank") == models.BoardStateStopped }, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, tank.stops)
+	assert.Equal(t, board.ErrBoardAlreadyStopped, us.Stop(context.Background(), "tank"))
+	assert.NoError(t, us.Start(context.Background(), "tank"))
+	assert.Eventually(t, func() bool { return getState(t, us, "tank") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, tank.starts)
+
+	// Restart
+	assert.NoError(t, us.Restart(context.Background(), "tank"))
+	assert.Eventually(t, func() bool { return getState(t, us, "tank") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, tank.stops)
+	assert.Equal(t, 3, tank.starts)
+
+	// Stop cancel the start in progress, without stop board
+	tank.failures = 100
+	assert.NoError(t, us.Restart(context.Background(), "tank"))
+	assert.Eventually(t, func() bool { return getState(t, us, "tank") == models.BoardStateRetrying }, 1*time.Second, 10*time.Millisecond)
+	assert.NoError(t, us.Stop(context.Background(), "tank"))
+	assert.Eventually(t, func() bool { return getState(t, us, "tank") == models.BoardStateStopped }, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, tank.stops)
+
+	// Failed when board not stop successfully
+	tfp.stopErr = errors.New("test")
+	us.Stops(context.Background())
+	assert.Equal(t, models.BoardStateFailed, getState(t, us, "tfp"))
+	assert.Equal(t, 3, tank.stops)
+}
+
+func TestStopOnBackground(t *testing.T) {
+	dfp := &fakeBoard{name: "dfp", diagnostics: board.NewDiagnostics("dfp"), chStop: make(chan struct{})}
+	us := NewBoardUsecase(nil, 10*time.Second)
+	us.AddBoard(dfp)
+	us.Starts(context.Background())
+	assert.Eventually(t, func() bool { return getState(t, us, "dfp") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+
+	// Stop not wait board is stopped
+	assert.NoError(t, us.Stop(context.Background(), "dfp"))
+	assert.Equal(t, models.BoardStateStopping, getState(t, us, "dfp"))
+	assert.Equal(t, board.ErrBoardStopping, us.Start(context.Background(), "dfp"))
+	assert.Equal(t, board.ErrBoardStopping, us.Stop(context.Background(), "dfp"))
+	assert.Equal(t, board.ErrBoardStopping, us.Restart(context.Background(), "dfp"))
+	dfp.chStop <- struct{}{}
+	assert.Eventually(t, func() bool { return getState(t, us, "dfp") == models.BoardStateStopped }, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, dfp.stops)
+
+	// Restart start board when it's stopped
+	assert.NoError(t, us.Start(context.Background(), "dfp"))
+	assert.Eventually(t, func() bool { return getState(t, us, "dfp") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+	assert.NoError(t, us.Restart(context.Background(), "dfp"))
+	assert.Equal(t, models.BoardStateStopping, getState(t, us, "dfp"))
+	dfp.chStop <- struct{}{}
+	assert.Eventually(t, func() bool { return getState(t, us, "dfp") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, dfp.stops)
+	assert.Equal(t, 3, dfp.starts)
+
+	close(dfp.chStop)
+	us.Stops(context.Background())
+}
+
+func TestBoardDiagnostics(t *testing.T) {
+	conn, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "gobot-fat.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.AutoMigrate(&models.BoardDiagnostic{}); err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewSQLRepository(conn).(repository.SearchRepository)
+
+	// Diagnostics are created and board is started
+	tank := &fakeBoard{name: "tank", diagnostics: board.NewDiagnostics("tank")}
+	us := NewBoardUsecase(repo, 10*time.Second)
+	us.AddBoard(tank)
+	assert.NotZero(t, tank.diagnostics.Diagnostic().ID)
+	us.Starts(context.Background())
+	assert.Eventually(t, func() bool { return getState(t, us, "tank") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+	boards, err := us.GetBoards(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, boards[0].StartedAt)
+
+	// Diagnostics are saved on stop
+	tank.diagnostics.Rebooted()
+	tank.diagnostics.Offline(errors.New("test"))
+	tank.diagnostics.Requested(10 * time.Millisecond)
+	tank.diagnostics.Requested(20 * time.Millisecond)
+	us.Stops(context.Background())
+	boards, err = us.GetBoards(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, boards[0].StartedAt)
+	assert.Equal(t, int64(0), boards[0].Uptime)
+
+	// Diagnostics are restored after restart
+	tank = &fakeBoard{name: "tank", diagnostics: board.NewDiagnostics("tank")}
+	us = NewBoardUsecase(repo, 10*time.Second)
+	us.AddBoard(tank)
+	boards, err = us.GetBoards(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), boards[0].RebootCount)
+	assert.Equal(t, int64(1), boards[0].OfflineCount)
+	assert.Equal(t, "test", boards[0].LastError)
+	assert.InDelta(t, 15, boards[0].AverageLatency, 0.001)
+}
+
+func TestRemoveBoard(t *testing.T) {
+	tank := &fakeBoard{name: "tank", diagnostics: board.NewDiagnostics("tank")}
+	tfp := &fakeBoard{name: "tfp", diagnostics: board.NewDiagnostics("tfp")}
+	us := NewBoardUsecase(nil, 10*time.Second)
+	us.AddBoard(tank)
+	us.AddBoard(tfp)
+	us.Starts(context.Background())
+	assert.Eventually(t, func() bool { return getState(t, us, "tank") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+
+	// Board is stopped and removed
+	err := us.RemoveBoard(context.Background(), "tank")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tank.stops)
+	assert.Len(t, us.Boards(), 1)
+	assert.Equal(t, "tfp", us.Boards()[0].Name())
+	assert.ErrorIs(t, us.Start(context.Background(), "tank"), board.ErrBoardNotFound)
+
+	// Board not exist
+	err = us.RemoveBoard(context.Background(), "tank")
+	assert.ErrorIs(t, err, board.ErrBoardNotFound)
+
+	// Board can be added again
+	us.AddBoard(tank)
+	assert.NoError(t, us.Start(context.Background(), "tank"))
+	assert.Eventually(t, func() bool { return getState(t, us, "tank") == models.BoardStateRunning }, 1*time.Second, 10*time.Millisecond)
+
+	us.Stops(context.Background())
+}
@@ -5,9 +5,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/disaster37/gobot-fat/board"
 	"github.com/disaster37/gobot-fat/dfp"
 	"github.com/disaster37/gobot-fat/helper"
-	"github.com/disaster37/gobot-fat/mail"
 	"github.com/disaster37/gobot-fat/mock"
 	"github.com/disaster37/gobot-fat/models"
 	"github.com/disaster37/gobot-fat/usecase"
@@ -75,9 +75,9 @@ type DFPBoard struct {
 	eventUsecase            usecase.UsecaseCRUD
 	stateUsecase            usecase.UsecaseCRUD
 	configHandler           *viper.Viper
-	mailClient              mail.Mail
 	isOnline                bool
 	isInitialized           bool
+	poll                    *pollAdaptor
 	relayDrum               *gpio.RelayDriver
 	relayPump               *gpio.RelayDriver
 	ledGreen                *gpio.LedDriver
@@ -99,12 +99,16 @@ type DFPBoard struct {
 	waitTimeForceWashFrozen *time.Ticker
 	waitTimeUnsetSecurity   *time.Ticker
 	schedulingRoutines      []*time.Ticker
+	shutdownRelays          string
+	shutdownWash            string
+	shutdownWashTimeout     time.Duration
+	washes                  sync.WaitGroup
 	gobot.Eventer
 	sync.Mutex
 }
 
 // NewDFP create board to manage DFP
-func NewDFP(configHandler *viper.Viper, config *models.DFPConfig, state *models.DFPState, eventUsecase usecase.UsecaseCRUD, dfpStateUsecase usecase.UsecaseCRUD, eventer gobot.Eventer, mailClient mail.Mail) (dfpBoard dfp.Board) {
+func NewDFP(configHandler *viper.Viper, config *models.DFPConfig, state *models.DFPState, eventUsecase usecase.UsecaseCRUD, dfpStateUsecase usecase.UsecaseCRUD, eventer gobot.Eventer) (dfpBoard dfp.Board) {
 
 	//Create client
 	var c DFPAdaptor
@@ -130,15 +134,18 @@ func NewDFP(configHandler *viper.Viper, config *models.DFPConfig, state *models.
 		c = NewRaspiAdaptor(configHandler)
 	}
 
-	return newDFP(c, configHandler, config, state, eventUsecase, dfpStateUsecase, eventer, mailClient)
+	return newDFP(c, configHandler, config, state, eventUsecase, dfpStateUsecase, eventer)
 
 }
 
 // newDFP create board to manage DFP
-func newDFP(board DFPAdaptor, configHandler *viper.Viper, config *models.DFPConfig, state *models.DFPState, eventUsecase usecase.UsecaseCRUD, dfpStateUsecase usecase.UsecaseCRUD, eventer gobot.Eventer, mailClient mail.Mail) dfp.Board {
+func newDFP(board DFPAdaptor, configHandler *viper.Viper, config *models.DFPConfig, state *models.DFPState, eventUsecase usecase.UsecaseCRUD, dfpStateUsecase usecase.UsecaseCRUD, eventer gobot.Eventer) dfp.Board {
 
 	buttonPollingDuration := configHandler.GetDuration("button_polling") * time.Millisecond
 
+	// Buttons and captors are read through poll, to know when board answered for the last time
+	poll := newPollAdaptor(board, configHandler.GetString("name"))
+
 	// Init board
 	dfpBoard := &DFPBoard{
 		board:                 board,
@@ -151,27 +158,29 @@ func newDFP(board DFPAdaptor, configHandler *viper.Viper, config *models.DFPConf
 		isOnline:              false,
 		isInitialized:         false,
 		globalEventer:         eventer,
+		poll:                  poll,
 		relayDrum:             gpio.NewRelayDriver(board, configHandler.GetString("pin.relay.drum")),
 		relayPump:             gpio.NewRelayDriver(board, configHandler.GetString("pin.relay.pomp")),
 		ledGreen:              gpio.NewLedDriver(board, configHandler.GetString("pin.led.green")),
 		ledRed:                gpio.NewLedDriver(board, configHandler.GetString("pin.led.red")),
-		buttonEmergencyStop:   gpio.NewButtonDriver(board, configHandler.GetString("pin.button.emergency_stop"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
-		buttonStart:           gpio.NewButtonDriver(board, configHandler.GetString("pin.button.start"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
-		buttonStop:            gpio.NewButtonDriver(board, configHandler.GetString("pin.button.stop"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
-		buttonWash:            gpio.NewButtonDriver(board, configHandler.GetString("pin.button.wash"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
-		buttonForceDrum:       gpio.NewButtonDriver(board, configHandler.GetString("pin.button.force_drum"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
-		buttonForcePump:       gpio.NewButtonDriver(board, configHandler.GetString("pin.button.force_pump"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
-		captorSecurityUpper:   gpio.NewButtonDriver(board, configHandler.GetString("pin.captor.security_upper"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(0)),
-		captorSecurityUnder:   gpio.NewButtonDriver(board, configHandler.GetString("pin.captor.security_under"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
-		captorWaterUpper:      gpio.NewButtonDriver(board, configHandler.GetString("pin.captor.water_upper"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(0)),
-		captorWaterUnder:      gpio.NewButtonDriver(board, configHandler.GetString("pin.captor.water_under"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
+		buttonEmergencyStop:   gpio.NewButtonDriver(poll, configHandler.GetString("pin.button.emergency_stop"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
+		buttonStart:           gpio.NewButtonDriver(poll, configHandler.GetString("pin.button.start"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
+		buttonStop:            gpio.NewButtonDriver(poll, configHandler.GetString("pin.button.stop"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
+		buttonWash:            gpio.NewButtonDriver(poll, configHandler.GetString("pin.button.wash"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
+		buttonForceDrum:       gpio.NewButtonDriver(poll, configHandler.GetString("pin.button.force_drum"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
+		buttonForcePump:       gpio.NewButtonDriver(poll, configHandler.GetString("pin.button.force_pump"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
+		captorSecurityUpper:   gpio.NewButtonDriver(poll, configHandler.GetString("pin.captor.security_upper"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(0)),
+		captorSecurityUnder:   gpio.NewButtonDriver(poll, configHandler.GetString("pin.captor.security_under"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
+		captorWaterUpper:      gpio.NewButtonDriver(poll, configHandler.GetString("pin.captor.water_upper"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(0)),
+		captorWaterUnder:      gpio.NewButtonDriver(poll, configHandler.GetString("pin.captor.water_under"), gpio.WithButtonPollInterval(buttonPollingDuration), gpio.WithButtonDefaultState(1)),
 		timeBetweenWash:       time.NewTicker(time.Duration(1 * time.Nanosecond)),
 		waitTimeUnsetSecurity: time.NewTicker(time.Duration(1 * time.Nanosecond)),
 		Eventer:               gobot.NewEventer(),
 		schedulingRoutines:    make([]*time.Ticker, 0),
-		mailClient:            mailClient,
 	}
 
+	dfpBoard.setShutdownPolicy(configHandler)
+
 	// Create gobot robot
 	dfpBoard.gobot = gobot.NewRobot(
 		dfpBoard.Name(),
@@ -233,13 +242,27 @@ func (h *DFPBoard) Start(ctx context.Context) (err error) {
 
 // Stop permit to stop gobot.
 // It send event of name `stop`. It can be used to stop routines.
+// The wash in progress is finished or aborted, and relays are forced off or left as they are, according to shutdown policy.
 func (h *DFPBoard) Stop(ctx context.Context) (err error) {
 
-	// Internal event
+	// Let the wash in progress finish
+	if h.shutdownWash == ShutdownWashFinish && h.state.IsWashed {
+		log.Infof("Wait wash is finished before stop board %s", h.name)
+		if !h.waitWash(h.shutdownWashTimeout) {
+			log.Warnf("Wash not finished after %s on board %s, abort it", h.shutdownWashTimeout, h.name)
+		}
+	}
+
+	// Internal event, it abort the wash in progress
 	h.Publish(EventBoardStop, nil)
+	if !h.waitWash(abortWashTimeout) {
+		log.Errorf("Wash not aborted after %s on board %s", abortWashTimeout, h.name)
+	}
 
 	// Stop outputs
-	h.forceStopRelais()
+	if h.isRelaysStoppedOnShutdown() {
+		h.forceStopRelais()
+	}
 	h.turnOffGreenLed()
 	h.turnOffRedLed()
 
@@ -262,6 +285,11 @@ func (h *DFPBoard) Stop(ctx context.Context) (err error) {
 	h.isOnline = false
 	h.isInitialized = false
 
+	// Persist final state
+	if err = h.stateUsecase.Update(ctx, h.state); err != nil {
+		log.Errorf("Error when save state of board %s: %s", h.name, err.Error())
+	}
+
 	helper.SendEvent(ctx, h.eventUsecase, h.name, helper.KindEventStopBoard, h.name)
 
 	return nil
@@ -274,10 +302,19 @@ func (h *DFPBoard) Name() string {
 
 // Board return public board data
 func (h *DFPBoard) Board() *models.Board {
-	return &models.Board{
-		Name:     h.Name(),
-		IsOnline: h.isOnline,
+	data := &models.Board{
+		Name:          h.Name(),
+		IsOnline:      h.isOnline,
+		IsInitialized: h.isInitialized,
 	}
+	h.poll.Fill(data)
+
+	return data
+}
+
+// Diagnostics return the board diagnostics
+func (h *DFPBoard) Diagnostics() *board.Diagnostics {
+	return h.poll.Diagnostics
 }
 
 // IsOnline return is board is online
@@ -136,6 +136,8 @@ func (s *DFPBoardTestSuite) TestStartStopIsOnline() {
 func (s *DFPBoardTestSuite) TestGetBoard() {
 	assert.Equal(s.T(), "test", s.board.Board().Name)
 	assert.True(s.T(), s.board.Board().IsOnline)
+	assert.True(s.T(), s.board.Board().IsInitialized)
+	assert.Eventually(s.T(), func() bool { return s.board.Board().LastPoll != nil }, 5*time.Second, 100*time.Millisecond)
 }
 
 func (s *DFPBoardTestSuite) TestName() {
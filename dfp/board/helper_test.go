@@ -46,7 +46,6 @@ func initTestBoard() (*DFPBoard, *mock.MockPlateform) {
 	eventUsecaseMock := usecase.NewMockUsecasetBase()
 	mockBoard := mock.NewMockPlateform()
 	usecaseDFPMock := usecase.NewMockUsecasetBase()
-	mockMail := mock.NewMockMail()
 
 	mockBoard.SetInvertInitialPinState(configHandler.GetString("pin.captor.security_upper"))
 	mockBoard.SetInvertInitialPinState(configHandler.GetString("pin.captor.water_upper"))
@@ -66,7 +65,7 @@ func initTestBoard() (*DFPBoard, *mock.MockPlateform) {
 	eventer.AddEvent(dfpconfig.NewDFPConfig)
 	eventer.AddEvent(dfpstate.NewDFPState)
 
-	board := newDFP(mockBoard, configHandler, dfpConfig, dfpState, eventUsecaseMock, usecaseDFPMock, eventer, mockMail)
+	board := newDFP(mockBoard, configHandler, dfpConfig, dfpState, eventUsecaseMock, usecaseDFPMock, eventer)
 
 	return board.(*DFPBoard), mockBoard
 }
@@ -0,0 +1,31 @@
+package dfpboard
+
+import (
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+)
+
+// pollAdaptor record each read of inputs on board on diagnostics
+type pollAdaptor struct {
+	DFPAdaptor
+	*board.Diagnostics
+}
+
+func newPollAdaptor(adaptor DFPAdaptor, name string) *pollAdaptor {
+	return &pollAdaptor{
+		DFPAdaptor:  adaptor,
+		Diagnostics: board.NewDiagnostics(name),
+	}
+}
+
+// DigitalRead read pin on board and record its latency and when it succeed
+func (h *pollAdaptor) DigitalRead(pin string) (val int, err error) {
+	start := time.Now()
+	val, err = h.DFPAdaptor.DigitalRead(pin)
+	h.Requested(time.Since(start))
+	if err == nil {
+		h.Polled()
+	}
+	return val, err
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/disaster37/gobot-fat/dfpconfig"
 	"github.com/disaster37/gobot-fat/dfpstate"
 	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/metrics"
 	"github.com/disaster37/gobot-fat/models"
 	log "github.com/sirupsen/logrus"
 	"github.com/yryz/ds18b20"
@@ -17,11 +18,13 @@ import (
 
 // Wash  run on routine for no blocking.
 // All routines are stopped when receive EventStopDFP,  EventBoardStop, EventSetSecurity or EventSetEmergencyStop  internal event
-func (h *DFPBoard) wash() {
+// The context carry who ask the wash, it's not used to cancel it
+func (h *DFPBoard) wash(ctx context.Context) {
 	// Only one watch
 	defer h.Unlock()
 	h.Lock()
 
+	startTime := time.Now()
 	h.state.IsWashed = true
 	if err := h.stateUsecase.Update(context.Background(), h.state); err != nil {
 		log.Errorf("Error when save state in wash routine: %s", err.Error())
@@ -86,7 +89,9 @@ func (h *DFPBoard) wash() {
 	}()
 
 	// Run wash
+	h.washes.Add(1)
 	go func() {
+		defer h.washes.Done()
 
 		var err error
 
@@ -150,7 +155,9 @@ func (h *DFPBoard) wash() {
 		h.waitTimeForceWashFrozen = time.NewTicker(time.Duration(h.config.ForceWashingDurationWhenFrozen) * time.Second)
 
 		// send event
-		helper.SendEvent(context.Background(), h.eventUsecase, h.name, helper.KindEventWash, h.name)
+		helper.SendEvent(context.WithoutCancel(ctx), h.eventUsecase, h.name, helper.KindEventWash, h.name)
+		metrics.Washes.WithLabelValues(h.name).Inc()
+		metrics.WashDuration.WithLabelValues(h.name).Observe(time.Since(startTime).Seconds())
 
 		ledControl.Wait()
 		wg.Wait()
@@ -163,6 +170,8 @@ func (h *DFPBoard) wash() {
 func (h *DFPBoard) work() {
 
 	ctx := context.TODO()
+	buttonCtx := helper.WithOrigin(ctx, helper.OriginButton)
+	autoCtx := helper.WithOrigin(ctx, helper.OriginAuto)
 
 	/****
 	 * Init state
@@ -185,7 +194,7 @@ func (h *DFPBoard) work() {
 
 	// If on current wash
 	if h.state.IsWashed {
-		h.wash()
+		h.wash(autoCtx)
 	}
 
 
@@ -197,6 +206,9 @@ func (h *DFPBoard) work() {
 		//panic("plop2")
 
 		dfpConfig := s.(*models.DFPConfig)
+		if dfpConfig.ID != h.config.ID {
+			return
+		}
 		log.Debugf("New config received for board %s, we update it", h.name)
 
 		h.config = dfpConfig
@@ -210,6 +222,9 @@ func (h *DFPBoard) work() {
 	h.on(h.globalEventer, dfpstate.NewDFPState, func(s interface{}) {
 
 		dfpState := s.(*models.DFPState)
+		if dfpState.ID != h.state.ID {
+			return
+		}
 		log.Debugf("New state received for board %s, we update it", h.name)
 
 		h.state.IsDisableSecurity = dfpState.IsDisableSecurity
@@ -230,7 +245,7 @@ func (h *DFPBoard) work() {
 		}
 		log.Debug("Button start pushed")
 
-		if err := h.StartDFP(ctx); err != nil {
+		if err := h.StartDFP(buttonCtx); err != nil {
 			log.Errorf("When start DFP: %s", err.Error())
 		}
 	})
@@ -243,7 +258,7 @@ func (h *DFPBoard) work() {
 		}
 		log.Debug("Button stop pushed")
 
-		if err := h.StopDFP(ctx); err != nil {
+		if err := h.StopDFP(buttonCtx); err != nil {
 			log.Errorf("When stop DFP: %s", err.Error())
 		}
 
@@ -259,7 +274,7 @@ func (h *DFPBoard) work() {
 
 		log.Debug("Button wash pushed")
 
-		if err := h.ForceWashing(ctx); err != nil {
+		if err := h.ForceWashing(buttonCtx); err != nil {
 			log.Errorf("When force washing: %s", err.Error())
 		}
 	})
@@ -274,7 +289,7 @@ func (h *DFPBoard) work() {
 
 		log.Debug("Button force drum pushed")
 
-		if err := h.StartManualDrum(ctx); err != nil {
+		if err := h.StartManualDrum(buttonCtx); err != nil {
 			log.Errorf("When start manual drum: %s", err.Error())
 		}
 
@@ -285,7 +300,7 @@ func (h *DFPBoard) work() {
 		//stop drum
 		log.Debug("Button force drum released")
 
-		if err := h.StopManualDrum(ctx); err != nil {
+		if err := h.StopManualDrum(buttonCtx); err != nil {
 			log.Errorf("When stop manual drum: %s", err.Error())
 		}
 
@@ -304,7 +319,7 @@ func (h *DFPBoard) work() {
 
 		log.Debug("Button force pump pushed")
 
-		if err := h.StartManualPump(ctx); err != nil {
+		if err := h.StartManualPump(buttonCtx); err != nil {
 			log.Errorf("When start manual pump: %s", err.Error())
 		}
 
@@ -315,7 +330,7 @@ func (h *DFPBoard) work() {
 		// Stop pump
 		log.Debug("Button force pump released")
 
-		if err := h.StopManualPump(ctx); err != nil {
+		if err := h.StopManualPump(buttonCtx); err != nil {
 			log.Errorf("When stop manual pump: %s", err.Error())
 		}
 
@@ -333,14 +348,14 @@ func (h *DFPBoard) work() {
 		
 		log.Debug("Button emergency stop pushed")
 
-		if err := h.SetEmergencyStop(ctx); err != nil {
+		if err := h.SetEmergencyStop(buttonCtx); err != nil {
 			log.Errorf("When set emergency stop for DFP: %s", err.Error())
 		}
 	})
 	h.on(h.buttonEmergencyStop, gpio.ButtonRelease, func(s interface{}) {
 		log.Debug("Button emergency stop released")
 
-		if err := h.UnsetEmergencyStop(ctx); err != nil {
+		if err := h.UnsetEmergencyStop(buttonCtx); err != nil {
 			log.Errorf("When unset emergency stop for DFP: %s", err.Error())
 		}
 	})
@@ -357,7 +372,7 @@ func (h *DFPBoard) work() {
 		case <-h.timeBetweenWash.C:
 			// Timer finished
 			if h.state.ShouldWash() {
-				h.wash()
+				h.wash(autoCtx)
 			}
 			h.timeBetweenWash = time.NewTicker(time.Duration(h.config.WaitTimeBetweenWashing) * time.Second)
 			break
@@ -381,14 +396,14 @@ func (h *DFPBoard) work() {
 
 		if h.captorSecurityUpper.Active() || h.captorSecurityUnder.Active() {
 
-			if err := h.SetSecurity(ctx); err != nil {
+			if err := h.SetSecurity(autoCtx); err != nil {
 				log.Errorf("When set security for DFP: %s", err.Error())
 			}
 		} else {
 
 			select {
 			case <-h.waitTimeUnsetSecurity.C:
-				if err := h.UnsetSecurity(ctx); err != nil {
+				if err := h.UnsetSecurity(autoCtx); err != nil {
 					log.Errorf("When unset security for DFP: %s", err.Error())
 				}
 			default:
@@ -433,6 +448,8 @@ func (h *DFPBoard) work() {
 // All routines are stopped when receive EventBoardStop internal event
 func (h *DFPBoard) runWashInactivity() {
 
+	ctx := helper.WithOrigin(context.Background(), helper.OriginAuto)
+
 	chStop := make(chan bool)
 	h.waitTimeForceWash = time.NewTicker(time.Duration(h.config.ForceWashingDuration) * time.Second)
 	h.waitTimeForceWashFrozen = time.NewTicker(time.Duration(h.config.ForceWashingDurationWhenFrozen) * time.Second)
@@ -461,7 +478,7 @@ func (h *DFPBoard) runWashInactivity() {
 				h.waitTimeForceWash = time.NewTicker(time.Duration(h.config.ForceWashingDuration) * time.Second)
 				if int(h.state.AmbientTemperature) > h.config.TemperatureThresholdWhenFrozen {
 					if h.state.ShouldWash() {
-						h.wash()
+						h.wash(ctx)
 					}
 					break
 				}
@@ -470,7 +487,7 @@ func (h *DFPBoard) runWashInactivity() {
 				h.waitTimeForceWashFrozen = time.NewTicker(time.Duration(h.config.ForceWashingDurationWhenFrozen) * time.Second)
 				if int(h.state.AmbientTemperature) <= h.config.TemperatureThresholdWhenFrozen {
 					if h.state.ShouldWash() {
-						h.wash()
+						h.wash(ctx)
 					}
 					break
 				}
@@ -192,7 +192,7 @@ func (s *DFPBoardTestSuite) TestWash() {
 
 	// Wash
 	status := mock.WaitEvent(s.board.Eventer, EventWash, 5*time.Second)
-	s.board.wash()
+	s.board.wash(context.Background())
 	time.Sleep(500 * time.Millisecond)
 	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayPump.Pin()))
 	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayDrum.Pin()))
@@ -206,7 +206,7 @@ func (s *DFPBoardTestSuite) TestWash() {
 
 	// When stop during process
 	status = mock.WaitEvent(s.board.Eventer, EventWash, 5*time.Second)
-	s.board.wash()
+	s.board.wash(context.Background())
 	time.Sleep(1 * time.Second)
 	err := s.board.StopDFP(context.Background())
 	assert.NoError(s.T(), err)
@@ -218,7 +218,7 @@ func (s *DFPBoardTestSuite) TestWash() {
 
 	// When emergency stop during process
 	status = mock.WaitEvent(s.board.Eventer, EventWash, 5*time.Second)
-	s.board.wash()
+	s.board.wash(context.Background())
 	time.Sleep(1 * time.Second)
 	err = s.board.SetEmergencyStop(context.Background())
 	assert.NoError(s.T(), err)
@@ -230,7 +230,7 @@ func (s *DFPBoardTestSuite) TestWash() {
 
 	// When security during process
 	status = mock.WaitEvent(s.board.Eventer, EventWash, 5*time.Second)
-	s.board.wash()
+	s.board.wash(context.Background())
 	time.Sleep(1 * time.Second)
 	err = s.board.SetSecurity(context.Background())
 	assert.NoError(s.T(), err)
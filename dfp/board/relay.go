@@ -147,10 +147,7 @@ func (h *DFPBoard) SetSecurity(ctx context.Context) (err error) {
 		}
 
 		// Send event
-		helper.SendEvent(ctx, h.eventUsecase, h.name, helper.KindEventSetSecurity, h.name)
-
-		// Send email
-		h.mailClient.SendEmail("DFP set security mode", fmt.Sprintf("We enter on security mode at %s", time.Now()))
+		helper.SendEventWithMessage(ctx, h.eventUsecase, h.name, helper.KindEventSetSecurity, h.name, fmt.Sprintf("We enter on security mode at %s", time.Now()))
 
 		// Publish internal event
 		h.Publish(EventSetSecurity, nil)
@@ -183,10 +180,7 @@ func (h *DFPBoard) UnsetSecurity(ctx context.Context) (err error) {
 		}
 
 		// Send event
-		helper.SendEvent(ctx, h.eventUsecase, h.name, helper.KindEventUnsetSecurity, h.name)
-
-		// Send email
-		h.mailClient.SendEmail("DFP unset security mode", fmt.Sprintf("We exit on security mode at %s", time.Now()))
+		helper.SendEventWithMessage(ctx, h.eventUsecase, h.name, helper.KindEventUnsetSecurity, h.name, fmt.Sprintf("We exit on security mode at %s", time.Now()))
 
 		// Publish internal event
 		h.Publish(EventUnsetSecurity, nil)
@@ -254,7 +248,7 @@ func (h *DFPBoard) UnsetDisableSecurity(ctx context.Context) (err error) {
 func (h *DFPBoard) ForceWashing(ctx context.Context) (err error) {
 	if !h.state.IsWashed && !h.state.IsEmergencyStopped {
 		log.Debug("Run force wash")
-		h.wash()
+		h.wash(ctx)
 	}
 
 	return
@@ -0,0 +1,68 @@
+package dfpboard
+
+import (
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	// ShutdownWashFinish wait the wash in progress is finished before stop board
+	ShutdownWashFinish = "finish"
+
+	// ShutdownWashAbort stop the wash in progress when board is stopped
+	ShutdownWashAbort = "abort"
+
+	// DefaultShutdownWashTimeout is the maximum time to wait the wash in progress, then it's aborted
+	DefaultShutdownWashTimeout = 60 * time.Second
+
+	// abortWashTimeout is the maximum time to wait the aborted wash stop relays and save state
+	abortWashTimeout = 5 * time.Second
+)
+
+// setShutdownPolicy read the `shutdown` section of board settings:
+//   - relays: off (default) or keep
+//   - wash: finish (default) or abort
+//   - wash_timeout: the maximum seconds to wait the wash in progress
+func (h *DFPBoard) setShutdownPolicy(configHandler *viper.Viper) {
+	h.shutdownRelays = board.ShutdownRelays(configHandler, board.ShutdownRelaysOff)
+
+	switch configHandler.GetString("shutdown.wash") {
+	case ShutdownWashAbort:
+		h.shutdownWash = ShutdownWashAbort
+	case ShutdownWashFinish, "":
+		h.shutdownWash = ShutdownWashFinish
+	default:
+		log.Warnf("Shutdown wash policy %s not supported, use %s", configHandler.GetString("shutdown.wash"), ShutdownWashFinish)
+		h.shutdownWash = ShutdownWashFinish
+	}
+
+	h.shutdownWashTimeout = DefaultShutdownWashTimeout
+	if configHandler.GetInt("shutdown.wash_timeout") > 0 {
+		h.shutdownWashTimeout = time.Duration(configHandler.GetInt("shutdown.wash_timeout")) * time.Second
+	}
+}
+
+// isRelaysStoppedOnShutdown is true when relays are forced off when board is stopped
+func (h *DFPBoard) isRelaysStoppedOnShutdown() bool {
+	return h.shutdownRelays == board.ShutdownRelaysOff
+}
+
+// waitWash wait the wash in progress is finished
+// It return false on timeout
+func (h *DFPBoard) waitWash(timeout time.Duration) bool {
+	chFinished := make(chan bool)
+	go func() {
+		h.washes.Wait()
+		close(chFinished)
+	}()
+
+	select {
+	case <-chFinished:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
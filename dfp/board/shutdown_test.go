@@ -0,0 +1,50 @@
+package dfpboard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+	"github.com/disaster37/gobot-fat/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownPolicy(t *testing.T) {
+
+	// Stop wait the wash in progress is finished
+	dfpBoard, adaptor := initTestBoard()
+	assert.NoError(t, dfpBoard.Start(context.Background()))
+	dfpBoard.wash(context.Background())
+	time.Sleep(500 * time.Millisecond)
+	assert.True(t, dfpBoard.state.IsWashed)
+	status := mock.WaitEvent(dfpBoard.Eventer, EventWash, 5*time.Second)
+	assert.NoError(t, dfpBoard.Stop(context.Background()))
+	assert.True(t, <-status)
+	assert.False(t, dfpBoard.state.IsWashed)
+	assert.False(t, dfpBoard.state.LastWashing.IsZero())
+	assert.Equal(t, 0, adaptor.GetDigitalPinState(dfpBoard.relayDrum.Pin()))
+	assert.Equal(t, 0, adaptor.GetDigitalPinState(dfpBoard.relayPump.Pin()))
+
+	// Stop abort the wash in progress
+	dfpBoard, adaptor = initTestBoard()
+	dfpBoard.shutdownWash = ShutdownWashAbort
+	assert.NoError(t, dfpBoard.Start(context.Background()))
+	dfpBoard.wash(context.Background())
+	time.Sleep(500 * time.Millisecond)
+	assert.True(t, dfpBoard.state.IsWashed)
+	status = mock.WaitEvent(dfpBoard.Eventer, EventWash, 3*time.Second)
+	assert.NoError(t, dfpBoard.Stop(context.Background()))
+	assert.False(t, <-status)
+	assert.False(t, dfpBoard.state.IsWashed)
+	assert.Equal(t, 0, adaptor.GetDigitalPinState(dfpBoard.relayDrum.Pin()))
+	assert.Equal(t, 0, adaptor.GetDigitalPinState(dfpBoard.relayPump.Pin()))
+
+	// Stop keep relays as is
+	dfpBoard, adaptor = initTestBoard()
+	dfpBoard.shutdownRelays = board.ShutdownRelaysKeep
+	assert.NoError(t, dfpBoard.Start(context.Background()))
+	assert.NoError(t, dfpBoard.StartManualPump(context.Background()))
+	assert.NoError(t, dfpBoard.Stop(context.Background()))
+	assert.Equal(t, 1, adaptor.GetDigitalPinState(dfpBoard.relayPump.Pin()))
+}
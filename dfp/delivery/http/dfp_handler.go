@@ -50,6 +50,7 @@ func (h DFPHandler) GetState(c echo.Context) error {
 	state, err := h.dUsecase.GetState(ctx)
 	if err != nil {
 		log.Errorf("Error when get DFP state: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -74,6 +75,7 @@ func (h DFPHandler) GetIO(c echo.Context) error {
 	io, err := h.dUsecase.GetIO(ctx)
 	if err != nil {
 		log.Errorf("Error when get DFP IO: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -99,6 +101,7 @@ func (h DFPHandler) Start(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post start: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -123,6 +126,7 @@ func (h DFPHandler) Stop(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post stop: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -147,6 +151,7 @@ func (h DFPHandler) Wash(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post wash: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -171,6 +176,7 @@ func (h DFPHandler) ManualStartDrum(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post manual_start_drum: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -195,6 +201,7 @@ func (h DFPHandler) ManualStopDrum(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post manual_stop_drum: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -219,6 +226,7 @@ func (h DFPHandler) ManualStartPump(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post manual_start_pump: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -243,6 +251,7 @@ func (h DFPHandler) ManualStopPump(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post manual_stop_pump: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -267,6 +276,7 @@ func (h DFPHandler) SetSecurity(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post set_security: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -291,6 +301,7 @@ func (h DFPHandler) UnsetSecurity(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post unset_security: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -315,6 +326,7 @@ func (h DFPHandler) SetEmergencyStop(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post set_emergency_stop: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -339,6 +351,7 @@ func (h DFPHandler) UnsetEmergencyStop(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post unset_emergency_stop: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -363,6 +376,7 @@ func (h DFPHandler) SetDisableSecurity(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post set_disable_security: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
@@ -387,6 +401,7 @@ func (h DFPHandler) UnsetDisableSecurity(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post unset_disable_security: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
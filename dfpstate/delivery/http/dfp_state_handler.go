@@ -5,8 +5,9 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/disaster37/gobot-fat/dfpstate"
+	"github.com/disaster37/gobot-fat/helper"
 	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
 	"github.com/disaster37/gobot-fat/usecase"
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
@@ -16,12 +17,15 @@ import (
 // DFPStateHandler  represent the httphandler for dfp_state
 type DFPStateHandler struct {
 	us usecase.UsecaseCRUD
+	id uint
 }
 
 // NewDFPStateHandler will initialize the DFP_state/ resources endpoint
-func NewDFPStateHandler(e *echo.Group, us usecase.UsecaseCRUD) {
+// The routes without ID use the DFP state with id
+func NewDFPStateHandler(e *echo.Group, us usecase.UsecaseCRUD, id uint) {
 	handler := &DFPStateHandler{
 		us: us,
+		id: id,
 	}
 	e.GET("/dfp-states", handler.Get)
 	e.POST("/dfp-states", handler.UpdateOld)
@@ -37,7 +41,7 @@ func (h *DFPStateHandler) Get(c echo.Context) error {
 	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
 
 	state := &models.DFPState{}
-	if err := h.us.Get(ctx, dfpstate.ID, state); err != nil {
+	if err := h.us.Get(ctx, h.id, state); err != nil {
 		log.Errorf("Error when get dfp_state: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -49,19 +53,32 @@ func (h *DFPStateHandler) Get(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(state.Version))
 	c.Response().WriteHeader(http.StatusOK)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), state)
 }
 
 // Update permit to update the current DFP state
 func (h *DFPStateHandler) UpdateOld(c echo.Context) error {
-	var err error
 	ctx := c.Request().Context()
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
 
+	// Version read by client, to not overwrite another update
+	version, isVersion, err := helper.ExpectedVersion(c.Request())
+	if err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  "Error when update dfp_state",
+				Detail: err.Error(),
+			},
+		})
+	}
+
 	state := &models.DFPState{}
 	if err = jsonapi.UnmarshalPayload(c.Request().Body, state); err != nil {
 		c.Response().WriteHeader(http.StatusBadRequest)
@@ -73,11 +90,26 @@ func (h *DFPStateHandler) UpdateOld(c echo.Context) error {
 			},
 		})
 	}
-	state.ID = dfpstate.ID
+	state.ID = h.id
 
 	log.Debugf("Data: %+v", state)
 
-	if err = h.us.Update(ctx, state); err != nil {
+	if isVersion {
+		err = h.us.UpdateIfVersion(ctx, state, version)
+	} else {
+		err = h.us.Update(ctx, state)
+	}
+	if err != nil {
+		if repository.IsVersionConflictError(err) {
+			c.Response().WriteHeader(http.StatusConflict)
+			return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+				{
+					Status: fmt.Sprintf("%d", http.StatusConflict),
+					Title:  "Error when update dfp_state",
+					Detail: err.Error(),
+				},
+			})
+		}
 		log.Errorf("Error when update dfp_state: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -89,6 +121,7 @@ func (h *DFPStateHandler) UpdateOld(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(state.Version))
 	c.Response().WriteHeader(http.StatusCreated)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), state)
 }
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disaster37/gobot-fat/event"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/google/jsonapi"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// EventHandler represent the httphandler for event
+type EventHandler struct {
+	dUsecase event.Usecase
+}
+
+// NewEventHandler will initialize the events/ resources endpoint
+func NewEventHandler(e *echo.Group, us event.Usecase) {
+	handler := &EventHandler{
+		dUsecase: us,
+	}
+	e.GET("/events", handler.List)
+	e.GET("/audit", handler.Audit)
+}
+
+// List return events that match filters
+// Supported query parameters:
+//   - filter[source], filter[kind], filter[type]
+//   - filter[from], filter[to] as RFC3339 date
+//   - page[number], page[size]
+//   - filter[user], filter[origin], filter[result]
+//   - sort, like `-timestamp` to get the newest first
+func (h *EventHandler) List(c echo.Context) error {
+	return h.search(c, "Error when list events", false)
+}
+
+// Audit return actions triggered from API, buttons or automatically
+// It support the same query parameters as List
+func (h *EventHandler) Audit(c echo.Context) error {
+	return h.search(c, "Error when list audit", true)
+}
+
+func (h *EventHandler) search(c echo.Context, title string, isAudit bool) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	filter, err := ParseFilter(c)
+	if err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  title,
+				Detail: err.Error(),
+			},
+		})
+	}
+	filter.IsAudit = isAudit
+
+	events, total, err := h.dUsecase.Search(ctx, filter)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, event.ErrInvalidFilter) {
+			status = http.StatusBadRequest
+		} else {
+			log.Errorf("%s: %s", title, err.Error())
+		}
+		c.Response().WriteHeader(status)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", status),
+				Title:  title,
+				Detail: err.Error(),
+			},
+		})
+	}
+
+	payload, err := jsonapi.Marshal(events)
+	if err != nil {
+		return err
+	}
+	manyPayload := payload.(*jsonapi.ManyPayload)
+	manyPayload.Meta = &jsonapi.Meta{
+		"total": total,
+		"page":  filter.Page,
+		"size":  filter.Size,
+	}
+
+	c.Response().WriteHeader(http.StatusOK)
+	return json.NewEncoder(c.Response()).Encode(manyPayload)
+}
+
+// ParseFilter read the event filter from query parameters
+func ParseFilter(c echo.Context) (*models.EventFilter, error) {
+	var err error
+	filter := &models.EventFilter{
+		SourceName: c.QueryParam("filter[source]"),
+		Kind:       c.QueryParam("filter[kind]"),
+		Type:       c.QueryParam("filter[type]"),
+		User:       c.QueryParam("filter[user]"),
+		Origin:     c.QueryParam("filter[origin]"),
+		Result:     c.QueryParam("filter[result]"),
+	}
+
+	if from := c.QueryParam("filter[from]"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, errors.Wrap(err, "filter[from] must be RFC3339 date")
+		}
+	}
+	if to := c.QueryParam("filter[to]"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, errors.Wrap(err, "filter[to] must be RFC3339 date")
+		}
+	}
+	if page := c.QueryParam("page[number]"); page != "" {
+		if filter.Page, err = strconv.Atoi(page); err != nil {
+			return nil, errors.Wrap(err, "page[number] must be a number")
+		}
+	}
+	if size := c.QueryParam("page[size]"); size != "" {
+		if filter.Size, err = strconv.Atoi(size); err != nil {
+			return nil, errors.Wrap(err, "page[size] must be a number")
+		}
+	}
+	if sort := c.QueryParam("sort"); sort != "" {
+		filter.Sort = strings.TrimPrefix(sort, "-")
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,32 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidFilter is returned when the filter is not valid
+var ErrInvalidFilter = errors.New("Invalid filter")
+
+const (
+	// DefaultPageSize is the number of events per page when not provided
+	DefaultPageSize = 50
+
+	// MaxPageSize is the maximum number of events per page
+	MaxPageSize = 1000
+
+	// MaxResultWindow is the maximum of page number multiplied by page size, it's the default index.max_result_window of Elasticsearch
+	MaxResultWindow = 10000
+)
+
+// Usecase represent the event usecase
+type Usecase interface {
+	// Search return events that match filter and the total number of matching events
+	Search(ctx context.Context, filter *models.EventFilter) (events []*models.Event, total int64, err error)
+
+	// Purge remove events older than date on all repositories and return the number of removed events
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/disaster37/gobot-fat/event"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/pkg/errors"
+)
+
+// ErrSearchNotSupported is returned when the repository can't search events
+var ErrSearchNotSupported = errors.New("Repository not support search")
+
+// ErrPurgeNotSupported is returned when the repository can't remove old events
+var ErrPurgeNotSupported = errors.New("Repository not support purge")
+
+// sortFields is the list of fields that can be used to sort events
+var sortFields = map[string]bool{
+	"timestamp":   true,
+	"temperature": true,
+	"humidity":    true,
+	"duration":    true,
+	"level":       true,
+}
+
+type eventUsecase struct {
+	repo           repository.Repository
+	otherRepos     []repository.Repository
+	contextTimeout time.Duration
+}
+
+// NewEventUsecase will create new eventUsecase object of event.Usecase interface
+// The repo is used to search events. The other repositories are where events are also stored, they are only purged.
+func NewEventUsecase(repo repository.Repository, timeout time.Duration, otherRepos ...repository.Repository) event.Usecase {
+	return &eventUsecase{
+		repo:           repo,
+		otherRepos:     otherRepos,
+		contextTimeout: timeout,
+	}
+}
+
+// Search return events that match filter and the total number of matching events
+func (h *eventUsecase) Search(c context.Context, filter *models.EventFilter) ([]*models.Event, int64, error) {
+
+	if filter == nil {
+		return nil, 0, errors.New("Filter can't be null")
+	}
+
+	searchRepo, ok := h.repo.(repository.SearchRepository)
+	if !ok {
+		return nil, 0, ErrSearchNotSupported
+	}
+
+	query, err := toQuery(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, h.contextTimeout)
+	defer cancel()
+
+	events := make([]*models.Event, 0)
+	total, err := searchRepo.Search(ctx, query, &events)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// SQL repository not generate document ID
+	for _, event := range events {
+		if event.DocumentID == "" {
+			event.DocumentID = strconv.FormatUint(uint64(event.ID), 10)
+		}
+	}
+
+	return events, total, nil
+}
+
+// Purge remove events older than date on all repositories
+// It try all repositories and return the last error
+func (h *eventUsecase) Purge(c context.Context, before time.Time) (total int64, err error) {
+
+	ctx, cancel := context.WithTimeout(c, h.contextTimeout)
+	defer cancel()
+
+	for _, repo := range append([]repository.Repository{h.repo}, h.otherRepos...) {
+		purgeRepo, ok := repo.(repository.PurgeRepository)
+		if !ok {
+			err = ErrPurgeNotSupported
+			continue
+		}
+		nb, errPurge := purgeRepo.Purge(ctx, "timestamp", before, &models.Event{})
+		if errPurge != nil {
+			err = errPurge
+			continue
+		}
+		total += nb
+	}
+
+	return total, err
+}
+
+// toQuery convert event filter to repository query
+func toQuery(filter *models.EventFilter) (*repository.Query, error) {
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Size <= 0 {
+		filter.Size = event.DefaultPageSize
+	}
+	if filter.Size > event.MaxPageSize {
+		return nil, errors.Wrapf(event.ErrInvalidFilter, "Page size must be lower or equal to %d", event.MaxPageSize)
+	}
+	if filter.Page*filter.Size > event.MaxResultWindow {
+		return nil, errors.Wrapf(event.ErrInvalidFilter, "Page number multiplied by page size must be lower or equal to %d, use filter[from] and filter[to] to get older events", event.MaxResultWindow)
+	}
+	if filter.Sort == "" {
+		filter.Sort = "timestamp"
+		filter.SortDesc = true
+	}
+	if !sortFields[filter.Sort] {
+		return nil, errors.Wrapf(event.ErrInvalidFilter, "Events can't be sorted by %s", filter.Sort)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return nil, errors.Wrap(event.ErrInvalidFilter, "From must be before To")
+	}
+
+	query := &repository.Query{
+		Filters:   make(map[string]interface{}),
+		TimeField: "timestamp",
+		From:      filter.From,
+		To:        filter.To,
+		SortField: filter.Sort,
+		SortDesc:  filter.SortDesc,
+		Offset:    (filter.Page - 1) * filter.Size,
+		Size:      filter.Size,
+	}
+
+	if filter.SourceName != "" {
+		query.Filters["source_name"] = filter.SourceName
+	}
+	if filter.Kind != "" {
+		query.Filters["kind"] = filter.Kind
+	}
+	if filter.Type != "" {
+		query.Filters["type"] = filter.Type
+	}
+	if filter.User != "" {
+		query.Filters["user"] = filter.User
+	}
+	if filter.Origin != "" {
+		query.Filters["origin"] = filter.Origin
+	}
+	if filter.Result != "" {
+		query.Filters["result"] = filter.Result
+	}
+	if filter.IsAudit {
+		query.Exists = []string{"origin"}
+	}
+
+	return query, nil
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/event"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSearchRepository struct {
+	repository.MockBase
+	query *repository.Query
+}
+
+func (m *mockSearchRepository) Search(ctx context.Context, query *repository.Query, listData interface{}) (int64, error) {
+	m.query = query
+	events := listData.(*[]*models.Event)
+	*events = append(*events, &models.Event{ID: 7, SourceName: "dfp", EventKind: "wash"})
+	return 1, nil
+}
+
+func TestSearch(t *testing.T) {
+	repo := &mockSearchRepository{}
+	us := NewEventUsecase(repo, 10*time.Second)
+
+	// Default values
+	events, total, err := us.Search(context.Background(), &models.EventFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "7", events[0].DocumentID)
+	assert.Equal(t, "timestamp", repo.query.SortField)
+	assert.True(t, repo.query.SortDesc)
+	assert.Equal(t, 0, repo.query.Offset)
+	assert.Equal(t, event.DefaultPageSize, repo.query.Size)
+	assert.Empty(t, repo.query.Filters)
+
+	// With filters
+	from := time.Now().Add(-1 * time.Hour)
+	to := time.Now()
+	_, _, err = us.Search(context.Background(), &models.EventFilter{
+		SourceName: "dfp",
+		Kind:       "wash",
+		Type:       "drum",
+		From:       from,
+		To:         to,
+		Page:       3,
+		Size:       20,
+		Sort:       "temperature",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "dfp", repo.query.Filters["source_name"])
+	assert.Equal(t, "wash", repo.query.Filters["kind"])
+	assert.Equal(t, "drum", repo.query.Filters["type"])
+	assert.Equal(t, from, repo.query.From)
+	assert.Equal(t, to, repo.query.To)
+	assert.Equal(t, 40, repo.query.Offset)
+	assert.Equal(t, 20, repo.query.Size)
+	assert.Equal(t, "temperature", repo.query.SortField)
+	assert.False(t, repo.query.SortDesc)
+	assert.Empty(t, repo.query.Exists)
+
+	// Audit
+	_, _, err = us.Search(context.Background(), &models.EventFilter{
+		User:    "john",
+		Origin:  "api",
+		Result:  "failure",
+		IsAudit: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "john", repo.query.Filters["user"])
+	assert.Equal(t, "api", repo.query.Filters["origin"])
+	assert.Equal(t, "failure", repo.query.Filters["result"])
+	assert.Equal(t, []string{"origin"}, repo.query.Exists)
+
+	// Bad filters
+	_, _, err = us.Search(context.Background(), &models.EventFilter{Sort: "bad"})
+	assert.True(t, errors.Is(err, event.ErrInvalidFilter))
+	_, _, err = us.Search(context.Background(), &models.EventFilter{Size: event.MaxPageSize + 1})
+	assert.True(t, errors.Is(err, event.ErrInvalidFilter))
+	_, _, err = us.Search(context.Background(), &models.EventFilter{Page: 11, Size: event.MaxPageSize})
+	assert.True(t, errors.Is(err, event.ErrInvalidFilter))
+	_, _, err = us.Search(context.Background(), &models.EventFilter{From: to, To: from})
+	assert.True(t, errors.Is(err, event.ErrInvalidFilter))
+	_, _, err = us.Search(context.Background(), nil)
+	assert.Error(t, err)
+
+	// Repository without search
+	us = NewEventUsecase(&repository.MockBase{}, 10*time.Second)
+	_, _, err = us.Search(context.Background(), &models.EventFilter{})
+	assert.Equal(t, ErrSearchNotSupported, err)
+}
+
+type mockPurgeRepository struct {
+	repository.MockBase
+	before time.Time
+	nb     int64
+	err    error
+}
+
+func (m *mockPurgeRepository) Purge(ctx context.Context, field string, before time.Time, data interface{}) (int64, error) {
+	m.before = before
+	return m.nb, m.err
+}
+
+func TestPurge(t *testing.T) {
+	sqlRepo := &mockPurgeRepository{nb: 2}
+	esRepo := &mockPurgeRepository{nb: 3}
+	us := NewEventUsecase(sqlRepo, 10*time.Second, esRepo)
+	before := time.Now().Add(-24 * time.Hour)
+
+	// Purge all repositories
+	nb, err := us.Purge(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), nb)
+	assert.Equal(t, before, sqlRepo.before)
+	assert.Equal(t, before, esRepo.before)
+
+	// When one repository failed
+	esRepo.err = errors.New("test")
+	nb, err = us.Purge(context.Background(), before)
+	assert.Error(t, err)
+	assert.Equal(t, int64(2), nb)
+
+	// Repository without purge
+	us = NewEventUsecase(&repository.MockBase{}, 10*time.Second)
+	_, err = us.Purge(context.Background(), before)
+	assert.Equal(t, ErrPurgeNotSupported, err)
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/event"
+	log "github.com/sirupsen/logrus"
+)
+
+// Retention remove periodically the events older than retention duration
+type Retention struct {
+	eventUsecase event.Usecase
+	retention    time.Duration
+	interval     time.Duration
+	chStop       chan bool
+	isStarted    bool
+	sync.Mutex
+}
+
+// NewRetention will create new Retention that purge events older than retention every interval
+func NewRetention(eventUsecase event.Usecase, retention time.Duration, interval time.Duration) *Retention {
+	return &Retention{
+		eventUsecase: eventUsecase,
+		retention:    retention,
+		interval:     interval,
+	}
+}
+
+// Start purge events now, then every interval
+func (h *Retention) Start(ctx context.Context) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.isStarted {
+		return
+	}
+	h.chStop = make(chan bool)
+	h.isStarted = true
+
+	go func(chStop chan bool) {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+		for {
+			h.purge(ctx)
+			select {
+			case <-chStop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}(h.chStop)
+}
+
+// Stop purge events
+func (h *Retention) Stop(ctx context.Context) {
+	h.Lock()
+	defer h.Unlock()
+
+	if !h.isStarted {
+		return
+	}
+	close(h.chStop)
+	h.isStarted = false
+}
+
+func (h *Retention) purge(ctx context.Context) {
+	nb, err := h.eventUsecase.Purge(ctx, time.Now().Add(-h.retention))
+	if err != nil {
+		log.Errorf("Error when purge events: %s", err.Error())
+	}
+	if nb > 0 {
+		log.Infof("Purge %d events older than %s", nb, h.retention)
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/disaster37/gobot-fat/health"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/google/jsonapi"
+	"github.com/labstack/echo/v4"
+)
+
+// HealthHandler represent the httphandler for health
+type HealthHandler struct {
+	dUsecase health.Usecase
+}
+
+// NewHealthHandler will initialize the health endpoints
+// The `/healthz` and `/readyz` are on e without authentication, for Docker healthcheck and load balancer.
+// The detailed report is on api group.
+func NewHealthHandler(e *echo.Echo, api *echo.Group, us health.Usecase) {
+	handler := &HealthHandler{
+		dUsecase: us,
+	}
+	e.GET("/healthz", handler.Live)
+	e.GET("/readyz", handler.Ready)
+	api.GET("/health", handler.Health)
+}
+
+// Live return 200 while the process answer
+func (h *HealthHandler) Live(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": models.HealthStatusUp,
+	})
+}
+
+// Ready return 200 when critical dependencies are up, 503 otherwise
+func (h *HealthHandler) Ready(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	status := h.dUsecase.Ready(ctx)
+
+	return c.JSON(httpStatus(status), status)
+}
+
+// Health return the status of each dependency
+// It return 503 when critical dependencies are down
+func (h *HealthHandler) Health(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	status := h.dUsecase.Check(ctx)
+
+	c.Response().WriteHeader(httpStatus(status))
+	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), status)
+}
+
+func httpStatus(status *models.Health) int {
+	if status.Status == models.HealthStatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
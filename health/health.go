@@ -0,0 +1,28 @@
+package health
+
+import (
+	"context"
+
+	"github.com/disaster37/gobot-fat/models"
+)
+
+// Checker check the status of some dependencies
+type Checker interface {
+	// Critical is true when service can't work without the dependencies
+	Critical() bool
+
+	// Check return the status of each dependency
+	Check(ctx context.Context) []*models.HealthCheck
+}
+
+// Usecase is the health interface
+type Usecase interface {
+	// AddChecker add checker on list
+	AddChecker(checker Checker)
+
+	// Check run all checkers and return the service status
+	Check(ctx context.Context) *models.Health
+
+	// Ready run only the critical checkers, to know if service can serve requests
+	Ready(ctx context.Context) *models.Health
+}
@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+	"github.com/disaster37/gobot-fat/health"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/outbox"
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+const (
+	// KindDatabase is the kind of SQL database check
+	KindDatabase = "database"
+
+	// KindElasticsearch is the kind of Elasticsearch cluster check
+	KindElasticsearch = "elasticsearch"
+
+	// KindSMTP is the kind of SMTP server check
+	KindSMTP = "smtp"
+
+	// KindBoard is the kind of board check
+	KindBoard = "board"
+
+	// KindEvents is the kind of event pipeline check
+	KindEvents = "events"
+
+	// eventQueueThreshold is the ratio of event queue used before event pipeline is degraded
+	eventQueueThreshold = 0.8
+)
+
+// sqlChecker check the SQL database answer
+type sqlChecker struct {
+	db     *sql.DB
+	driver string
+}
+
+// NewSQLChecker return critical checker that ping database
+func NewSQLChecker(db *sql.DB, driver string) health.Checker {
+	return &sqlChecker{
+		db:     db,
+		driver: driver,
+	}
+}
+
+func (h *sqlChecker) Critical() bool {
+	return true
+}
+
+func (h *sqlChecker) Check(ctx context.Context) []*models.HealthCheck {
+	check := &models.HealthCheck{
+		Name:   h.driver,
+		Kind:   KindDatabase,
+		Status: models.HealthStatusUp,
+	}
+	if err := h.db.PingContext(ctx); err != nil {
+		check.Status = models.HealthStatusDown
+		check.Detail = err.Error()
+		return []*models.HealthCheck{check}
+	}
+	check.Data = map[string]interface{}{
+		"open_connections": h.db.Stats().OpenConnections,
+	}
+
+	return []*models.HealthCheck{check}
+}
+
+// elasticsearchChecker check the Elasticsearch cluster status
+type elasticsearchChecker struct {
+	client *elasticsearch.Client
+}
+
+// NewElasticsearchChecker return checker that read the cluster health
+// The yellow cluster is degraded and the red cluster is down
+func NewElasticsearchChecker(client *elasticsearch.Client) health.Checker {
+	return &elasticsearchChecker{
+		client: client,
+	}
+}
+
+func (h *elasticsearchChecker) Critical() bool {
+	return false
+}
+
+func (h *elasticsearchChecker) Check(ctx context.Context) []*models.HealthCheck {
+	check := &models.HealthCheck{
+		Name:   "elasticsearch",
+		Kind:   KindElasticsearch,
+		Status: models.HealthStatusDown,
+	}
+
+	res, err := h.client.Cluster.Health(h.client.Cluster.Health.WithContext(ctx))
+	if err != nil {
+		check.Detail = err.Error()
+		return []*models.HealthCheck{check}
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.IsError() {
+		check.Detail = res.String()
+		return []*models.HealthCheck{check}
+	}
+
+	clusterHealth := struct {
+		ClusterName   string `json:"cluster_name"`
+		Status        string `json:"status"`
+		NumberOfNodes int    `json:"number_of_nodes"`
+	}{}
+	if err = json.NewDecoder(res.Body).Decode(&clusterHealth); err != nil {
+		check.Detail = err.Error()
+		return []*models.HealthCheck{check}
+	}
+
+	switch clusterHealth.Status {
+	case "green":
+		check.Status = models.HealthStatusUp
+	case "yellow":
+		check.Status = models.HealthStatusDegraded
+	}
+	check.Detail = "Cluster is " + clusterHealth.Status
+	check.Data = map[string]interface{}{
+		"cluster_name":    clusterHealth.ClusterName,
+		"cluster_status":  clusterHealth.Status,
+		"number_of_nodes": clusterHealth.NumberOfNodes,
+	}
+
+	return []*models.HealthCheck{check}
+}
+
+// smtpChecker check the SMTP server is reachable
+type smtpChecker struct {
+	address string
+}
+
+// NewSMTPChecker return checker that open TCP connection on SMTP server
+func NewSMTPChecker(server string, port int) health.Checker {
+	return &smtpChecker{
+		address: net.JoinHostPort(server, strconv.Itoa(port)),
+	}
+}
+
+func (h *smtpChecker) Critical() bool {
+	return false
+}
+
+func (h *smtpChecker) Check(ctx context.Context) []*models.HealthCheck {
+	check := &models.HealthCheck{
+		Name:   h.address,
+		Kind:   KindSMTP,
+		Status: models.HealthStatusUp,
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", h.address)
+	if err != nil {
+		check.Status = models.HealthStatusDown
+		check.Detail = err.Error()
+		return []*models.HealthCheck{check}
+	}
+	_ = conn.Close()
+
+	return []*models.HealthCheck{check}
+}
+
+// boardChecker check each board is online and initialized
+type boardChecker struct {
+	boardUsecase board.Usecase
+}
+
+// NewBoardChecker return checker with one check per board
+func NewBoardChecker(boardUsecase board.Usecase) health.Checker {
+	return &boardChecker{
+		boardUsecase: boardUsecase,
+	}
+}
+
+func (h *boardChecker) Critical() bool {
+	return false
+}
+
+func (h *boardChecker) Check(ctx context.Context) []*models.HealthCheck {
+	boards, err := h.boardUsecase.GetBoards(ctx)
+	if err != nil {
+		return []*models.HealthCheck{
+			{
+				Name:   "boards",
+				Kind:   KindBoard,
+				Status: models.HealthStatusDown,
+				Detail: err.Error(),
+			},
+		}
+	}
+
+	checks := make([]*models.HealthCheck, 0, len(boards))
+	for _, b := range boards {
+		check := &models.HealthCheck{
+			Name:   b.Name,
+			Kind:   KindBoard,
+			Status: models.HealthStatusUp,
+			Data: map[string]interface{}{
+				"is_online":      b.IsOnline,
+				"is_initialized": b.IsInitialized,
+			},
+		}
+		if b.LastPoll != nil {
+			check.Data["last_poll"] = b.LastPoll
+			check.Data["seconds_since_last_poll"] = int64(time.Since(*b.LastPoll).Seconds())
+		}
+		switch {
+		case !b.IsOnline:
+			check.Status = models.HealthStatusDown
+			check.Detail = "Board is offline"
+		case !b.IsInitialized:
+			check.Status = models.HealthStatusDown
+			check.Detail = "Board is not initialized"
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// EventQueue is the queue of events that wait to be stored
+type EventQueue interface {
+	// Len return the number of events that wait on queue
+	Len() int
+
+	// Cap return the number of events that can wait on queue
+	Cap() int
+}
+
+// eventChecker check the backlog of event pipeline
+type eventChecker struct {
+	queue         EventQueue
+	outboxUsecase outbox.Usecase
+}
+
+// NewEventChecker return checker on events that wait to be stored and Elasticsearch writes that wait to be retried
+// The outboxUsecase can be nil
+func NewEventChecker(queue EventQueue, outboxUsecase outbox.Usecase) health.Checker {
+	return &eventChecker{
+		queue:         queue,
+		outboxUsecase: outboxUsecase,
+	}
+}
+
+func (h *eventChecker) Critical() bool {
+	return false
+}
+
+func (h *eventChecker) Check(ctx context.Context) []*models.HealthCheck {
+	check := &models.HealthCheck{
+		Name:   "events",
+		Kind:   KindEvents,
+		Status: models.HealthStatusUp,
+		Data: map[string]interface{}{
+			"queue_length":   h.queue.Len(),
+			"queue_capacity": h.queue.Cap(),
+		},
+	}
+	if h.queue.Cap() > 0 && float64(h.queue.Len()) >= eventQueueThreshold*float64(h.queue.Cap()) {
+		check.Status = models.HealthStatusDegraded
+		check.Detail = "Event queue is almost full"
+	}
+
+	if h.outboxUsecase != nil {
+		depth, err := h.outboxUsecase.Depth(ctx)
+		if err != nil {
+			check.Status = models.HealthStatusDegraded
+			check.Detail = err.Error()
+			return []*models.HealthCheck{check}
+		}
+		check.Data["outbox_depth"] = depth
+		if depth > 0 && check.Status == models.HealthStatusUp {
+			check.Status = models.HealthStatusDegraded
+			check.Detail = "Elasticsearch writes wait to be retried"
+		}
+	}
+
+	return []*models.HealthCheck{check}
+}
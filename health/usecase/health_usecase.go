@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/health"
+	"github.com/disaster37/gobot-fat/models"
+)
+
+type healthUsecase struct {
+	checkers       []health.Checker
+	contextTimeout time.Duration
+	sync.Mutex
+}
+
+// NewHealthUsecase will create new healthUsecase object of health.Usecase interface
+// Each checker has timeout to answer
+func NewHealthUsecase(timeout time.Duration) health.Usecase {
+	return &healthUsecase{
+		checkers:       make([]health.Checker, 0),
+		contextTimeout: timeout,
+	}
+}
+
+// AddChecker add checker on list
+func (h *healthUsecase) AddChecker(checker health.Checker) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.checkers = append(h.checkers, checker)
+}
+
+// Check run all checkers and return the service status
+func (h *healthUsecase) Check(ctx context.Context) *models.Health {
+	return h.check(ctx, false)
+}
+
+// Ready run only the critical checkers, to know if service can serve requests
+func (h *healthUsecase) Ready(ctx context.Context) *models.Health {
+	return h.check(ctx, true)
+}
+
+// check run checkers at the same time, and compute the service status:
+//   - down when a critical dependency is not up
+//   - degraded when a non critical dependency is not up
+//   - up otherwise
+func (h *healthUsecase) check(ctx context.Context, onlyCritical bool) *models.Health {
+	h.Lock()
+	checkers := make([]health.Checker, 0, len(h.checkers))
+	for _, checker := range h.checkers {
+		if !onlyCritical || checker.Critical() {
+			checkers = append(checkers, checker)
+		}
+	}
+	h.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	results := make([][]*models.HealthCheck, len(checkers))
+	wg := sync.WaitGroup{}
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker health.Checker) {
+			defer wg.Done()
+			results[i] = checker.Check(ctx)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	status := &models.Health{
+		ID:     "health",
+		Status: models.HealthStatusUp,
+		Checks: make([]*models.HealthCheck, 0, len(checkers)),
+	}
+	for i, checks := range results {
+		for _, check := range checks {
+			check.Critical = checkers[i].Critical()
+			status.Checks = append(status.Checks, check)
+
+			switch {
+			case check.Status == models.HealthStatusUp:
+			case check.Critical:
+				status.Status = models.HealthStatusDown
+			case status.Status == models.HealthStatusUp:
+				status.Status = models.HealthStatusDegraded
+			}
+		}
+	}
+
+	return status
+}
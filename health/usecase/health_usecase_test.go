@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+	boardUsecase "github.com/disaster37/gobot-fat/board/usecase"
+	"github.com/disaster37/gobot-fat/mock"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	elastic "github.com/elastic/go-elasticsearch/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChecker struct {
+	critical bool
+	status   string
+}
+
+func (h *fakeChecker) Critical() bool { return h.critical }
+func (h *fakeChecker) Check(ctx context.Context) []*models.HealthCheck {
+	return []*models.HealthCheck{{Name: "fake", Status: h.status}}
+}
+
+type genericBoard = board.Board
+
+type fakeBoard struct {
+	genericBoard
+	data *models.Board
+}
+
+func (h *fakeBoard) Name() string         { return h.data.Name }
+func (h *fakeBoard) Board() *models.Board { return h.data }
+
+type fakeQueue struct {
+	len int
+	cap int
+}
+
+func (h *fakeQueue) Len() int { return h.len }
+func (h *fakeQueue) Cap() int { return h.cap }
+
+func TestHealthStatus(t *testing.T) {
+
+	// Up when all is up
+	us := NewHealthUsecase(1 * time.Second)
+	us.AddChecker(&fakeChecker{critical: true, status: models.HealthStatusUp})
+	us.AddChecker(&fakeChecker{status: models.HealthStatusUp})
+	status := us.Check(context.Background())
+	assert.Equal(t, models.HealthStatusUp, status.Status)
+	assert.Len(t, status.Checks, 2)
+	assert.True(t, status.Checks[0].Critical)
+
+	// Degraded when non critical is not up, but still ready
+	us.AddChecker(&fakeChecker{status: models.HealthStatusDown})
+	assert.Equal(t, models.HealthStatusDegraded, us.Check(context.Background()).Status)
+	status = us.Ready(context.Background())
+	assert.Equal(t, models.HealthStatusUp, status.Status)
+	assert.Len(t, status.Checks, 1)
+
+	// Down when critical is not up
+	us.AddChecker(&fakeChecker{critical: true, status: models.HealthStatusDown})
+	assert.Equal(t, models.HealthStatusDown, us.Check(context.Background()).Status)
+	assert.Equal(t, models.HealthStatusDown, us.Ready(context.Background()).Status)
+}
+
+func TestSQLChecker(t *testing.T) {
+	conn, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "gobot-fat.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := conn.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := NewSQLChecker(db, "sqlite")
+	assert.True(t, checker.Critical())
+	assert.Equal(t, models.HealthStatusUp, checker.Check(context.Background())[0].Status)
+
+	_ = db.Close()
+	checks := checker.Check(context.Background())
+	assert.Equal(t, models.HealthStatusDown, checks[0].Status)
+	assert.NotEmpty(t, checks[0].Detail)
+}
+
+func TestElasticsearchChecker(t *testing.T) {
+	body := ""
+	mocktrans := &mock.MockTransport{}
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}},
+		}, nil
+	}
+	conn, _ := elastic.NewClient(elastic.Config{Transport: mocktrans})
+	checker := NewElasticsearchChecker(conn)
+
+	body = `{"cluster_name": "dfp", "status": "green", "number_of_nodes": 1}`
+	checks := checker.Check(context.Background())
+	assert.Equal(t, models.HealthStatusUp, checks[0].Status)
+	assert.Equal(t, 1, checks[0].Data["number_of_nodes"])
+
+	body = `{"cluster_name": "dfp", "status": "yellow", "number_of_nodes": 1}`
+	assert.Equal(t, models.HealthStatusDegraded, checker.Check(context.Background())[0].Status)
+
+	body = `{"cluster_name": "dfp", "status": "red", "number_of_nodes": 1}`
+	assert.Equal(t, models.HealthStatusDown, checker.Check(context.Background())[0].Status)
+}
+
+func TestSMTPChecker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	checker := NewSMTPChecker("127.0.0.1", port)
+	assert.Equal(t, models.HealthStatusUp, checker.Check(context.Background())[0].Status)
+
+	_ = listener.Close()
+	checks := checker.Check(context.Background())
+	assert.Equal(t, models.HealthStatusDown, checks[0].Status)
+	assert.Equal(t, "127.0.0.1:"+strconv.Itoa(port), checks[0].Name)
+}
+
+func TestBoardChecker(t *testing.T) {
+	lastPoll := time.Now().Add(-30 * time.Second)
+	boardU := boardUsecase.NewBoardUsecase(nil, 10*time.Second)
+	boardU.AddBoard(&fakeBoard{data: &models.Board{Name: "tfp", IsOnline: true, IsInitialized: true, LastPoll: &lastPoll}})
+	boardU.AddBoard(&fakeBoard{data: &models.Board{Name: "dfp", IsOnline: true}})
+	boardU.AddBoard(&fakeBoard{data: &models.Board{Name: "tank", IsInitialized: true}})
+
+	checks := NewBoardChecker(boardU).Check(context.Background())
+	assert.Len(t, checks, 3)
+	assert.Equal(t, models.HealthStatusUp, checks[0].Status)
+	assert.Equal(t, int64(30), checks[0].Data["seconds_since_last_poll"])
+	assert.Equal(t, models.HealthStatusDown, checks[1].Status)
+	assert.Equal(t, "Board is not initialized", checks[1].Detail)
+	assert.NotContains(t, checks[1].Data, "last_poll")
+	assert.Equal(t, models.HealthStatusDown, checks[2].Status)
+	assert.Equal(t, "Board is offline", checks[2].Detail)
+}
+
+func TestEventChecker(t *testing.T) {
+	queue := &fakeQueue{len: 10, cap: 100}
+	checker := NewEventChecker(queue, nil)
+	checks := checker.Check(context.Background())
+	assert.Equal(t, models.HealthStatusUp, checks[0].Status)
+	assert.Equal(t, 10, checks[0].Data["queue_length"])
+
+	queue.len = 90
+	assert.Equal(t, models.HealthStatusDegraded, checker.Check(context.Background())[0].Status)
+}
@@ -0,0 +1,52 @@
+package helper
+
+import (
+	"context"
+)
+
+const (
+	// OriginAPI is when action is called from API
+	OriginAPI = "api"
+
+	// OriginButton is when action is called from physical button
+	OriginButton = "button"
+
+	// OriginAuto is when action is called automatically by board
+	OriginAuto = "auto"
+)
+
+type actorKey struct{}
+
+// Actor is who trigger an action
+type Actor struct {
+	// User is the authenticated user, only when called from API
+	User string
+
+	// SourceIP is the client IP, only when called from API
+	SourceIP string
+
+	// Route is the API route, only when called from API
+	Route string
+
+	// Origin is api, button or auto
+	Origin string
+}
+
+// WithActor return context that carry the actor, to record it on events
+func WithActor(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// WithOrigin return context that carry actor with only origin, for button or automatic actions
+func WithOrigin(ctx context.Context, origin string) context.Context {
+	return WithActor(ctx, &Actor{Origin: origin})
+}
+
+// ActorFromContext return the actor carried by context or nil
+func ActorFromContext(ctx context.Context) *Actor {
+	if ctx == nil {
+		return nil
+	}
+	actor, _ := ctx.Value(actorKey{}).(*Actor)
+	return actor
+}
@@ -26,16 +26,41 @@ const (
 	KindEventTankLevel            = "tank_level"
 	KindEventStart                = "start"
 	KindEventStop                 = "stop"
+	KindEventSetBacterium         = "set_bacterium"
+	KindEventUnsetBacterium       = "unset_bacterium"
+	KindEventBlisterAlert         = "blister_alert"
+	KindEventSetTankLowLevel      = "set_tank_low_level"
+	KindEventUnsetTankLowLevel    = "unset_tank_low_level"
+	KindEventSetTankHighLevel     = "set_tank_high_level"
+	KindEventUnsetTankHighLevel   = "unset_tank_high_level"
+	KindEventSetDryRun            = "set_dry_run"
+	KindEventUnsetDryRun          = "unset_dry_run"
+	KindEventAudit                = "audit"
 )
 
 // SendEvent permit to send event on Elasticsearch
 func SendEvent(ctx context.Context, esUsecase usecase.UsecaseCRUD, sourceName string, kind string, name string, args ...interface{}) {
+	SendEventWithMessage(ctx, esUsecase, sourceName, kind, name, "", args...)
+}
+
+// SendEventWithMessage permit to send event with human readable message on Elasticsearch
+// The message is used by notifiers instead of the default one
+func SendEventWithMessage(ctx context.Context, esUsecase usecase.UsecaseCRUD, sourceName string, kind string, name string, message string, args ...interface{}) {
 
 	event := &models.Event{
 		SourceName: sourceName,
 		Timestamp:  time.Now(),
 		EventType:  name,
 		EventKind:  kind,
+		Message:    message,
+	}
+
+	// Add who trigger the action
+	if actor := ActorFromContext(ctx); actor != nil {
+		event.User = actor.User
+		event.SourceIP = actor.SourceIP
+		event.Route = actor.Route
+		event.Origin = actor.Origin
 	}
 
 	// Add extra infos
@@ -43,7 +68,7 @@ func SendEvent(ctx context.Context, esUsecase usecase.UsecaseCRUD, sourceName st
 		switch kind {
 		case KindEventTemperature:
 			event.Temperature = args[0].(float64)
-		case KindEventTankLevel:
+		case KindEventTankLevel, KindEventBlisterAlert, KindEventSetTankLowLevel, KindEventUnsetTankLowLevel, KindEventSetTankHighLevel, KindEventUnsetTankHighLevel, KindEventSetDryRun, KindEventUnsetDryRun:
 			event.Level = args[0].(int64)
 		}
 	}
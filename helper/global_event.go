@@ -7,4 +7,10 @@ const (
 	UnsetSecurity        = "unset-security"
 	SetDisableSecurity   = "set-disable-security"
 	UnsetDisableSecurity = "unset-disable-security"
+	SetTankLowLevel      = "set-tank-low-level"
+	UnsetTankLowLevel    = "unset-tank-low-level"
+	SetTankHighLevel     = "set-tank-high-level"
+	UnsetTankHighLevel   = "unset-tank-high-level"
+	SetDryRun            = "set-dry-run"
+	UnsetDryRun          = "unset-dry-run"
 )
@@ -0,0 +1,53 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/google/jsonapi"
+)
+
+// ErrorSource point on the attribute that cause the error
+type ErrorSource struct {
+	Pointer string `json:"pointer,omitempty"`
+}
+
+// ErrorObject is JSON:API error object with source, that google/jsonapi not provide
+type ErrorObject struct {
+	jsonapi.ErrorObject
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+// MarshalValidationErrors write one JSON:API error object for each invalid attribute, with pointer on it
+// Other errors are written as one error object
+func MarshalValidationErrors(w io.Writer, title string, err error) error {
+	errorObjects := make([]*ErrorObject, 0)
+
+	validationErrors, ok := err.(models.ValidationErrors)
+	if !ok {
+		errorObjects = append(errorObjects, &ErrorObject{
+			ErrorObject: jsonapi.ErrorObject{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  title,
+				Detail: err.Error(),
+			},
+		})
+	}
+	for _, validationError := range validationErrors {
+		errorObjects = append(errorObjects, &ErrorObject{
+			ErrorObject: jsonapi.ErrorObject{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  title,
+				Detail: fmt.Sprintf("%s %s", validationError.Attribute, validationError.Message),
+			},
+			Source: &ErrorSource{
+				Pointer: fmt.Sprintf("/data/attributes/%s", validationError.Attribute),
+			},
+		})
+	}
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{"errors": errorObjects})
+}
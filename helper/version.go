@@ -0,0 +1,69 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// HeaderIfMatch is the header where client send the version it read
+	HeaderIfMatch = "If-Match"
+
+	// HeaderETag is the header where the current version is sent to client
+	HeaderETag = "ETag"
+)
+
+// ETag return the ETag header value of version
+func ETag(version int64) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// ExpectedVersion return the version the client read before update, from If-Match header or from version attribute of JSON:API payload.
+// It return false when client not send it, so update is not conditional. The request body can be read again after.
+func ExpectedVersion(req *http.Request) (version int64, isSet bool, err error) {
+
+	// If-Match header
+	if ifMatch := strings.TrimSpace(req.Header.Get(HeaderIfMatch)); ifMatch != "" && ifMatch != "*" {
+		version, err = strconv.ParseInt(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), "\""), 10, 64)
+		if err != nil {
+			return 0, false, errors.Errorf("Invalid %s header: %s", HeaderIfMatch, ifMatch)
+		}
+		return version, true, nil
+	}
+
+	// Version attribute
+	if req.Body == nil {
+		return 0, false, nil
+	}
+	payload, err := io.ReadAll(req.Body)
+	if err != nil {
+		return 0, false, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+
+	document := &struct {
+		Data struct {
+			Attributes map[string]json.RawMessage `json:"attributes"`
+		} `json:"data"`
+	}{}
+	if err = json.Unmarshal(payload, document); err != nil {
+		// Invalid payload is handled when read it
+		return 0, false, nil
+	}
+	rawVersion, ok := document.Data.Attributes["version"]
+	if !ok {
+		return 0, false, nil
+	}
+	if err = json.Unmarshal(rawVersion, &version); err != nil {
+		return 0, false, errors.Errorf("Invalid version attribute: %s", string(rawVersion))
+	}
+
+	return version, true, nil
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/history"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/google/jsonapi"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// HistoryHandler represent the httphandler for config history
+type HistoryHandler struct {
+	resource       string
+	configUsecase  usecase.UsecaseCRUD
+	historyUsecase history.Usecase
+	newConfig      func() models.Model
+}
+
+// NewHistoryHandler will initialize the history endpoints of config resource, like dfp-configs
+// The newConfig return empty config used by rollback. The middlewares are only applied on rollback
+func NewHistoryHandler(e *echo.Group, resource string, configUsecase usecase.UsecaseCRUD, historyUsecase history.Usecase, newConfig func() models.Model, m ...echo.MiddlewareFunc) {
+	handler := &HistoryHandler{
+		resource:       resource,
+		configUsecase:  configUsecase,
+		historyUsecase: historyUsecase,
+		newConfig:      newConfig,
+	}
+	e.GET(fmt.Sprintf("/%s/:id/history", resource), handler.List)
+	e.GET(fmt.Sprintf("/%s/:id/history/diff", resource), handler.Diff)
+	e.POST(fmt.Sprintf("/%s/:id/rollback/:version", resource), handler.Rollback, m...)
+}
+
+// List return all versions of config, from the newest
+func (h *HistoryHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return marshalError(c, http.StatusBadRequest, "Error when get history", err)
+	}
+
+	revisions, err := h.historyUsecase.List(ctx, h.resource, uint(id))
+	if err != nil {
+		log.Errorf("Error when get %s history: %s", h.resource, err.Error())
+		return marshalError(c, http.StatusInternalServerError, "Error when get history", err)
+	}
+
+	c.Response().WriteHeader(http.StatusOK)
+	return jsonapi.MarshalPayload(c.Response(), revisions)
+}
+
+// Diff return the attributes changed between `from` and `to` versions
+func (h *HistoryHandler) Diff(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return marshalError(c, http.StatusBadRequest, "Error when get diff", err)
+	}
+	from, err := strconv.ParseInt(c.QueryParam("from"), 10, 64)
+	if err != nil {
+		return marshalError(c, http.StatusBadRequest, "Error when get diff", fmt.Errorf("Invalid from version: %w", err))
+	}
+	to, err := strconv.ParseInt(c.QueryParam("to"), 10, 64)
+	if err != nil {
+		return marshalError(c, http.StatusBadRequest, "Error when get diff", fmt.Errorf("Invalid to version: %w", err))
+	}
+
+	diff, err := h.historyUsecase.Diff(ctx, h.resource, uint(id), from, to)
+	if err != nil {
+		if repository.IsRecordNotFoundError(err) {
+			return marshalError(c, http.StatusNotFound, "Error when get diff", err)
+		}
+		log.Errorf("Error when get %s diff: %s", h.resource, err.Error())
+		return marshalError(c, http.StatusInternalServerError, "Error when get diff", err)
+	}
+
+	c.Response().WriteHeader(http.StatusOK)
+	return jsonapi.MarshalPayload(c.Response(), diff)
+}
+
+// Rollback restore the config of version as new version, and apply it on board
+func (h *HistoryHandler) Rollback(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return marshalError(c, http.StatusBadRequest, "Error when rollback config", err)
+	}
+	version, err := strconv.ParseInt(c.Param("version"), 10, 64)
+	if err != nil {
+		return marshalError(c, http.StatusBadRequest, "Error when rollback config", err)
+	}
+
+	config := h.newConfig()
+	config.SetID(uint(id))
+	if err = h.historyUsecase.Rollback(ctx, h.configUsecase, h.resource, config, version); err != nil {
+		switch {
+		case repository.IsRecordNotFoundError(err):
+			return marshalError(c, http.StatusNotFound, "Error when rollback config", err)
+		case repository.IsVersionConflictError(err), err == history.ErrConfigDeleted:
+			return marshalError(c, http.StatusConflict, "Error when rollback config", err)
+		}
+		if _, ok := err.(models.ValidationErrors); ok {
+			c.Response().WriteHeader(http.StatusBadRequest)
+			return helper.MarshalValidationErrors(c.Response(), "Error when rollback config", err)
+		}
+		log.Errorf("Error when rollback %s to version %d: %s", h.resource, version, err.Error())
+		return marshalError(c, http.StatusInternalServerError, "Error when rollback config", err)
+	}
+
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(config.GetVersion()))
+	c.Response().WriteHeader(http.StatusCreated)
+	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), config)
+}
+
+// marshalError write the JSON:API error with status
+func marshalError(c echo.Context, status int, title string, err error) error {
+	c.Response().WriteHeader(status)
+	return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+		{
+			Status: fmt.Sprintf("%d", status),
+			Title:  title,
+			Detail: err.Error(),
+		},
+	})
+}
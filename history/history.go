@@ -0,0 +1,32 @@
+package history
+
+import (
+	"context"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/pkg/errors"
+)
+
+// ErrConfigDeleted is returned when rollback deleted config
+var ErrConfigDeleted = errors.New("Config is deleted")
+
+// Usecase is the config history interface
+type Usecase interface {
+	// Record store the config as new revision of kind, if its version is not already stored
+	Record(ctx context.Context, kind string, config models.Model) error
+
+	// List return the revisions of config, from the newest
+	List(ctx context.Context, kind string, id uint) ([]*models.ConfigRevision, error)
+
+	// Get return the revision of config with version
+	Get(ctx context.Context, kind string, id uint, version int64) (*models.ConfigRevision, error)
+
+	// Diff return the attributes changed between two versions of config
+	Diff(ctx context.Context, kind string, id uint, from int64, to int64) (*models.ConfigDiff, error)
+
+	// Rollback update config with the attributes of version, as new version.
+	// The config is a pointer on model with ID, it contain the new version after rollback.
+	// The fixed attributes of config are kept, and it return ErrConfigDeleted when config is deleted
+	Rollback(ctx context.Context, configUsecase usecase.UsecaseCRUD, kind string, config models.Model, version int64) error
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/disaster37/gobot-fat/history"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/usecase"
+	log "github.com/sirupsen/logrus"
+)
+
+// historyConfigUsecase store a revision each time config is written
+type historyConfigUsecase struct {
+	usecase.UsecaseCRUD
+	historyUsecase history.Usecase
+	kind           string
+}
+
+// NewConfigUsecase wrap the config usecase to record each version on history
+// A failed record is logged and not fail the update
+func NewConfigUsecase(configUsecase usecase.UsecaseCRUD, historyUsecase history.Usecase, kind string) usecase.UsecaseCRUD {
+	return &historyConfigUsecase{
+		UsecaseCRUD:    configUsecase,
+		historyUsecase: historyUsecase,
+		kind:           kind,
+	}
+}
+
+// Create create config, then record it
+func (h *historyConfigUsecase) Create(ctx context.Context, data interface{}) error {
+	if err := h.UsecaseCRUD.Create(ctx, data); err != nil {
+		return err
+	}
+	h.record(ctx, data)
+	return nil
+}
+
+// Update update config, then record it
+func (h *historyConfigUsecase) Update(ctx context.Context, data interface{}) error {
+	if err := h.UsecaseCRUD.Update(ctx, data); err != nil {
+		return err
+	}
+	h.record(ctx, data)
+	return nil
+}
+
+// UpdateIfVersion update config if not updated since version, then record it
+func (h *historyConfigUsecase) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+	if err := h.UsecaseCRUD.UpdateIfVersion(ctx, data, version); err != nil {
+		return err
+	}
+	h.record(ctx, data)
+	return nil
+}
+
+// Init init config, then record the current version if it's not already recorded
+func (h *historyConfigUsecase) Init(ctx context.Context, data interface{}) error {
+	if err := h.UsecaseCRUD.Init(ctx, data); err != nil {
+		return err
+	}
+	h.record(ctx, data)
+	return nil
+}
+
+func (h *historyConfigUsecase) record(ctx context.Context, data interface{}) {
+	config, ok := data.(models.Model)
+	if !ok {
+		return
+	}
+	if err := h.historyUsecase.Record(context.WithoutCancel(ctx), h.kind, config); err != nil {
+		log.Errorf("Error when record %s version %d on history: %s", h.kind, config.GetVersion(), err.Error())
+	}
+}
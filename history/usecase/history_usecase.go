@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/history"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/pkg/errors"
+)
+
+// ignoredAttributes are the attributes not compared on diff, because they change on each version
+var ignoredAttributes = map[string]bool{
+	"version":   true,
+	"CreatedAt": true,
+	"UpdatedAt": true,
+	"DeletedAt": true,
+}
+
+type historyUsecase struct {
+	repo           repository.SearchRepository
+	contextTimeout time.Duration
+}
+
+// NewHistoryUsecase will create new historyUsecase object of history.Usecase interface
+// The repository must support search, like SQL repository
+func NewHistoryUsecase(repo repository.SearchRepository, timeout time.Duration) history.Usecase {
+	return &historyUsecase{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+// Record store the config as new revision of kind, if its version is not already stored
+func (h *historyUsecase) Record(c context.Context, kind string, config models.Model) error {
+
+	if config == nil {
+		return errors.New("Config can't be null")
+	}
+
+	_, err := h.Get(c, kind, config.GetID(), config.GetVersion())
+	if err == nil {
+		return nil
+	}
+	if !repository.IsRecordNotFoundError(err) {
+		return err
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	revision := &models.ConfigRevision{
+		Kind:          kind,
+		ConfigID:      config.GetID(),
+		ConfigVersion: config.GetVersion(),
+		Timestamp:     time.Now(),
+	}
+	if err = json.Unmarshal(b, &revision.Data); err != nil {
+		return err
+	}
+	if actor := helper.ActorFromContext(c); actor != nil {
+		revision.Author = actor.User
+		revision.Origin = actor.Origin
+	}
+
+	ctx, cancel := context.WithTimeout(c, h.contextTimeout)
+	defer cancel()
+
+	return h.repo.Create(ctx, revision)
+}
+
+// List return the revisions of config, from the newest
+func (h *historyUsecase) List(c context.Context, kind string, id uint) ([]*models.ConfigRevision, error) {
+	ctx, cancel := context.WithTimeout(c, h.contextTimeout)
+	defer cancel()
+
+	revisions := make([]*models.ConfigRevision, 0)
+	_, err := h.repo.Search(ctx, &repository.Query{
+		Filters: map[string]interface{}{
+			"kind":      kind,
+			"config_id": id,
+		},
+		SortField: "config_version",
+		SortDesc:  true,
+	}, &revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Get return the revision of config with version
+func (h *historyUsecase) Get(c context.Context, kind string, id uint, version int64) (*models.ConfigRevision, error) {
+	ctx, cancel := context.WithTimeout(c, h.contextTimeout)
+	defer cancel()
+
+	revisions := make([]*models.ConfigRevision, 0)
+	_, err := h.repo.Search(ctx, &repository.Query{
+		Filters: map[string]interface{}{
+			"kind":           kind,
+			"config_id":      id,
+			"config_version": version,
+		},
+		Size: 1,
+	}, &revisions)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, repository.ErrRecordNotFoundError
+	}
+
+	return revisions[0], nil
+}
+
+// Diff return the attributes changed between two versions of config
+func (h *historyUsecase) Diff(ctx context.Context, kind string, id uint, from int64, to int64) (*models.ConfigDiff, error) {
+
+	fromRevision, err := h.Get(ctx, kind, id, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := h.Get(ctx, kind, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make([]string, 0, len(toRevision.Data))
+	for attribute := range fromRevision.Data {
+		attributes = append(attributes, attribute)
+	}
+	for attribute := range toRevision.Data {
+		if _, ok := fromRevision.Data[attribute]; !ok {
+			attributes = append(attributes, attribute)
+		}
+	}
+	sort.Strings(attributes)
+
+	diff := &models.ConfigDiff{
+		ID:      fmt.Sprintf("%s-%d-%d-%d", kind, id, from, to),
+		From:    from,
+		To:      to,
+		Changes: make([]*models.ConfigChange, 0),
+	}
+	for _, attribute := range attributes {
+		if ignoredAttributes[attribute] || reflect.DeepEqual(fromRevision.Data[attribute], toRevision.Data[attribute]) {
+			continue
+		}
+		diff.Changes = append(diff.Changes, &models.ConfigChange{
+			Attribute: attribute,
+			From:      fromRevision.Data[attribute],
+			To:        toRevision.Data[attribute],
+		})
+	}
+
+	return diff, nil
+}
+
+// Rollback update config with the attributes of version, as new version.
+// It's a conditional update on current version, so a concurrent update is not overwritten.
+// The fixed attributes, like tank name, and the deleted date are not rolled back.
+func (h *historyUsecase) Rollback(ctx context.Context, configUsecase usecase.UsecaseCRUD, kind string, config models.Model, version int64) error {
+
+	if config == nil {
+		return errors.New("Config can't be null")
+	}
+	id := config.GetID()
+
+	revision, err := h.Get(ctx, kind, id, version)
+	if err != nil {
+		return err
+	}
+
+	if err = configUsecase.Get(ctx, id, config); err != nil {
+		return err
+	}
+	currentVersion := config.GetVersion()
+	current := make(map[string]interface{})
+	b, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, &current); err != nil {
+		return err
+	}
+	if current["DeletedAt"] != nil {
+		return history.ErrConfigDeleted
+	}
+
+	// The deleted date and fixed attributes are kept from current config
+	data := make(map[string]interface{}, len(revision.Data))
+	for attribute, value := range revision.Data {
+		data[attribute] = value
+	}
+	delete(data, "DeletedAt")
+	if fixed, ok := config.(models.Fixed); ok {
+		for _, attribute := range fixed.FixedAttributes() {
+			delete(data, attribute)
+		}
+	}
+
+	b, err = json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, config); err != nil {
+		return err
+	}
+	config.SetID(id)
+	config.SetVersion(currentVersion)
+
+	if err = models.Validate(config); err != nil {
+		return err
+	}
+
+	return configUsecase.UpdateIfVersion(ctx, config, currentVersion)
+}
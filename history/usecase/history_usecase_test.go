@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/history"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/stretchr/testify/assert"
+	"gobot.io/x/gobot/v2"
+)
+
+func TestHistory(t *testing.T) {
+	conn, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "gobot-fat.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.AutoMigrate(&models.TankConfig{}, &models.ConfigRevision{}); err != nil {
+		t.Fatal(err)
+	}
+	eventer := gobot.NewEventer()
+	eventer.AddEvent("newTankConfig")
+	chEvent := eventer.Subscribe()
+
+	historyUsecase := NewHistoryUsecase(repository.NewSQLRepository(conn).(repository.SearchRepository), 10*time.Second)
+	configUsecase := NewConfigUsecase(
+		usecase.NewUsecase(repository.NewSQLRepository(conn), repository.NewNoopRepository(), 10*time.Second, eventer, "newTankConfig"),
+		historyUsecase,
+		"tank-configs",
+	)
+
+	// Init record the first version
+	config := &models.TankConfig{Name: "pond", Depth: 200, LiterPerCm: 50}
+	config.ID = 1
+	assert.NoError(t, configUsecase.Init(context.Background(), config))
+	assert.NoError(t, configUsecase.Init(context.Background(), config))
+	revisions, err := historyUsecase.List(context.Background(), "tank-configs", 1)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, int64(0), revisions[0].ConfigVersion)
+
+	// Each update record new version with author
+	ctx := helper.WithActor(context.Background(), &helper.Actor{User: "admin", Origin: helper.OriginAPI})
+	config.Depth = 150
+	assert.NoError(t, configUsecase.Update(ctx, config))
+	config.Depth = 100
+	config.LowLevelThreshold = 10
+	assert.NoError(t, configUsecase.UpdateIfVersion(ctx, config, 1))
+	revisions, err = historyUsecase.List(context.Background(), "tank-configs", 1)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 3)
+	assert.Equal(t, int64(2), revisions[0].ConfigVersion)
+	assert.Equal(t, "admin", revisions[0].Author)
+	assert.Equal(t, helper.OriginAPI, revisions[0].Origin)
+	assert.Equal(t, float64(100), revisions[0].Data["depth"])
+
+	// Other configs are not mixed
+	revisions, err = historyUsecase.List(context.Background(), "dfp-configs", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, revisions)
+
+	// Diff
+	diff, err := historyUsecase.Diff(context.Background(), "tank-configs", 1, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ConfigChange{
+		{Attribute: "depth", From: float64(200), To: float64(100)},
+		{Attribute: "low_level_threshold", From: float64(0), To: float64(10)},
+	}, diff.Changes)
+	_, err = historyUsecase.Diff(context.Background(), "tank-configs", 1, 0, 10)
+	assert.True(t, repository.IsRecordNotFoundError(err))
+
+	// Rollback restore version as new version and publish it
+	rollback := &models.TankConfig{}
+	rollback.ID = 1
+	assert.NoError(t, historyUsecase.Rollback(ctx, configUsecase, "tank-configs", rollback, 0))
+	assert.Equal(t, int64(200), rollback.Depth)
+	assert.Equal(t, int64(0), rollback.LowLevelThreshold)
+	assert.Equal(t, int64(3), rollback.Version)
+	// Events of previous updates can be received before
+	isPublished := false
+	timeout := time.After(1 * time.Second)
+	for !isPublished {
+		select {
+		case event := <-chEvent:
+			if published := event.Data.(*models.TankConfig); published.Version == 3 {
+				assert.Equal(t, int64(200), published.Depth)
+				isPublished = true
+			}
+		case <-timeout:
+			t.Fatal("Rollback not publish config")
+		}
+	}
+	current := &models.TankConfig{}
+	assert.NoError(t, configUsecase.Get(context.Background(), 1, current))
+	assert.Equal(t, int64(200), current.Depth)
+	assert.Equal(t, int64(3), current.Version)
+	revisions, err = historyUsecase.List(context.Background(), "tank-configs", 1)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 4)
+
+	// Rollback on unknown version
+	err = historyUsecase.Rollback(ctx, configUsecase, "tank-configs", &models.TankConfig{ID: 1}, 10)
+	assert.True(t, repository.IsRecordNotFoundError(err))
+
+	// Name and URL are kept
+	current.Name = "shed"
+	current.URL = "http://192.168.0.193"
+	assert.NoError(t, configUsecase.Update(ctx, current))
+	rollback = &models.TankConfig{}
+	rollback.ID = 1
+	assert.NoError(t, historyUsecase.Rollback(ctx, configUsecase, "tank-configs", rollback, 0))
+	assert.Equal(t, "shed", rollback.Name)
+	assert.Equal(t, "http://192.168.0.193", rollback.URL)
+
+	// Deleted config can't be rolled back
+	now := time.Now()
+	rollback.DeletedAt = &now
+	assert.NoError(t, configUsecase.Update(ctx, rollback))
+	err = historyUsecase.Rollback(ctx, configUsecase, "tank-configs", &models.TankConfig{ID: 1}, 0)
+	assert.ErrorIs(t, err, history.ErrConfigDeleted)
+	assert.NoError(t, configUsecase.Get(context.Background(), 1, current))
+	assert.NotNil(t, current.DeletedAt)
+}
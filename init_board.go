@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/gobot-fat/backup"
+	"github.com/disaster37/gobot-fat/board"
+	"github.com/disaster37/gobot-fat/dfp"
+	"github.com/disaster37/gobot-fat/history"
+	"github.com/disaster37/gobot-fat/outbox"
+	"github.com/disaster37/gobot-fat/registry"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/tfp"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"gobot.io/x/gobot/v2"
+	"gorm.io/gorm"
+)
+
+// boardDependencies are the components shared by all boards
+type boardDependencies struct {
+	eventer        gobot.Eventer
+	api            *echo.Group
+	configHandler  *viper.Viper
+	elasticConn    *elasticsearch.Client
+	sqlConn        *gorm.DB
+	eventUsecase   usecase.UsecaseCRUD
+	boardUsecase   board.Usecase
+	outboxUsecase  outbox.Usecase
+	historyUsecase history.Usecase
+	backupUsecase  backup.Usecase
+	timeout        time.Duration
+
+	// adminMiddleware is applied on routes that only admin can use
+	adminMiddleware echo.MiddlewareFunc
+}
+
+// instanceGroups return the route groups of board instance
+// Each board is on `/api/boards/<name>` routes, and the first board of type also on the routes without board name
+func (h *boardDependencies) instanceGroups(instance *registry.Instance, isFirst bool) []*echo.Group {
+	groups := []*echo.Group{h.api.Group("/boards/" + instance.Name)}
+	if isFirst {
+		groups = append(groups, h.api)
+	}
+
+	return groups
+}
+
+// init boards declared on config from board types registry
+// It return the usecases of each DFP and TFP by board name, and the usecase of all tanks
+func initBoards(ctx context.Context, deps *boardDependencies) (dfpUs map[string]dfp.Usecase, tfpUs map[string]tfp.Usecase, tankU tank.Usecase, err error) {
+
+	dfpF := newDFPFactory(deps)
+	tankF := newTankFactory(deps)
+	tfpF := newTFPFactory(deps, tankF.Usecase())
+
+	// Boards are stopped in reverse order: DFP, TFP, then tanks that protect TFP pumps from dry run
+	boardRegistry := registry.NewRegistry()
+	boardRegistry.Register(registry.TypeTank, tankF.Create)
+	boardRegistry.Register(registry.TypeTFP, tfpF.Create)
+	boardRegistry.Register(registry.TypeDFP, dfpF.Create)
+
+	// Tanks on config not take the ID of tanks created over API
+	tankIDs, err := tankF.ReservedIDs(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	instances, err := registry.Instances(deps.configHandler, map[string][]uint{registry.TypeTank: tankIDs})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err = boardRegistry.Init(ctx, instances); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Tanks created over API
+	if err = tankF.Usecase().Load(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return dfpF.Usecases(), tfpF.Usecases(), tankF.Usecase(), nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/disaster37/gobot-fat/repository"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	dbDriverPostgres = "postgres"
+	dbDriverSQLite   = "sqlite"
+)
+
+// init SQL database from `db.driver` setting, postgres by default
+// With sqlite, the database is stored on `db.path` file
+func initDB(configHandler *viper.Viper) (db *gorm.DB, err error) {
+
+	switch configHandler.GetString("db.driver") {
+	case dbDriverSQLite:
+		db, err = repository.OpenSQLite(configHandler.GetString("db.path"))
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Use SQLite database %s", configHandler.GetString("db.path"))
+		return db, nil
+	case dbDriverPostgres, "":
+		// Wait postgresql is ready
+		for {
+			dsn := fmt.Sprintf("host=%s port=5432 user=%s dbname=%s password=%s sslmode=disable", configHandler.GetString("db.host"), configHandler.GetString("db.user"), configHandler.GetString("db.name"), configHandler.GetString("db.password"))
+			log.Debug(dsn)
+			db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+			if err == nil {
+				return db, nil
+			}
+			log.Errorf("failed to connect on postgresql: %s", err.Error())
+			time.Sleep(10 * time.Second)
+		}
+	default:
+		return nil, fmt.Errorf("DB driver %s not supported", configHandler.GetString("db.driver"))
+	}
+}
@@ -2,9 +2,9 @@ package main
 
 import (
 	"context"
-	"time"
 
-	"github.com/disaster37/gobot-fat/board"
+	"github.com/disaster37/gobot-fat/backup"
+	"github.com/disaster37/gobot-fat/dfp"
 	dfpboard "github.com/disaster37/gobot-fat/dfp/board"
 	dfpHttpDeliver "github.com/disaster37/gobot-fat/dfp/delivery/http"
 	dfpusecase "github.com/disaster37/gobot-fat/dfp/usecase"
@@ -12,27 +12,65 @@ import (
 	dfpConfigHttpDeliver "github.com/disaster37/gobot-fat/dfpconfig/delivery/http"
 	"github.com/disaster37/gobot-fat/dfpstate"
 	dfpStateHttpDeliver "github.com/disaster37/gobot-fat/dfpstate/delivery/http"
-	"github.com/disaster37/gobot-fat/mail"
+	historyHttpDeliver "github.com/disaster37/gobot-fat/history/delivery/http"
+	historyusecase "github.com/disaster37/gobot-fat/history/usecase"
 	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/registry"
 	"github.com/disaster37/gobot-fat/repository"
 	"github.com/disaster37/gobot-fat/usecase"
-	"github.com/elastic/go-elasticsearch/v8"
-	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
-	"gobot.io/x/gobot/v2"
-	"gorm.io/gorm"
 )
 
-// init DFP config, state and board usecase
-func initDFP(ctx context.Context, eventer gobot.Eventer, api *echo.Group, configHandler *viper.Viper, elacticConn *elasticsearch.Client, sqlConn *gorm.DB, eventUsecase usecase.UsecaseCRUD, boardUsecase board.Usecase, mailClient mail.Mail) (err error) {
+// dfpFactory create DFP boards
+// The config and state usecases are shared by all DFP
+type dfpFactory struct {
+	deps             *boardDependencies
+	dfpConfigUsecase usecase.UsecaseCRUD
+	dfpStateUsecase  usecase.UsecaseCRUD
+	dfpUsecases      map[string]dfp.Usecase
+	nbInstances      int
+}
+
+func newDFPFactory(deps *boardDependencies) *dfpFactory {
+	return &dfpFactory{
+		deps:        deps,
+		dfpUsecases: make(map[string]dfp.Usecase),
+	}
+}
 
-	timeout := time.Duration(configHandler.GetInt("context.timeout")) * time.Second
+// Usecases return the usecase of each enabled DFP by board name
+func (h *dfpFactory) Usecases() map[string]dfp.Usecase {
+	return h.dfpUsecases
+}
+
+// init DFP config and state usecases, on first DFP
+func (h *dfpFactory) init() {
+	if h.dfpConfigUsecase != nil {
+		return
+	}
+
+	// DFP config
+	dfpConfigRepoSQL := repository.NewSQLRepository(h.deps.sqlConn)
+	dfpConfigRepoES := newElasticsearchRepository(h.deps.elasticConn, h.deps.configHandler.GetString("elasticsearch.index.dfp_config"), h.deps.outboxUsecase)
+	h.dfpConfigUsecase = historyusecase.NewConfigUsecase(usecase.NewUsecase(dfpConfigRepoSQL, dfpConfigRepoES, h.deps.timeout, h.deps.eventer, dfpconfig.NewDFPConfig), h.deps.historyUsecase, "dfp-configs")
+	historyHttpDeliver.NewHistoryHandler(h.deps.api, "dfp-configs", h.dfpConfigUsecase, h.deps.historyUsecase, func() models.Model { return &models.DFPConfig{} })
+	h.deps.backupUsecase.Register(backup.KindDFPConfig, h.dfpConfigUsecase)
+
+	// DFP state
+	dfpStateRepoSQL := repository.NewSQLRepository(h.deps.sqlConn)
+	dfpStateRepoES := newElasticsearchRepository(h.deps.elasticConn, h.deps.configHandler.GetString("elasticsearch.index.dfp_state"), h.deps.outboxUsecase)
+	h.dfpStateUsecase = usecase.NewUsecase(dfpStateRepoSQL, dfpStateRepoES, h.deps.timeout, h.deps.eventer, dfpstate.NewDFPState)
+	h.deps.backupUsecase.Register(backup.KindDFPState, h.dfpStateUsecase)
+}
+
+// Create init DFP config, state and board usecase of instance
+func (h *dfpFactory) Create(ctx context.Context, instance *registry.Instance) (err error) {
+	h.init()
+	groups := h.deps.instanceGroups(instance, h.nbInstances == 0)
+	h.nbInstances++
 
 	// DFP config
-	dfpConfigRepoSQL := repository.NewSQLRepository(sqlConn)
-	dfpConfigRepoES := repository.NewElasticsearchRepository(elacticConn, configHandler.GetString("elasticsearch.index.dfp_config"))
-	dfpConfigUsecase := usecase.NewUsecase(dfpConfigRepoSQL, dfpConfigRepoES, timeout, eventer, dfpconfig.NewDFPConfig)
 	dfpConfig := &models.DFPConfig{
 		Enable:                         true,
 		ForceWashingDuration:           180,
@@ -44,55 +82,48 @@ func initDFP(ctx context.Context, eventer gobot.Eventer, api *echo.Group, config
 		WaitTimeBeforeUnsetSecurity:    7200,
 		TemperatureSensorPolling:       60,
 	}
-	dfpConfig.ID = dfpconfig.ID
-	err = dfpConfigUsecase.Init(ctx, dfpConfig)
-	if err != nil {
-		log.Errorf("Error appear when init DFP config: %s", err.Error())
-		panic("Failed to retrive dfpconfig from sql")
+	dfpConfig.ID = instance.ID
+	if err = h.dfpConfigUsecase.Init(ctx, dfpConfig); err != nil {
+		return errors.Wrap(err, "Error appear when init DFP config")
 	}
-	err = dfpConfigUsecase.Get(ctx, dfpconfig.ID, dfpConfig)
-	if err != nil {
-		log.Errorf("Failed to retrive dfpconfig from usecase")
-		panic("Failed to retrive dfpconfig from usecase")
+	if err = h.dfpConfigUsecase.Get(ctx, instance.ID, dfpConfig); err != nil {
+		return errors.Wrap(err, "Failed to retrive dfpconfig from usecase")
 	}
-	log.Info("Get dfpconfig successfully")
-	dfpConfigHttpDeliver.NewDFPConfigHandler(api, dfpConfigUsecase)
+	log.Infof("Get dfpconfig %d successfully", instance.ID)
 
 	// DFP state
-	dfpStateRepoSQL := repository.NewSQLRepository(sqlConn)
-	dfpStateRepoES := repository.NewElasticsearchRepository(elacticConn, configHandler.GetString("elasticsearch.index.dfp_state"))
-	dfpStateUsecase := usecase.NewUsecase(dfpStateRepoSQL, dfpStateRepoES, timeout, eventer, dfpstate.NewDFPState)
 	dfpState := &models.DFPState{
-		Name:               configHandler.GetString("dfp.name"),
+		Name:               instance.Name,
 		IsWashed:           false,
 		IsRunning:          true,
 		IsSecurity:         false,
 		IsEmergencyStopped: false,
 		IsDisableSecurity:  false,
 	}
-	dfpState.ID = dfpstate.ID
-	err = dfpStateUsecase.Init(ctx, dfpState)
-	if err != nil {
-		log.Errorf("Error appear when init DFP state: %s", err.Error())
-		panic("Failed to init dfpState on SQL")
+	dfpState.ID = instance.ID
+	if err = h.dfpStateUsecase.Init(ctx, dfpState); err != nil {
+		return errors.Wrap(err, "Error appear when init DFP state")
+	}
+	if err = h.dfpStateUsecase.Get(ctx, instance.ID, dfpState); err != nil {
+		return errors.Wrap(err, "Failed to retrive dfpState from usecase")
 	}
-	err = dfpStateUsecase.Get(ctx, dfpstate.ID, dfpState)
 	log.Debugf("DFP state after init it: %s", dfpState)
-	if err != nil {
-		log.Errorf("Failed to retrive dfpState from usecase")
-		panic("Failed to retrive dfpState from usecase")
+	log.Infof("Get dfpState %d successfully", instance.ID)
+
+	for _, group := range groups {
+		dfpConfigHttpDeliver.NewDFPConfigHandler(group, h.dfpConfigUsecase, instance.ID)
+		dfpStateHttpDeliver.NewDFPStateHandler(group, h.dfpStateUsecase, instance.ID)
 	}
-	log.Info("Get dfpState successfully")
-	dfpStateHttpDeliver.NewDFPStateHandler(api, dfpStateUsecase)
 
 	// DFP board
-	if configHandler.GetBool("dfp.enable") {
-		dfpConfigViper := configHandler.Sub("dfp")
-		dfpConfigViper.Set("fake-board", configHandler.GetBool("fake-board"))
-		dfpBoard := dfpboard.NewDFP(dfpConfigViper, dfpConfig, dfpState, eventUsecase, dfpStateUsecase, eventer, mailClient)
-		boardUsecase.AddBoard(dfpBoard)
-		dfpUsecase := dfpusecase.NewDFPUsecase(dfpBoard, timeout)
-		dfpHttpDeliver.NewDFPHandler(api, dfpUsecase)
+	if instance.Enable {
+		dfpBoard := dfpboard.NewDFP(instance.Settings, dfpConfig, dfpState, h.deps.eventUsecase, h.dfpStateUsecase, h.deps.eventer)
+		h.deps.boardUsecase.AddBoard(dfpBoard)
+		dfpUsecase := dfpusecase.NewDFPUsecase(dfpBoard, h.deps.timeout)
+		for _, group := range groups {
+			dfpHttpDeliver.NewDFPHandler(group, dfpUsecase)
+		}
+		h.dfpUsecases[instance.Name] = dfpUsecase
 	}
 
 	return nil
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/disaster37/gobot-fat/event"
+	eventSearchUsecase "github.com/disaster37/gobot-fat/event/usecase"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/outbox"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/elastic/go-elasticsearch/v8"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const (
+	eventStorageElasticsearch = "elasticsearch"
+	eventStorageSQL           = "sql"
+	eventStorageBoth          = "both"
+)
+
+// init Elasticsearch client, it's nil when `elasticsearch.urls` is empty
+func initElasticsearch(configHandler *viper.Viper) (*elasticsearch.Client, error) {
+
+	if len(configHandler.GetStringSlice("elasticsearch.urls")) == 0 {
+		log.Info("Elasticsearch is not configured")
+		return nil, nil
+	}
+
+	cfg := elasticsearch.Config{
+		Addresses: configHandler.GetStringSlice("elasticsearch.urls"),
+		Username:  configHandler.GetString("elasticsearch.username"),
+		Password:  configHandler.GetString("elasticsearch.password"),
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	return elasticsearch.NewClient(cfg)
+}
+
+// newElasticsearchRepository return repository on index that queue failed writes on outbox
+// It store nothing when Elasticsearch is not configured
+func newElasticsearchRepository(elasticConn *elasticsearch.Client, index string, outboxUsecase outbox.Usecase) repository.Repository {
+	if elasticConn == nil {
+		return repository.NewNoopRepository()
+	}
+
+	return outboxUsecase.Wrap(index, repository.NewElasticsearchRepository(elasticConn, index))
+}
+
+// init event storage from `event.storage` setting: elasticsearch, sql or both.
+// It's elasticsearch by default, or sql when Elasticsearch is not configured.
+// It return the usecase to store events on background and the usecase to search them
+func initEvent(configHandler *viper.Viper, elasticConn *elasticsearch.Client, sqlConn *gorm.DB, outboxUsecase outbox.Usecase) (eventUsecase *usecase.UsecaseBulkEvent, searchUsecase event.Usecase, err error) {
+
+	timeout := time.Duration(configHandler.GetInt("context.timeout")) * time.Second
+
+	storage := configHandler.GetString("event.storage")
+	if storage == "" {
+		storage = eventStorageElasticsearch
+		if elasticConn == nil {
+			storage = eventStorageSQL
+		}
+	}
+	if storage != eventStorageSQL && elasticConn == nil {
+		return nil, nil, fmt.Errorf("Event storage %s need Elasticsearch", storage)
+	}
+
+	// Events are searched and purged on search repositories, and stored on store repositories
+	// The store repository of Elasticsearch queue failed writes on outbox
+	var searchRepo, storeRepo repository.Repository
+	var otherSearchRepos, otherStoreRepos []repository.Repository
+	if storage == eventStorageSQL || storage == eventStorageBoth {
+		if err = sqlConn.AutoMigrate(&models.Event{}); err != nil {
+			return nil, nil, err
+		}
+	}
+	switch storage {
+	case eventStorageElasticsearch:
+		eventRepoES := repository.NewElasticsearchRepository(elasticConn, configHandler.GetString("elasticsearch.index.event"), false)
+		searchRepo = eventRepoES
+		storeRepo = outboxUsecase.Wrap(configHandler.GetString("elasticsearch.index.event"), eventRepoES)
+	case eventStorageSQL:
+		searchRepo = repository.NewSQLRepository(sqlConn)
+		storeRepo = searchRepo
+	case eventStorageBoth:
+		eventRepoES := repository.NewElasticsearchRepository(elasticConn, configHandler.GetString("elasticsearch.index.event"), false)
+		searchRepo = repository.NewSQLRepository(sqlConn)
+		storeRepo = searchRepo
+		otherSearchRepos = append(otherSearchRepos, eventRepoES)
+		otherStoreRepos = append(otherStoreRepos, outboxUsecase.Wrap(configHandler.GetString("elasticsearch.index.event"), eventRepoES))
+	default:
+		return nil, nil, fmt.Errorf("Event storage %s not supported", storage)
+	}
+
+	searchUsecase = eventSearchUsecase.NewEventUsecase(searchRepo, timeout, otherSearchRepos...)
+	eventUsecase = usecase.NewBulkEventUsecase(
+		storeRepo,
+		timeout,
+		configHandler.GetInt("event.queue_size"),
+		configHandler.GetInt("event.batch_size"),
+		time.Duration(configHandler.GetInt("event.flush_interval"))*time.Second,
+		configHandler.GetString("event.overflow"),
+		otherStoreRepos...,
+	)
+	log.Infof("Events are stored on %s", storage)
+
+	return eventUsecase, searchUsecase, nil
+}
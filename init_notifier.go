@@ -0,0 +1,58 @@
+package main
+
+import (
+	"time"
+
+	"github.com/disaster37/gobot-fat/mail"
+	"github.com/disaster37/gobot-fat/mail/smtp"
+	"github.com/disaster37/gobot-fat/notifier"
+	notifierBackend "github.com/disaster37/gobot-fat/notifier/backend"
+	notifierUsecase "github.com/disaster37/gobot-fat/notifier/usecase"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// init notifiers from `notifiers` section
+// When there are no notifiers, it send mail like before with `mail` section
+func initNotifier(configHandler *viper.Viper) (notifierU notifier.Usecase, err error) {
+
+	timeout := time.Duration(configHandler.GetInt("context.timeout")) * time.Second
+
+	var mailClient mail.Mail
+	if configHandler.IsSet("mail.server") {
+		mailClient = smtp.NewSMTPClient(configHandler.GetString("mail.server"), configHandler.GetInt("mail.port"), configHandler.GetString("mail.user"), configHandler.GetString("mail.password"), configHandler.GetString("mail.to"))
+	}
+
+	configs := make([]notifier.Config, 0)
+	if configHandler.IsSet("notifiers") {
+		if err = configHandler.UnmarshalKey("notifiers", &configs); err != nil {
+			return nil, err
+		}
+	} else if mailClient != nil {
+		configs = append(configs, notifier.Config{
+			Name: "mail",
+			Type: notifierBackend.TypeSMTP,
+		})
+	}
+
+	routes := make([]notifier.Route, 0, len(configs))
+	for _, config := range configs {
+		if config.MinSeverity != "" && !notifier.IsValidSeverity(config.MinSeverity) {
+			log.Errorf("Notifier %s has bad min_severity %s", config.Name, config.MinSeverity)
+			continue
+		}
+		n, err := notifierBackend.NewNotifier(config, mailClient, timeout)
+		if err != nil {
+			log.Errorf("Error when init notifier: %s", err.Error())
+			continue
+		}
+		routes = append(routes, notifier.Route{
+			Notifier:    n,
+			Kinds:       config.Kinds,
+			MinSeverity: config.MinSeverity,
+		})
+		log.Infof("Notifier %s (%s) is enabled", n.Name(), config.Type)
+	}
+
+	return notifierUsecase.NewNotifierUsecase(timeout, routes...), nil
+}
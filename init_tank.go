@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
-	"time"
 
-	"github.com/disaster37/gobot-fat/board"
+	"github.com/disaster37/gobot-fat/backup"
+	historyHttpDeliver "github.com/disaster37/gobot-fat/history/delivery/http"
+	historyusecase "github.com/disaster37/gobot-fat/history/usecase"
 	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/registry"
 	"github.com/disaster37/gobot-fat/repository"
 	"github.com/disaster37/gobot-fat/tank"
 	tankboard "github.com/disaster37/gobot-fat/tank/board"
@@ -14,89 +16,111 @@ import (
 	"github.com/disaster37/gobot-fat/tankconfig"
 	tankConfigHttpDeliver "github.com/disaster37/gobot-fat/tankconfig/delivery/http"
 	"github.com/disaster37/gobot-fat/usecase"
-	"github.com/elastic/go-elasticsearch/v8"
-	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
-	"gobot.io/x/gobot/v2"
-	"gorm.io/gorm"
 )
 
-// init tank config and tank board usecase
-func initTank(ctx context.Context, eventer gobot.Eventer, api *echo.Group, configHandler *viper.Viper, elacticConn *elasticsearch.Client, sqlConn *gorm.DB, eventUsecase usecase.UsecaseCRUD, boardUsecase board.Usecase) (err error) {
-
-	timeout := time.Duration(configHandler.GetInt("context.timeout")) * time.Second
+// tankFactory create tank boards
+// The config usecase and the tank usecase are shared by all tanks
+type tankFactory struct {
+	deps              *boardDependencies
+	tankConfigUsecase usecase.UsecaseCRUD
+	tankUsecase       tank.Usecase
+}
 
-	// Init repositories and usecase
-	tankConfigRepoSQL := repository.NewSQLRepository(sqlConn)
-	tankConfigRepoES := repository.NewElasticsearchRepository(elacticConn, configHandler.GetString("elasticsearch.index.tank_config"))
-	tankConfigUsecase := usecase.NewUsecase(tankConfigRepoSQL, tankConfigRepoES, timeout, eventer, tankconfig.NewTankConfig)
-	listTankBoards := make([]tank.Board, 0)
+// newTankFactory init tank config and tank usecases, to be used by other components even without tank
+func newTankFactory(deps *boardDependencies) *tankFactory {
+	tankConfigRepoSQL := repository.NewSQLRepository(deps.sqlConn)
+	tankConfigRepoES := newElasticsearchRepository(deps.elasticConn, deps.configHandler.GetString("elasticsearch.index.tank_config"), deps.outboxUsecase)
+	tankConfigU := historyusecase.NewConfigUsecase(usecase.NewUsecase(tankConfigRepoSQL, tankConfigRepoES, deps.timeout, deps.eventer, tankconfig.NewTankConfig), deps.historyUsecase, "tank-configs")
+	historyHttpDeliver.NewHistoryHandler(deps.api, "tank-configs", tankConfigU, deps.historyUsecase, func() models.Model { return &models.TankConfig{} }, deps.adminMiddleware)
+	deps.backupUsecase.Register(backup.KindTankConfig, tankConfigU)
 
-	// Tank Pond config
-	tankPondConfig := &models.TankConfig{
-		Enable:       true,
-		Name:         configHandler.GetString("tank_pond.name"),
-		Depth:        200,
-		SensorHeight: 20,
-		LiterPerCm:   50,
+	factory := &tankFactory{
+		deps:              deps,
+		tankConfigUsecase: tankConfigU,
 	}
-	tankPondConfig.ID = tankconfig.IDPondTank
-	err = tankConfigUsecase.Init(ctx, tankPondConfig)
-	if err != nil {
-		log.Errorf("Error appear when init Tank Pond config: %s", err.Error())
-		panic("Failed to init tank Pond config on SQL")
+	factory.tankUsecase = tankUsecase.NewTankUsecase(make([]tank.Board, 0), tankConfigU, deps.boardUsecase, factory.newBoard, deps.timeout)
+	tankConfigHttpDeliver.NewTankConfigHandler(deps.api, tankConfigU, factory.tankUsecase, deps.adminMiddleware)
+	tankHttpDeliver.NewTankHandler(deps.api, factory.tankUsecase)
+
+	return factory
+}
+
+// newBoard create the board of tank created over API
+func (h *tankFactory) newBoard(config *models.TankConfig) tank.Board {
+	settings := viper.New()
+	settings.Set("name", config.Name)
+	settings.Set("url", config.URL)
+	settings.Set("fake-board", h.deps.configHandler.GetBool("fake-board"))
+
+	return tankboard.NewTank(settings, config, h.deps.eventUsecase, h.deps.eventer)
+}
+
+// Usecase return the usecase of all tanks
+func (h *tankFactory) Usecase() tank.Usecase {
+	return h.tankUsecase
+}
+
+// ReservedIDs return the IDs of tanks created over API, even deleted to keep their history
+// The tanks declared on config must not use them.
+func (h *tankFactory) ReservedIDs(ctx context.Context) ([]uint, error) {
+	configs := make([]*models.TankConfig, 0)
+	if err := h.tankConfigUsecase.List(ctx, &configs); err != nil {
+		return nil, errors.Wrap(err, "Error when list tank configs")
 	}
-	err = tankConfigUsecase.Get(ctx, tankconfig.IDPondTank, tankPondConfig)
-	if err != nil {
-		log.Errorf("Failed to retrive tankPondconfig from usecase")
-		panic("Failed to retrive tankPondconfig from usecase")
+
+	ids := make([]uint, 0, len(configs))
+	for _, config := range configs {
+		if config.Origin == models.TankOriginAPI {
+			ids = append(ids, config.ID)
+		}
 	}
-	log.Info("Get tankPondconfig successfully")
-
-	// Tank Garden config
-	tankGardenConfig := &models.TankConfig{
-		Enable:       true,
-		Name:         configHandler.GetString("tank_garden.name"),
-		Depth:        120,
-		SensorHeight: 50,
-		LiterPerCm:   30,
+
+	return ids, nil
+}
+
+// defaultTankConfig return the config of new tank
+func defaultTankConfig(instance *registry.Instance) *models.TankConfig {
+	config := tankconfig.NewDefault(instance.Name)
+
+	// Keep the historical garden tank config
+	if instance.ID == tankconfig.IDGardenTank {
+		config.Depth = 120
+		config.SensorHeight = 50
+		config.LiterPerCm = 30
+		config.LowLevelThreshold = 10
+		config.DryRunThreshold = 0
+		config.DryRunRestartThreshold = 0
 	}
-	tankGardenConfig.ID = tankconfig.IDGardenTank
-	err = tankConfigUsecase.Init(ctx, tankGardenConfig)
-	if err != nil {
-		log.Errorf("Error appear when init Tank Garden config: %s", err.Error())
-		panic("Failed to init tank garden config on SQL")
+	config.ID = instance.ID
+	config.Origin = models.TankOriginConfig
+
+	return config
+}
+
+// Create init tank config and board of instance
+func (h *tankFactory) Create(ctx context.Context, instance *registry.Instance) (err error) {
+
+	tankConfig := defaultTankConfig(instance)
+	if err = h.tankConfigUsecase.Init(ctx, tankConfig); err != nil {
+		return errors.Wrap(err, "Error appear when init Tank config")
 	}
-	err = tankConfigUsecase.Get(ctx, tankconfig.IDGardenTank, tankGardenConfig)
-	if err != nil {
-		log.Errorf("Failed to retrive tankGardenconfig from usecase")
-		panic("Failed to retrive tankGardenconfig from usecase")
+	if err = h.tankConfigUsecase.Get(ctx, instance.ID, tankConfig); err != nil {
+		return errors.Wrap(err, "Failed to retrive tank config from usecase")
 	}
-	log.Info("Get tankGardenconfig successfully")
-	tankConfigHttpDeliver.NewTankConfigHandler(api, tankConfigUsecase)
-
-	// Tank pond board
-	if configHandler.GetBool("tank_pond.enable") {
-		tankPondConfigViper := configHandler.Sub("tank_pond")
-		tankPondConfigViper.Set("fake-board", configHandler.GetBool("fake-board"))
-		tankPondBoard := tankboard.NewTank(tankPondConfigViper, tankPondConfig, eventUsecase, eventer)
-		boardUsecase.AddBoard(tankPondBoard)
-		listTankBoards = append(listTankBoards, tankPondBoard)
+	if tankConfig.Origin == models.TankOriginAPI {
+		return errors.Errorf("ID %d is used by tank %s created over API, set other id on config", instance.ID, tankConfig.Name)
 	}
+	log.Infof("Get tank config %s successfully", instance.Name)
 
-	// Tank garden board
-	if configHandler.GetBool("tank_garden.enable") {
-		tankGardenConfigViper := configHandler.Sub("tank_garden")
-		tankGardenConfigViper.Set("fake-board", configHandler.GetBool("fake-board"))
-		tankGardenBoard := tankboard.NewTank(tankGardenConfigViper, tankGardenConfig, eventUsecase, eventer)
-		boardUsecase.AddBoard(tankGardenBoard)
-		listTankBoards = append(listTankBoards, tankGardenBoard)
+	// Tank board
+	if instance.Enable {
+		tankBoard := tankboard.NewTank(instance.Settings, tankConfig, h.deps.eventUsecase, h.deps.eventer)
+		h.deps.boardUsecase.AddBoard(tankBoard)
+		h.tankUsecase.AddBoard(tankBoard)
 	}
 
-	// Board usecase
-	tankU := tankUsecase.NewTankUsecase(listTankBoards, timeout)
-	tankHttpDeliver.NewTankHandler(api, tankU)
-
 	return nil
 }
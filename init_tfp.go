@@ -4,9 +4,14 @@ import (
 	"context"
 	"time"
 
-	"github.com/disaster37/gobot-fat/board"
+	"github.com/disaster37/gobot-fat/backup"
+	historyHttpDeliver "github.com/disaster37/gobot-fat/history/delivery/http"
+	historyusecase "github.com/disaster37/gobot-fat/history/usecase"
 	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/registry"
 	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/tfp"
 	tfpboard "github.com/disaster37/gobot-fat/tfp/board"
 	tfpHttpDeliver "github.com/disaster37/gobot-fat/tfp/delivery/http"
 	tfpusecase "github.com/disaster37/gobot-fat/tfp/usecase"
@@ -15,54 +20,88 @@ import (
 	"github.com/disaster37/gobot-fat/tfpstate"
 	tfpStateHttpDeliver "github.com/disaster37/gobot-fat/tfpstate/delivery/http"
 	"github.com/disaster37/gobot-fat/usecase"
-	"github.com/elastic/go-elasticsearch/v8"
-	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
-	"gobot.io/x/gobot/v2"
-	"gorm.io/gorm"
 )
 
-// init tank config and tank board usecase
-func initTFP(ctx context.Context, eventer gobot.Eventer, api *echo.Group, configHandler *viper.Viper, elacticConn *elasticsearch.Client, sqlConn *gorm.DB, eventUsecase usecase.UsecaseCRUD, boardUsecase board.Usecase) (err error) {
+// tfpFactory create TFP boards
+// The config and state usecases are shared by all TFP
+type tfpFactory struct {
+	deps             *boardDependencies
+	tfpConfigUsecase usecase.UsecaseCRUD
+	tfpStateUsecase  usecase.UsecaseCRUD
+	tankUsecase      tank.Usecase
+	tfpUsecases      map[string]tfp.Usecase
+	nbInstances      int
+}
+
+// The tank usecase let TFP read the dry run state of its pond tank
+func newTFPFactory(deps *boardDependencies, tankUsecase tank.Usecase) *tfpFactory {
+	return &tfpFactory{
+		deps:        deps,
+		tankUsecase: tankUsecase,
+		tfpUsecases: make(map[string]tfp.Usecase),
+	}
+}
 
-	timeout := time.Duration(configHandler.GetInt("context.timeout")) * time.Second
+// Usecases return the usecase of each enabled TFP by board name
+func (h *tfpFactory) Usecases() map[string]tfp.Usecase {
+	return h.tfpUsecases
+}
+
+// init TFP config and state usecases, on first TFP
+func (h *tfpFactory) init() {
+	if h.tfpConfigUsecase != nil {
+		return
+	}
 
 	//TFP config
-	tfpConfigRepoSQL := repository.NewSQLRepository(sqlConn)
-	tfpConfigRepoES := repository.NewElasticsearchRepository(elacticConn, configHandler.GetString("elasticsearch.index.tfp_config"))
-	tfpConfigUsecase := usecase.NewUsecase(tfpConfigRepoSQL, tfpConfigRepoES, timeout, eventer, tfpconfig.NewTFPConfig)
+	tfpConfigRepoSQL := repository.NewSQLRepository(h.deps.sqlConn)
+	tfpConfigRepoES := newElasticsearchRepository(h.deps.elasticConn, h.deps.configHandler.GetString("elasticsearch.index.tfp_config"), h.deps.outboxUsecase)
+	h.tfpConfigUsecase = historyusecase.NewConfigUsecase(usecase.NewUsecase(tfpConfigRepoSQL, tfpConfigRepoES, h.deps.timeout, h.deps.eventer, tfpconfig.NewTFPConfig), h.deps.historyUsecase, "tfp-configs")
+	historyHttpDeliver.NewHistoryHandler(h.deps.api, "tfp-configs", h.tfpConfigUsecase, h.deps.historyUsecase, func() models.Model { return &models.TFPConfig{} })
+	h.deps.backupUsecase.Register(backup.KindTFPConfig, h.tfpConfigUsecase)
+
+	// TFP state
+	tfpStateRepoSQL := repository.NewSQLRepository(h.deps.sqlConn)
+	tfpStateRepoES := newElasticsearchRepository(h.deps.elasticConn, h.deps.configHandler.GetString("elasticsearch.index.tfp_state"), h.deps.outboxUsecase)
+	h.tfpStateUsecase = usecase.NewUsecase(tfpStateRepoSQL, tfpStateRepoES, h.deps.timeout, h.deps.eventer, tfpstate.NewTFPState)
+	h.deps.backupUsecase.Register(backup.KindTFPState, h.tfpStateUsecase)
+}
+
+// Create init TFP config, state and board usecase of instance
+func (h *tfpFactory) Create(ctx context.Context, instance *registry.Instance) (err error) {
+	h.init()
+	groups := h.deps.instanceGroups(instance, h.nbInstances == 0)
+	h.nbInstances++
+
+	// TFP config
 	tfpConfig := &models.TFPConfig{
-		Enable:              true,
-		UVC1BlisterMaxTime:  6000,
-		UVC2BlisterMaxTime:  6000,
-		OzoneBlisterMaxTime: 16000,
-		IsWaterfallAuto:     false,
-		StartTimeWaterfall:  "10:00",
-		StopTimeWaterfall:   "20:00",
-		Mode:                "none",
-		OzoneBlisterTime:    time.Now(),
-		UVC1BlisterTime:     time.Now(),
-		UVC2BlisterTime:     time.Now(),
+		Enable:                 true,
+		UVC1BlisterMaxTime:     6000,
+		UVC2BlisterMaxTime:     6000,
+		OzoneBlisterMaxTime:    16000,
+		IsWaterfallAuto:        false,
+		StartTimeWaterfall:     "10:00",
+		StopTimeWaterfall:      "20:00",
+		Mode:                   "none",
+		BacteriumDuration:      tfpboard.DefaultBacteriumDuration,
+		BlisterAlertThresholds: "90,100",
+		IsBlisterAutoCutoff:    false,
+		OzoneBlisterTime:       time.Now(),
+		UVC1BlisterTime:        time.Now(),
+		UVC2BlisterTime:        time.Now(),
 	}
-	tfpConfig.ID = tfpconfig.ID
-	err = tfpConfigUsecase.Init(ctx, tfpConfig)
-	if err != nil {
-		log.Errorf("Error appear when init TFP config: %s", err.Error())
-		panic("Failed to init tfpconfig on SQL")
+	tfpConfig.ID = instance.ID
+	if err = h.tfpConfigUsecase.Init(ctx, tfpConfig); err != nil {
+		return errors.Wrap(err, "Error appear when init TFP config")
 	}
-	err = tfpConfigUsecase.Get(ctx, tfpconfig.ID, tfpConfig)
-	if err != nil {
-		log.Errorf("Failed to retrive tfpconfig from usecase")
-		panic("Failed to retrive tfpconfig from usecase")
+	if err = h.tfpConfigUsecase.Get(ctx, instance.ID, tfpConfig); err != nil {
+		return errors.Wrap(err, "Failed to retrive tfpconfig from usecase")
 	}
-	log.Info("Get tfpconfig successfully")
-	tfpConfigHttpDeliver.NewTFPConfigHandler(api, tfpConfigUsecase)
+	log.Infof("Get tfpconfig %d successfully", instance.ID)
 
 	// TFP state
-	tfpStateRepoSQL := repository.NewSQLRepository(sqlConn)
-	tfpStateRepoES := repository.NewElasticsearchRepository(elacticConn, configHandler.GetString("elasticsearch.index.tfp_state"))
-	tfpStateUsecase := usecase.NewUsecase(tfpStateRepoSQL, tfpStateRepoES, timeout, eventer, tfpstate.NewTFPState)
 	tfpState := &models.TFPState{
 		PondPumpRunning:         true,
 		UVC1Running:             true,
@@ -77,30 +116,31 @@ func initTFP(ctx context.Context, eventer gobot.Eventer, api *echo.Group, config
 		UVC1BlisterNbHour:       0,
 		UVC2BlisterNbHour:       0,
 		AcknoledgeWaterfallAuto: false,
-		Name:                    configHandler.GetString("tfp.name"),
+		Name:                    instance.Name,
+	}
+	tfpState.ID = instance.ID
+	if err = h.tfpStateUsecase.Init(ctx, tfpState); err != nil {
+		return errors.Wrap(err, "Error appear when init TFP state")
 	}
-	tfpState.ID = tfpstate.ID
-	err = tfpStateUsecase.Init(ctx, tfpState)
-	if err != nil {
-		log.Errorf("Error appear when init TFP state: %s", err.Error())
-		panic("Failed to init tfpState on SQL")
+	if err = h.tfpStateUsecase.Get(ctx, instance.ID, tfpState); err != nil {
+		return errors.Wrap(err, "Failed to retrive tfpState from usecase")
 	}
-	err = tfpStateUsecase.Get(ctx, tfpstate.ID, tfpState)
-	if err != nil {
-		log.Errorf("Failed to retrive tfpState from usecase")
-		panic("Failed to retrive tfpState from usecase")
+	log.Infof("Get tfpState %d successfully", instance.ID)
+
+	for _, group := range groups {
+		tfpConfigHttpDeliver.NewTFPConfigHandler(group, h.tfpConfigUsecase, instance.ID)
+		tfpStateHttpDeliver.NewTFPStateHandler(group, h.tfpStateUsecase, instance.ID)
 	}
-	log.Info("Get tfpState successfully")
-	tfpStateHttpDeliver.NewTFPStateHandler(api, tfpStateUsecase)
 
 	// TFP board
-	if configHandler.GetBool("tfp.enable") {
-		tfpConfigViper := configHandler.Sub("tfp")
-		tfpConfigViper.Set("fake-board", configHandler.GetBool("fake-board"))
-		tfpBoard := tfpboard.NewTFP(tfpConfigViper, tfpConfig, tfpState, eventUsecase, tfpStateUsecase, eventer)
-		boardUsecase.AddBoard(tfpBoard)
-		tfpUsecase := tfpusecase.NewTFPUsecase(tfpBoard, tfpConfigUsecase, tfpStateUsecase, timeout)
-		tfpHttpDeliver.NewTFPHandler(api, tfpUsecase)
+	if instance.Enable {
+		tfpBoard := tfpboard.NewTFP(instance.Settings, tfpConfig, tfpState, h.deps.eventUsecase, h.tfpStateUsecase, h.tankUsecase, h.deps.eventer)
+		h.deps.boardUsecase.AddBoard(tfpBoard)
+		tfpUsecase := tfpusecase.NewTFPUsecase(tfpBoard, h.tfpConfigUsecase, h.tfpStateUsecase, h.deps.timeout)
+		for _, group := range groups {
+			tfpHttpDeliver.NewTFPHandler(group, tfpUsecase)
+		}
+		h.tfpUsecases[instance.Name] = tfpUsecase
 	}
 
 	return nil
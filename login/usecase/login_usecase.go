@@ -5,13 +5,17 @@ import (
 	"time"
 
 	"github.com/disaster37/gobot-fat/login"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/user"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
 type loginUsecase struct {
 	configHandler *viper.Viper
+	userUsecase   user.Usecase
 }
 
 // JwtCustomClaims are custom claims extending default ones.
@@ -19,29 +23,45 @@ type loginUsecase struct {
 type JwtCustomClaims struct {
 	Name  string `json:"name"`
 	Admin bool   `json:"admin"`
+	Role  string `json:"role"`
 	jwt.RegisteredClaims
 }
 
+// GetRole return the user role
+// Tokens issued before roles only have admin flag
+func (h *JwtCustomClaims) GetRole() string {
+	if h.Role == "" && h.Admin {
+		return models.RoleAdmin
+	}
+	return h.Role
+}
+
 // NewLoginUsecase will create new loginUsecase object of login.Usecase interface
-func NewLoginUsecase(configHandler *viper.Viper) login.Usecase {
+func NewLoginUsecase(configHandler *viper.Viper, userUsecase user.Usecase) login.Usecase {
 	return &loginUsecase{
 		configHandler: configHandler,
+		userUsecase:   userUsecase,
 	}
 }
 
-func (h *loginUsecase) Login(c context.Context, user string, password string) (string, error) {
+func (h *loginUsecase) Login(c context.Context, username string, password string) (string, error) {
 
-	log.Debugf("Login: %s", user)
+	log.Debugf("Login: %s", username)
 	log.Debugf("Password: XXX")
 
-	if h.configHandler.GetString("jwt.user") != user || h.configHandler.GetString("jwt.password") != password {
-		return "", nil
+	u, err := h.userUsecase.Authenticate(c, username, password)
+	if err != nil {
+		if errors.Is(err, user.ErrBadCredentials) {
+			return "", nil
+		}
+		return "", err
 	}
 
 	// Set custom claims
 	claims := &JwtCustomClaims{
-		user,
-		true,
+		u.Username,
+		u.Role == models.RoleAdmin,
+		u.Role,
 		jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 72)),
 		},
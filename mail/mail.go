@@ -1,5 +1,6 @@
 package mail
 
 type Mail interface {
-	SendEmail(title string, contend string)
+	// SendEmail send email and return error if it can't be sent
+	SendEmail(title string, contend string) error
 }
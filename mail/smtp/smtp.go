@@ -23,18 +23,19 @@ func NewSMTPClient(server string, port int, user string, password string, to str
 }
 
 // SendEmail permit to send email
-// I run on goroutine
-func (h *SMTPClient) SendEmail(title string, contend string) {
-
-	go func() {
-		m := gomail.NewMessage()
-		m.SetHeader("From", h.from)
-		m.SetHeader("To", h.to)
-		m.SetHeader("Subject", title)
-		m.SetBody("text/html", contend)
-
-		if err := h.client.DialAndSend(m); err != nil {
-			log.Errorf("Error appear when sen email: %s", err.Error())
-		}
-	}()
+// It wait the email is sent, so it must be called on goroutine to not block
+func (h *SMTPClient) SendEmail(title string, contend string) error {
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", h.from)
+	m.SetHeader("To", h.to)
+	m.SetHeader("Subject", title)
+	m.SetBody("text/html", contend)
+
+	if err := h.client.DialAndSend(m); err != nil {
+		log.Errorf("Error appear when sen email: %s", err.Error())
+		return err
+	}
+
+	return nil
 }
@@ -2,51 +2,70 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
-	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	backupHttpDeliver "github.com/disaster37/gobot-fat/backup/delivery/http"
+	backupUsecase "github.com/disaster37/gobot-fat/backup/usecase"
 	boardHttpDeliver "github.com/disaster37/gobot-fat/board/delivery/http"
 	boardUsecase "github.com/disaster37/gobot-fat/board/usecase"
 	"github.com/disaster37/gobot-fat/dfpconfig"
 	"github.com/disaster37/gobot-fat/dfpstate"
+	eventHttpDeliver "github.com/disaster37/gobot-fat/event/delivery/http"
+	eventSearchUsecase "github.com/disaster37/gobot-fat/event/usecase"
+	healthHttpDeliver "github.com/disaster37/gobot-fat/health/delivery/http"
+	healthUsecase "github.com/disaster37/gobot-fat/health/usecase"
 	"github.com/disaster37/gobot-fat/helper"
+	historyUsecase "github.com/disaster37/gobot-fat/history/usecase"
 	loginHttpDeliver "github.com/disaster37/gobot-fat/login/delivery/http"
 	loginUsecase "github.com/disaster37/gobot-fat/login/usecase"
-	"github.com/disaster37/gobot-fat/mail/smtp"
+	"github.com/disaster37/gobot-fat/metrics"
+	metricsHttpDeliver "github.com/disaster37/gobot-fat/metrics/delivery/http"
 	dfpMiddleware "github.com/disaster37/gobot-fat/middleware"
 	"github.com/disaster37/gobot-fat/models"
+	mqttClient "github.com/disaster37/gobot-fat/mqtt/client"
+	mqttUsecase "github.com/disaster37/gobot-fat/mqtt/usecase"
+	notifierUsecase "github.com/disaster37/gobot-fat/notifier/usecase"
+	outboxHttpDeliver "github.com/disaster37/gobot-fat/outbox/delivery/http"
+	outboxUsecase "github.com/disaster37/gobot-fat/outbox/usecase"
 	"github.com/disaster37/gobot-fat/repository"
 	"github.com/disaster37/gobot-fat/tankconfig"
 	"github.com/disaster37/gobot-fat/tfpconfig"
 	"github.com/disaster37/gobot-fat/tfpstate"
-	"github.com/disaster37/gobot-fat/usecase"
-	elastic "github.com/elastic/go-elasticsearch/v8"
+	userHttpDeliver "github.com/disaster37/gobot-fat/user/delivery/http"
+	userUsecase "github.com/disaster37/gobot-fat/user/usecase"
+	websocketHttpDeliver "github.com/disaster37/gobot-fat/websocket/delivery/http"
+	websocketUsecase "github.com/disaster37/gobot-fat/websocket/usecase"
 	"github.com/golang-jwt/jwt/v5"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"gobot.io/x/gobot/v2"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
+// defaultShutdownTimeout is the maximum time to wait HTTP requests on shutdown
+const defaultShutdownTimeout = 30 * time.Second
+
 func main() {
 
 	// Logger setting
 	formatter := new(log.JSONFormatter)
 	/*
-	formatter.FullTimestamp = true
-	formatter.ForceFormatting = true
+		formatter.FullTimestamp = true
+		formatter.ForceFormatting = true
 	*/
 	log.SetFormatter(formatter)
 	log.SetReportCaller(true)
 	log.SetOutput(os.Stdout)
 	log.SetLevel(log.InfoLevel)
+	defer log.Info("End of program")
 
 	// Read config file
 	configHandler := viper.New()
@@ -64,35 +83,19 @@ func main() {
 	log.SetLevel(level)
 
 	// Init backend connexion
-	isConnected := false
-	var db *gorm.DB
-	for !isConnected {
-		dsn := fmt.Sprintf("host=%s port=5432 user=%s dbname=%s password=%s sslmode=disable", configHandler.GetString("db.host"), configHandler.GetString("db.user"), configHandler.GetString("db.name"), configHandler.GetString("db.password"))
-		log.Debug(dsn)
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-		if err != nil {
-			log.Errorf("failed to connect on postgresql: %s", err.Error())
-			time.Sleep(10 * time.Second)
-		} else {
-			isConnected = true
-		}
+	db, err := initDB(configHandler)
+	if err != nil {
+		log.Errorf("failed to connect on DB: %s", err.Error())
+		panic("failed to connect on DB")
 	}
 	sqlDB, err := db.DB()
 	if err != nil {
-		log.Errorf("failed to connect on Postgres DB: %s", err.Error())
-		panic("failed to connect on Postgres DB")
+		log.Errorf("failed to connect on DB: %s", err.Error())
+		panic("failed to connect on DB")
 	}
 	defer func() { _ = sqlDB.Close() }()
 
-	cfg := elastic.Config{
-		Addresses: configHandler.GetStringSlice("elasticsearch.urls"),
-		Username:  configHandler.GetString("elasticsearch.username"),
-		Password:  configHandler.GetString("elasticsearch.password"),
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}
-	es, err := elastic.NewClient(cfg)
+	es, err := initElasticsearch(configHandler)
 	if err != nil {
 		log.Errorf("failed to connect on elasticsearch: %s", err.Error())
 		panic("failed to connect on elasticsearch")
@@ -114,12 +117,40 @@ func main() {
 	if err = db.AutoMigrate(&models.TankConfig{}); err != nil {
 		log.Errorf("Failed to auto migrate schema 'tankconfig': %s", err.Error())
 	}
+	// Tanks saved before origin field was added are created over API when they have URL
+	if err = db.Model(&models.TankConfig{}).Where("origin IS NULL OR origin = ''").Where("url <> ''").Update("origin", models.TankOriginAPI).Error; err != nil {
+		log.Errorf("Failed to migrate origin of 'tankconfig': %s", err.Error())
+	}
+	if err = db.Model(&models.TankConfig{}).Where("origin IS NULL OR origin = ''").Update("origin", models.TankOriginConfig).Error; err != nil {
+		log.Errorf("Failed to migrate origin of 'tankconfig': %s", err.Error())
+	}
+	if err = db.AutoMigrate(&models.User{}); err != nil {
+		log.Errorf("Failed to auto migrate schema 'users': %s", err.Error())
+	}
+	if err = db.AutoMigrate(&models.OutboxEntry{}); err != nil {
+		log.Errorf("Failed to auto migrate schema 'outbox': %s", err.Error())
+	}
+	if err = db.AutoMigrate(&models.ConfigRevision{}); err != nil {
+		log.Errorf("Failed to auto migrate schema 'config_revisions': %s", err.Error())
+	}
+	if err = db.AutoMigrate(&models.BoardDiagnostic{}); err != nil {
+		log.Errorf("Failed to auto migrate schema 'board_diagnostics': %s", err.Error())
+	}
+
+	// Init users, they are read on each request to apply deleted users and role changes before token expire
+	timeoutContext := time.Duration(configHandler.GetInt("context.timeout")) * time.Second
+	userU := userUsecase.NewUserUsecase(repository.NewSQLRepository(db), timeoutContext)
+	if err = userU.Init(context.Background(), configHandler.GetString("jwt.user"), configHandler.GetString("jwt.password")); err != nil {
+		log.Errorf("Failed to init users: %s", err.Error())
+		panic("Failed to init users")
+	}
 
 	// Init web server
 	e := echo.New()
-	middL := dfpMiddleware.InitMiddleware()
+	middL := dfpMiddleware.InitMiddleware(userU, configHandler.GetStringSlice("server.allow_origins")...)
 	e.Use(middL.CORS)
 	if configHandler.GetBool("log.access") {
+		e.Use(middL.RedactToken)
 		e.Use(middleware.Logger())
 	}
 	e.Use(middleware.Recover())
@@ -129,23 +160,65 @@ func main() {
 			return new(loginUsecase.JwtCustomClaims)
 		},
 		SigningKey: []byte(configHandler.GetString("jwt.secret")),
+		// Browsers can't set header on websocket, so token can be provided on query string
+		TokenLookup: "header:Authorization:Bearer ,query:token",
 	}))
-	api.Use(middL.IsAdmin)
 
 	// Init global resources
-	timeoutContext := time.Duration(configHandler.GetInt("context.timeout")) * time.Second
-	eventRepoES := repository.NewElasticsearchRepository(es, configHandler.GetString("elasticsearch.index.event"), false)
-	eventUsecase := usecase.NewEventUsecase(eventRepoES, timeoutContext)
-	loginU := loginUsecase.NewLoginUsecase(configHandler)
+	outboxU := outboxUsecase.NewOutboxUsecase(
+		repository.NewSQLRepository(db),
+		time.Duration(configHandler.GetInt("outbox.interval"))*time.Second,
+		time.Duration(configHandler.GetInt("outbox.min_backoff"))*time.Second,
+		time.Duration(configHandler.GetInt("outbox.max_backoff"))*time.Second,
+		timeoutContext,
+	)
+	notifierU, err := initNotifier(configHandler)
+	if err != nil {
+		log.Errorf("Failed to init notifiers: %s", err.Error())
+		panic("Failed to init notifiers")
+	}
+	// Events are stored on background by batch, to not slow down boards
+	eventBulkUsecase, eventSearchU, err := initEvent(configHandler, es, db, outboxU)
+	if err != nil {
+		log.Errorf("Failed to init events: %s", err.Error())
+		panic("Failed to init events")
+	}
+	eventBulkUsecase.Start(context.Background())
+	defer eventBulkUsecase.Stop(context.Background())
+	eventUsecase := notifierUsecase.NewEventUsecase(eventBulkUsecase, notifierU)
+	api.Use(middL.Audit(eventUsecase))
+	api.Use(middL.IsAuthorized)
+	outboxHttpDeliver.NewOutboxHandler(api, outboxU)
 	ctx := context.Background()
 	eventer := gobot.NewEventer()
 	eventer.AddEvent(helper.SetEmergencyStop)
 	eventer.AddEvent(helper.UnsetEmergencyStop)
 	eventer.AddEvent(helper.SetSecurity)
 	eventer.AddEvent(helper.UnsetSecurity)
-	mailClient := smtp.NewSMTPClient(configHandler.GetString("mail.server"), configHandler.GetInt("mail.port"), configHandler.GetString("mail.user"), configHandler.GetString("mail.password"), configHandler.GetString("mail.to"))
+	eventer.AddEvent(helper.SetTankLowLevel)
+	eventer.AddEvent(helper.UnsetTankLowLevel)
+	eventer.AddEvent(helper.SetTankHighLevel)
+	eventer.AddEvent(helper.UnsetTankHighLevel)
+	eventer.AddEvent(helper.SetDryRun)
+	eventer.AddEvent(helper.UnsetDryRun)
+
+	/***********************
+	 * Users
+	 */
+	userHttpDeliver.NewUserHandler(api, userU, middL.IsAdmin)
+	loginU := loginUsecase.NewLoginUsecase(configHandler, userU)
 	loginHttpDeliver.NewLoginHandler(e, loginU)
 
+	/***********************
+	 * Events history
+	 */
+	eventHttpDeliver.NewEventHandler(api, eventSearchU)
+	if retention := configHandler.GetInt("event.retention_days"); retention > 0 {
+		eventRetention := eventSearchUsecase.NewRetention(eventSearchU, time.Duration(retention)*24*time.Hour, 1*time.Hour)
+		eventRetention.Start(ctx)
+		defer eventRetention.Stop(ctx)
+	}
+
 	// Init global events
 	eventer.AddEvent(dfpconfig.NewDFPConfig)
 	eventer.AddEvent(dfpstate.NewDFPState)
@@ -153,42 +226,135 @@ func main() {
 	eventer.AddEvent(tfpstate.NewTFPState)
 	eventer.AddEvent(tankconfig.NewTankConfig)
 
+	// Config history
+	historyU := historyUsecase.NewHistoryUsecase(repository.NewSQLRepository(db).(repository.SearchRepository), timeoutContext)
+
+	// Backup and restore, configs and states usecases are registered on init
+	backupU := backupUsecase.NewBackupUsecase(eventSearchU, backupUsecase.Options{
+		Path:       configHandler.GetString("backup.path"),
+		Interval:   time.Duration(configHandler.GetInt("backup.interval")) * time.Hour,
+		Keep:       configHandler.GetInt("backup.keep"),
+		EventsDays: configHandler.GetInt("backup.events_days"),
+	})
+	backupHttpDeliver.NewBackupHandler(api, backupU, middL.IsAdmin)
+
 	/***********************
 	 * Board
 	 */
-	boardU := boardUsecase.NewBoardUsecase()
-	boardHttpDeliver.NewBoardHandler(api, boardU)
+	boardU := boardUsecase.NewBoardUsecase(repository.NewSQLRepository(db).(repository.SearchRepository), timeoutContext)
+	boardHttpDeliver.NewBoardHandler(api, boardU, middL.IsAdmin)
 
 	/***********************
-	 * INIT TFP
+	 * Boards declared on config
 	 */
-	if err := initTFP(ctx, eventer, api, configHandler, es, db, eventUsecase, boardU); err != nil {
+	dfpUs, tfpUs, tankU, err := initBoards(ctx, &boardDependencies{
+		eventer:         eventer,
+		api:             api,
+		configHandler:   configHandler,
+		elasticConn:     es,
+		sqlConn:         db,
+		eventUsecase:    eventUsecase,
+		boardUsecase:    boardU,
+		outboxUsecase:   outboxU,
+		historyUsecase:  historyU,
+		backupUsecase:   backupU,
+		timeout:         timeoutContext,
+		adminMiddleware: middL.IsAdmin,
+	})
+	if err != nil {
 		panic(err)
 	}
 
-	/***********************
-	 * Tank
-	 */
-	if err := initTank(ctx, eventer, api, configHandler, es, db, eventUsecase, boardU); err != nil {
-		panic(err)
+	// Retry Elasticsearch writes that failed
+	defer outboxU.Stop(ctx)
+	if err = outboxU.Start(ctx); err != nil {
+		log.Errorf("Error when start outbox: %s", err.Error())
 	}
 
-	/*****************************
-	 * INIT DFP
-	 */
-	if err := initDFP(ctx, eventer, api, configHandler, es, db, eventUsecase, boardU, mailClient); err != nil {
-		panic(err)
+	// Write local backups
+	if configHandler.GetInt("backup.interval") > 0 {
+		defer backupU.Stop(ctx)
+		if err = backupU.Start(ctx); err != nil {
+			log.Errorf("Error when start backup: %s", err.Error())
+		}
 	}
 
 	// Starts boards
 	defer boardU.Stops(ctx)
 	boardU.Starts(ctx)
 
-	// Run web server
-	if err = e.Start(configHandler.GetString("server.address")); err != nil {
+	/*****************************
+	 * Websocket
+	 */
+	websocketU := websocketUsecase.NewWebsocketUsecase(boardU, eventer, 1*time.Second)
+	websocketHttpDeliver.NewWebsocketHandler(api, websocketU, middL.IsAllowedOrigin)
+	defer websocketU.Stop(ctx)
+	websocketU.Start(ctx)
+
+	/*****************************
+	 * MQTT
+	 */
+	if configHandler.GetBool("mqtt.enable") {
+		mqttPrefix := configHandler.GetString("mqtt.topic_prefix")
+		client := mqttClient.NewPahoClient(
+			configHandler.GetString("mqtt.url"),
+			configHandler.GetString("mqtt.client_id"),
+			configHandler.GetString("mqtt.username"),
+			configHandler.GetString("mqtt.password"),
+			fmt.Sprintf("%s/status", mqttPrefix),
+			timeoutContext,
+		)
+		mqttU := mqttUsecase.NewMQTTUsecase(client, dfpUs, tfpUs, tankU, mqttPrefix, configHandler.GetString("mqtt.discovery_prefix"), time.Duration(configHandler.GetInt("mqtt.interval"))*time.Second, timeoutContext)
+		defer mqttU.Stop(ctx)
+		mqttU.StartOnBackground(ctx)
+	}
+
+	/*****************************
+	 * Health
+	 */
+	healthU := healthUsecase.NewHealthUsecase(timeoutContext)
+	healthU.AddChecker(healthUsecase.NewSQLChecker(sqlDB, db.Dialector.Name()))
+	if es != nil {
+		healthU.AddChecker(healthUsecase.NewElasticsearchChecker(es))
+	}
+	if configHandler.IsSet("mail.server") {
+		healthU.AddChecker(healthUsecase.NewSMTPChecker(configHandler.GetString("mail.server"), configHandler.GetInt("mail.port")))
+	}
+	healthU.AddChecker(healthUsecase.NewBoardChecker(boardU))
+	healthU.AddChecker(healthUsecase.NewEventChecker(eventBulkUsecase, outboxU))
+	healthHttpDeliver.NewHealthHandler(e, api, healthU)
+
+	/*****************************
+	 * Metrics
+	 */
+	registry := prometheus.NewRegistry()
+	if err = metrics.Register(registry, collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}), metrics.NewCollector(boardU, tankU, timeoutContext)); err != nil {
 		panic(err)
 	}
+	metricsHttpDeliver.NewMetricsHandler(e, registry)
 
-	log.Info("End of program")
+	// Run web server until SIGINT or SIGTERM
+	chServerErr := make(chan error, 1)
+	go func() {
+		chServerErr <- e.Start(configHandler.GetString("server.address"))
+	}()
+	chSignal := make(chan os.Signal, 1)
+	signal.Notify(chSignal, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err = <-chServerErr:
+		panic(err)
+	case sig := <-chSignal:
+		log.Infof("Receive signal %s, shutdown", sig)
+	}
 
+	// Drain HTTP requests, then deferred functions stop MQTT, websocket and boards, flush events and close database
+	shutdownTimeout := time.Duration(configHandler.GetInt("server.shutdown_timeout")) * time.Second
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err = e.Shutdown(shutdownCtx); err != nil {
+		log.Errorf("Error when shutdown web server: %s", err.Error())
+	}
 }
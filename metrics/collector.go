@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+	"github.com/disaster37/gobot-fat/dfp"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/tfp"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	boardOnlineDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "board", "online"),
+		"1 if board is online",
+		[]string{"board"}, nil,
+	)
+	relayStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "board", "relay_state"),
+		"1 if relay is on",
+		[]string{"board", "relay"}, nil,
+	)
+	tankLevelDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "tank", "level_centimeters"),
+		"Level of water in tank",
+		[]string{"tank"}, nil,
+	)
+	tankVolumeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "tank", "volume_liters"),
+		"Volume of water in tank",
+		[]string{"tank"}, nil,
+	)
+	tankPercentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "tank", "percent"),
+		"Ratio of water in tank",
+		[]string{"tank"}, nil,
+	)
+	waterTemperatureDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "dfp", "water_temperature_celsius"),
+		"Water temperature",
+		[]string{"board"}, nil,
+	)
+	ambientTemperatureDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "dfp", "ambient_temperature_celsius"),
+		"Ambient temperature",
+		[]string{"board"}, nil,
+	)
+	lastWashDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "dfp", "last_wash_timestamp_seconds"),
+		"Time of the last washing cycle",
+		[]string{"board"}, nil,
+	)
+	washingDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "dfp", "washing_duration_config_seconds"),
+		"Configured washing duration",
+		[]string{"board"}, nil,
+	)
+	blisterHoursDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "tfp", "blister_hours"),
+		"Blister usage in hour",
+		[]string{"board", "blister"}, nil,
+	)
+)
+
+// Collector read the current boards and tanks values when Prometheus scrape
+type Collector struct {
+	boardUsecase board.Usecase
+	tankUsecase  tank.Usecase
+	timeout      time.Duration
+}
+
+// NewCollector create new collector
+// tankUsecase can be nil
+func NewCollector(boardUsecase board.Usecase, tankUsecase tank.Usecase, timeout time.Duration) *Collector {
+	return &Collector{
+		boardUsecase: boardUsecase,
+		tankUsecase:  tankUsecase,
+		timeout:      timeout,
+	}
+}
+
+// Describe send the metrics descriptions
+func (h *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- boardOnlineDesc
+	ch <- relayStateDesc
+	ch <- tankLevelDesc
+	ch <- tankVolumeDesc
+	ch <- tankPercentDesc
+	ch <- waterTemperatureDesc
+	ch <- ambientTemperatureDesc
+	ch <- lastWashDesc
+	ch <- washingDurationDesc
+	ch <- blisterHoursDesc
+}
+
+// Collect send the current metrics values
+func (h *Collector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	// Boards status
+	boards, err := h.boardUsecase.GetBoards(ctx)
+	if err != nil {
+		log.Errorf("Error when get boards for metrics: %s", err.Error())
+	}
+	for _, b := range boards {
+		ch <- prometheus.MustNewConstMetric(boardOnlineDesc, prometheus.GaugeValue, boolToFloat(b.IsOnline), b.Name)
+	}
+
+	// Relays, temperatures and counters
+	for _, b := range h.boardUsecase.Boards() {
+		switch currentBoard := b.(type) {
+		case dfp.Board:
+			io := currentBoard.IO()
+			state := currentBoard.State()
+			config := currentBoard.Config()
+			relays := map[string]bool{
+				"drum":      io.DrumRelay,
+				"pump":      io.PumpRelay,
+				"green_led": io.GreenLed,
+				"red_led":   io.RedLed,
+			}
+			for relay, value := range relays {
+				ch <- prometheus.MustNewConstMetric(relayStateDesc, prometheus.GaugeValue, boolToFloat(value), b.Name(), relay)
+			}
+			ch <- prometheus.MustNewConstMetric(waterTemperatureDesc, prometheus.GaugeValue, state.WaterTemperature, b.Name())
+			ch <- prometheus.MustNewConstMetric(ambientTemperatureDesc, prometheus.GaugeValue, state.AmbientTemperature, b.Name())
+			if !state.LastWashing.IsZero() {
+				ch <- prometheus.MustNewConstMetric(lastWashDesc, prometheus.GaugeValue, float64(state.LastWashing.Unix()), b.Name())
+			}
+			ch <- prometheus.MustNewConstMetric(washingDurationDesc, prometheus.GaugeValue, float64(config.WashingDuration), b.Name())
+		case tfp.Board:
+			io := currentBoard.IO()
+			state := currentBoard.State()
+			relays := map[string]bool{
+				"pond_pump":      io.PondPumpRelay,
+				"waterfall_pump": io.WaterfallPumpRelay,
+				"uvc1":           io.UVC1Relay,
+				"uvc2":           io.UVC2Relay,
+				"pond_bubble":    io.PondBubble,
+				"filter_bubble":  io.FilterBubble,
+			}
+			for relay, value := range relays {
+				ch <- prometheus.MustNewConstMetric(relayStateDesc, prometheus.GaugeValue, boolToFloat(value), b.Name(), relay)
+			}
+			blisters := map[string]int64{
+				"uvc1":  state.UVC1BlisterNbHour,
+				"uvc2":  state.UVC2BlisterNbHour,
+				"ozone": state.OzoneBlisterNbHour,
+			}
+			for blister, value := range blisters {
+				ch <- prometheus.MustNewConstMetric(blisterHoursDesc, prometheus.GaugeValue, float64(value), b.Name(), blister)
+			}
+		}
+	}
+
+	// Tanks
+	if h.tankUsecase == nil {
+		return
+	}
+	tanks, err := h.tankUsecase.Tanks(ctx)
+	if err != nil {
+		log.Errorf("Error when get tanks for metrics: %s", err.Error())
+		return
+	}
+	for name, t := range tanks {
+		if t == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(tankLevelDesc, prometheus.GaugeValue, float64(t.Level), name)
+		ch <- prometheus.MustNewConstMetric(tankVolumeDesc, prometheus.GaugeValue, float64(t.Volume), name)
+		ch <- prometheus.MustNewConstMetric(tankPercentDesc, prometheus.GaugeValue, t.Percent, name)
+	}
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	boardUsecase "github.com/disaster37/gobot-fat/board/usecase"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/tfp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+type tfpBoard = tfp.Board
+
+type fakeTFPBoard struct {
+	tfpBoard
+}
+
+func (h *fakeTFPBoard) Name() string { return "tfp" }
+func (h *fakeTFPBoard) Board() *models.Board {
+	return &models.Board{Name: "tfp", IsOnline: true}
+}
+func (h *fakeTFPBoard) IO() models.TFPIO {
+	return models.TFPIO{UVC1Relay: true}
+}
+func (h *fakeTFPBoard) State() models.TFPState {
+	return models.TFPState{UVC1BlisterNbHour: 100, OzoneBlisterNbHour: 10}
+}
+
+type fakeTankUsecase struct{}
+
+func (h *fakeTankUsecase) Tanks(ctx context.Context) (map[string]*models.Tank, error) {
+	return map[string]*models.Tank{
+		"pond": {ID: "pond", Level: 150, Volume: 7500, Percent: 75},
+	}, nil
+}
+func (h *fakeTankUsecase) Tank(ctx context.Context, name string) (*models.Tank, error) {
+	return nil, nil
+}
+func (h *fakeTankUsecase) AddBoard(board tank.Board) {}
+func (h *fakeTankUsecase) Create(ctx context.Context, config *models.TankConfig) error {
+	return nil
+}
+func (h *fakeTankUsecase) Delete(ctx context.Context, id uint) error { return nil }
+func (h *fakeTankUsecase) Load(ctx context.Context) error            { return nil }
+
+func findMetric(families []*dto.MetricFamily, name string, labels map[string]string) *dto.Metric {
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	METRIC:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if value, ok := labels[label.GetName()]; ok && value != label.GetValue() {
+					continue METRIC
+				}
+			}
+			return metric
+		}
+	}
+
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	boardU := boardUsecase.NewBoardUsecase(nil, 10*time.Second)
+	boardU.AddBoard(&fakeTFPBoard{})
+
+	registry := prometheus.NewRegistry()
+	err := Register(registry, NewCollector(boardU, &fakeTankUsecase{}, 10*time.Second))
+	assert.NoError(t, err)
+
+	RepositoryFailures.WithLabelValues("elasticsearch", "update").Inc()
+	EventFailures.Inc()
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	metric := findMetric(families, "gobot_fat_board_online", map[string]string{"board": "tfp"})
+	if assert.NotNil(t, metric) {
+		assert.Equal(t, float64(1), metric.GetGauge().GetValue())
+	}
+
+	metric = findMetric(families, "gobot_fat_board_relay_state", map[string]string{"board": "tfp", "relay": "uvc1"})
+	if assert.NotNil(t, metric) {
+		assert.Equal(t, float64(1), metric.GetGauge().GetValue())
+	}
+	metric = findMetric(families, "gobot_fat_board_relay_state", map[string]string{"board": "tfp", "relay": "uvc2"})
+	if assert.NotNil(t, metric) {
+		assert.Equal(t, float64(0), metric.GetGauge().GetValue())
+	}
+
+	metric = findMetric(families, "gobot_fat_tfp_blister_hours", map[string]string{"board": "tfp", "blister": "uvc1"})
+	if assert.NotNil(t, metric) {
+		assert.Equal(t, float64(100), metric.GetGauge().GetValue())
+	}
+
+	metric = findMetric(families, "gobot_fat_tank_volume_liters", map[string]string{"tank": "pond"})
+	if assert.NotNil(t, metric) {
+		assert.Equal(t, float64(7500), metric.GetGauge().GetValue())
+	}
+	metric = findMetric(families, "gobot_fat_tank_percent", map[string]string{"tank": "pond"})
+	if assert.NotNil(t, metric) {
+		assert.Equal(t, float64(75), metric.GetGauge().GetValue())
+	}
+
+	metric = findMetric(families, "gobot_fat_repository_failures_total", map[string]string{"backend": "elasticsearch", "operation": "update"})
+	if assert.NotNil(t, metric) {
+		assert.Equal(t, float64(1), metric.GetCounter().GetValue())
+	}
+	metric = findMetric(families, "gobot_fat_event_failures_total", nil)
+	if assert.NotNil(t, metric) {
+		assert.Equal(t, float64(1), metric.GetCounter().GetValue())
+	}
+}
@@ -0,0 +1,12 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewMetricsHandler will initialize the metrics endpoint for Prometheus
+func NewMetricsHandler(e *echo.Echo, gatherer prometheus.Gatherer) {
+	e.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})))
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace is the prefix of all metrics
+const Namespace = "gobot_fat"
+
+var (
+	// RepositoryFailures count the write failures per backend (sql / elasticsearch) and operation (create / update)
+	RepositoryFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "repository_failures_total",
+			Help:      "Number of failed writes on repository",
+		},
+		[]string{"backend", "operation"},
+	)
+
+	// EventFailures count the events that can't be stored
+	EventFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "event_failures_total",
+			Help:      "Number of events that can't be stored",
+		},
+	)
+
+	// EventsDropped count the events dropped because of queue is full
+	EventsDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "events_dropped_total",
+			Help:      "Number of events dropped because of queue is full",
+		},
+	)
+
+	// OutboxDepth is the number of Elasticsearch writes that wait to be retried
+	OutboxDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "outbox_depth",
+			Help:      "Number of Elasticsearch writes that wait to be retried",
+		},
+	)
+
+	// Washes count the number of washing cycles per DFP board
+	Washes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "dfp",
+			Name:      "washes_total",
+			Help:      "Number of washing cycles",
+		},
+		[]string{"board"},
+	)
+
+	// WashDuration is the real duration of washing cycles per DFP board
+	WashDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "dfp",
+			Name:      "wash_duration_seconds",
+			Help:      "Duration of washing cycles",
+			Buckets:   []float64{5, 10, 15, 20, 30, 45, 60, 90, 120},
+		},
+		[]string{"board"},
+	)
+)
+
+// Register add the global metrics and the collectors on registerer
+func Register(registerer prometheus.Registerer, collectors ...prometheus.Collector) error {
+	for _, collector := range append([]prometheus.Collector{RepositoryFailures, EventFailures, EventsDropped, OutboxDepth, Washes, WashDuration}, collectors...) {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/login/usecase"
+	"github.com/disaster37/gobot-fat/models"
+	genericUsecase "github.com/disaster37/gobot-fat/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// AuditSuccess is the result of API call that succeed
+	AuditSuccess = "success"
+
+	// AuditFailure is the result of API call that failed or was refused
+	AuditFailure = "failure"
+)
+
+// Audit record who call each action on API, with source IP, route and result
+// The actor is added on request context, so the events sent by boards also record it.
+// Read requests are not recorded
+func (m *GoMiddleware) Audit(eventUsecase genericUsecase.UsecaseCRUD) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+
+			actor := &helper.Actor{
+				User:     username(c),
+				SourceIP: c.RealIP(),
+				Route:    c.Request().URL.Path,
+				Origin:   helper.OriginAPI,
+			}
+			ctx := helper.WithActor(c.Request().Context(), actor)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+
+			// Handler can return error or write it on response
+			status := c.Response().Status
+			if err != nil {
+				status = http.StatusInternalServerError
+				if he, ok := err.(*echo.HTTPError); ok {
+					status = he.Code
+				}
+			}
+			result := AuditSuccess
+			if status >= http.StatusBadRequest {
+				result = AuditFailure
+			}
+
+			event := &models.Event{
+				SourceName: helper.OriginAPI,
+				Timestamp:  time.Now(),
+				EventType:  c.Request().Method,
+				EventKind:  helper.KindEventAudit,
+				User:       actor.User,
+				SourceIP:   actor.SourceIP,
+				Route:      actor.Route,
+				Origin:     actor.Origin,
+				Result:     result,
+				Status:     status,
+			}
+			if errAudit := eventUsecase.Create(context.WithoutCancel(ctx), event); errAudit != nil {
+				log.Errorf("Error when store audit: %s", errAudit.Error())
+			}
+
+			return err
+		}
+	}
+}
+
+// username return the authenticated user name, or empty string if no user
+func username(c echo.Context) string {
+	if u := c.Get("user"); u != nil {
+		if user, ok := u.(*jwt.Token); ok {
+			if claims, ok := user.Claims.(*usecase.JwtCustomClaims); ok {
+				return claims.Name
+			}
+		}
+	}
+
+	return ""
+}
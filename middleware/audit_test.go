@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/login/usecase"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/tfp"
+	tfpHttp "github.com/disaster37/gobot-fat/tfp/delivery/http"
+	genericUsecase "github.com/disaster37/gobot-fat/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordEventUsecase struct {
+	genericUsecase.MockUsecasetBase
+	events []*models.Event
+}
+
+func (m *recordEventUsecase) Create(ctx context.Context, data interface{}) error {
+	m.events = append(m.events, data.(*models.Event))
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	m := InitMiddleware(nil)
+	eventUsecase := &recordEventUsecase{}
+	e := echo.New()
+
+	call := func(method string, handler echo.HandlerFunc) {
+		req := httptest.NewRequest(method, "/api/tfps/action/stop_pond_pump", nil)
+		req.Header.Set(echo.HeaderXRealIP, "10.0.0.1")
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.Set("user", jwt.NewWithClaims(jwt.SigningMethodHS256, &usecase.JwtCustomClaims{Name: "john", Role: models.RoleOperator}))
+		_ = m.Audit(eventUsecase)(handler)(c)
+	}
+
+	// Read is not recorded
+	call(http.MethodGet, func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	assert.Empty(t, eventUsecase.events)
+
+	// Action succeed and actor is on context
+	var actor *helper.Actor
+	call(http.MethodPost, func(c echo.Context) error {
+		actor = helper.ActorFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+	assert.Len(t, eventUsecase.events, 1)
+	assert.Equal(t, "john", eventUsecase.events[0].User)
+	assert.Equal(t, "10.0.0.1", eventUsecase.events[0].SourceIP)
+	assert.Equal(t, "/api/tfps/action/stop_pond_pump", eventUsecase.events[0].Route)
+	assert.Equal(t, helper.OriginAPI, eventUsecase.events[0].Origin)
+	assert.Equal(t, AuditSuccess, eventUsecase.events[0].Result)
+	assert.Equal(t, http.StatusOK, eventUsecase.events[0].Status)
+	assert.NotNil(t, actor)
+	assert.Equal(t, "john", actor.User)
+
+	// Action failed
+	call(http.MethodPost, func(c echo.Context) error { return echo.ErrForbidden })
+	assert.Len(t, eventUsecase.events, 2)
+	assert.Equal(t, AuditFailure, eventUsecase.events[1].Result)
+	assert.Equal(t, http.StatusForbidden, eventUsecase.events[1].Status)
+
+	call(http.MethodPost, func(c echo.Context) error { return c.NoContent(http.StatusInternalServerError) })
+	assert.Equal(t, AuditFailure, eventUsecase.events[2].Result)
+}
+
+type failingTFPUsecase struct {
+	tfp.Usecase
+}
+
+func (h *failingTFPUsecase) PondPump(ctx context.Context, status bool) error {
+	return errors.New("Pond pump can't start")
+}
+
+func TestAuditFailedAction(t *testing.T) {
+	m := InitMiddleware(nil)
+	eventUsecase := &recordEventUsecase{}
+	e := echo.New()
+	api := e.Group("/api")
+	api.Use(m.Audit(eventUsecase))
+	tfpHttp.NewTFPHandler(api, &failingTFPUsecase{})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/tfps/action/start_pond_pump", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, eventUsecase.events, 1)
+	assert.Equal(t, AuditFailure, eventUsecase.events[0].Result)
+	assert.Equal(t, http.StatusBadRequest, eventUsecase.events[0].Status)
+}
@@ -1,23 +1,79 @@
 package middleware
 
-import "github.com/labstack/echo/v4"
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/disaster37/gobot-fat/user"
+	"github.com/labstack/echo/v4"
+)
 
 // GoMiddleware represent the data-struct for middleware
 type GoMiddleware struct {
-	// another stuff , may be needed by middleware
+	// allowOrigins are the origins permitted to open websocket, `*` permit all origins
+	allowOrigins []string
+
+	// userUsecase permit to read the current role of authenticated user
+	userUsecase user.Usecase
 }
 
 // CORS will handle the CORS middleware
+// The API is allowed for all web sites, because it need the token
 func (m *GoMiddleware) CORS(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		c.Response().Header().Set("Access-Control-Allow-Origin", "*")
 		c.Response().Header().Set("Access-Control-Allow-Headers", "*")
 		c.Response().Header().Set("Access-Control-Allow-Methods", "*")
+		c.Response().Header().Set("Access-Control-Expose-Headers", "ETag")
+		return next(c)
+	}
+}
+
+// IsAllowedOrigin return true if request come from same origin, from allowed origin, or without origin like non browser clients
+// It's needed for websocket, because browsers not apply CORS on websocket upgrade
+func (m *GoMiddleware) IsAllowedOrigin(req *http.Request) bool {
+	origin := req.Header.Get(echo.HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+
+	for _, allowOrigin := range m.allowOrigins {
+		if allowOrigin == "*" || strings.EqualFold(allowOrigin, origin) {
+			return true
+		}
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Host, req.Host)
+}
+
+// RedactToken remove the token from URI written on access log
+// The token is still read on URL by JWT middleware
+func (m *GoMiddleware) RedactToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if req.URL.Query().Has("token") {
+			query := req.URL.Query()
+			query.Set("token", "REDACTED")
+			uri := *req.URL
+			uri.RawQuery = query.Encode()
+			req.RequestURI = uri.RequestURI()
+		}
 		return next(c)
 	}
 }
 
 // InitMiddleware intialize the middleware
-func InitMiddleware() *GoMiddleware {
-	return &GoMiddleware{}
-}
\ No newline at end of file
+// The user usecase is used to check the user still exist with its role on each request, the role on token is used when nil.
+// The allowOrigins are the origins of web UI that are not served by this service, permitted to open websocket
+func InitMiddleware(userUsecase user.Usecase, allowOrigins ...string) *GoMiddleware {
+	return &GoMiddleware{
+		allowOrigins: allowOrigins,
+		userUsecase:  userUsecase,
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func requestWithOrigin(origin string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "http://gobot-fat:4040/api/ws", nil)
+	if origin != "" {
+		req.Header.Set(echo.HeaderOrigin, origin)
+	}
+	return req
+}
+
+func TestIsAllowedOrigin(t *testing.T) {
+	m := InitMiddleware(nil, "http://ui:8080")
+
+	// Same origin, allowed origin or without origin
+	assert.True(t, m.IsAllowedOrigin(requestWithOrigin("http://gobot-fat:4040")))
+	assert.True(t, m.IsAllowedOrigin(requestWithOrigin("http://ui:8080")))
+	assert.True(t, m.IsAllowedOrigin(requestWithOrigin("")))
+
+	// Other web site
+	assert.False(t, m.IsAllowedOrigin(requestWithOrigin("http://evil.com")))
+	assert.False(t, InitMiddleware(nil).IsAllowedOrigin(requestWithOrigin("http://ui:8080")))
+
+	// All origins
+	assert.True(t, InitMiddleware(nil, "*").IsAllowedOrigin(requestWithOrigin("http://evil.com")))
+}
+
+func TestCORS(t *testing.T) {
+	m := InitMiddleware(nil, "http://ui:8080")
+	e := echo.New()
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(requestWithOrigin("http://ui:8080"), rec)
+	assert.NoError(t, m.CORS(func(c echo.Context) error { return nil })(c))
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// API is allowed for all web sites, only websocket check the origin
+	rec = httptest.NewRecorder()
+	c = e.NewContext(requestWithOrigin("http://evil.com"), rec)
+	assert.NoError(t, m.CORS(func(c echo.Context) error { return nil })(c))
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestRedactToken(t *testing.T) {
+	m := InitMiddleware(nil)
+	buf := &bytes.Buffer{}
+	e := echo.New()
+	e.Use(m.RedactToken)
+	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{Output: buf}))
+	e.GET("/api/ws", func(c echo.Context) error {
+		// Token is still readable by JWT middleware
+		assert.Equal(t, "secret", c.QueryParam("token"))
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ws?boards=tfp&token=secret", nil)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	assert.NotContains(t, buf.String(), "secret")
+	assert.Contains(t, buf.String(), "boards=tfp")
+}
@@ -1,23 +1,78 @@
 package middleware
 
 import (
+	"net/http"
+
 	"github.com/disaster37/gobot-fat/login/usecase"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
 )
 
+// role return the role of authenticated user, or empty string if no user
+// The role is read from user store, so a deleted user or a changed role apply before token expire
+func (m *GoMiddleware) role(c echo.Context) string {
+	u := c.Get("user")
+	if u == nil {
+		return ""
+	}
+	token, ok := u.(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims, ok := token.Claims.(*usecase.JwtCustomClaims)
+	if !ok {
+		return ""
+	}
+
+	if m.userUsecase == nil {
+		return claims.GetRole()
+	}
+
+	current, err := m.userUsecase.GetByUsername(c.Request().Context(), claims.Name)
+	if err != nil {
+		if !repository.IsRecordNotFoundError(err) {
+			log.Errorf("Error when read user %s: %s", claims.Name, err.Error())
+		}
+		return ""
+	}
+
+	return current.Role
+}
+
+// IsAdmin permit only admin users
 func (m *GoMiddleware) IsAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		if u := c.Get("user"); u != nil {
-			user := c.Get("user").(*jwt.Token)
-			claims := user.Claims.(*usecase.JwtCustomClaims)
-			isAdmin := claims.Admin
-			if !isAdmin {
-				return echo.ErrUnauthorized
-			}
+		switch m.role(c) {
+		case models.RoleAdmin:
 			return next(c)
+		case "":
+			return echo.ErrUnauthorized
+		default:
+			return echo.ErrForbidden
 		}
+	}
+}
 
-		return echo.ErrUnauthorized
+// IsAuthorized check the user role allow the request
+// Read-only users can only read, operators and admins can also call actions and update settings
+func (m *GoMiddleware) IsAuthorized(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		switch m.role(c) {
+		case models.RoleAdmin, models.RoleOperator:
+			return next(c)
+		case models.RoleReadOnly:
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+			return echo.ErrForbidden
+		case "":
+			return echo.ErrUnauthorized
+		default:
+			return echo.ErrForbidden
+		}
 	}
 }
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/disaster37/gobot-fat/login/usecase"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/user"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func callWithRole(m echo.MiddlewareFunc, method string, claims *usecase.JwtCustomClaims) error {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(method, "/api/dfps", nil), httptest.NewRecorder())
+	if claims != nil {
+		c.Set("user", jwt.NewWithClaims(jwt.SigningMethodHS256, claims))
+	}
+
+	return m(func(c echo.Context) error { return nil })(c)
+}
+
+func TestIsAuthorized(t *testing.T) {
+	m := InitMiddleware(nil)
+
+	// Admin and operator can do all
+	assert.NoError(t, callWithRole(m.IsAuthorized, http.MethodPost, &usecase.JwtCustomClaims{Role: models.RoleAdmin}))
+	assert.NoError(t, callWithRole(m.IsAuthorized, http.MethodPost, &usecase.JwtCustomClaims{Role: models.RoleOperator}))
+
+	// Old token with admin flag
+	assert.NoError(t, callWithRole(m.IsAuthorized, http.MethodPost, &usecase.JwtCustomClaims{Admin: true}))
+
+	// Read-only can only read
+	assert.NoError(t, callWithRole(m.IsAuthorized, http.MethodGet, &usecase.JwtCustomClaims{Role: models.RoleReadOnly}))
+	assert.Equal(t, echo.ErrForbidden, callWithRole(m.IsAuthorized, http.MethodPost, &usecase.JwtCustomClaims{Role: models.RoleReadOnly}))
+
+	// Not authenticated
+	assert.Equal(t, echo.ErrUnauthorized, callWithRole(m.IsAuthorized, http.MethodGet, nil))
+}
+
+func TestIsAdmin(t *testing.T) {
+	m := InitMiddleware(nil)
+
+	assert.NoError(t, callWithRole(m.IsAdmin, http.MethodGet, &usecase.JwtCustomClaims{Role: models.RoleAdmin}))
+	assert.Equal(t, echo.ErrForbidden, callWithRole(m.IsAdmin, http.MethodGet, &usecase.JwtCustomClaims{Role: models.RoleOperator}))
+	assert.Equal(t, echo.ErrUnauthorized, callWithRole(m.IsAdmin, http.MethodGet, nil))
+}
+
+type fakeUserUsecase struct {
+	user.Usecase
+	users map[string]*models.User
+}
+
+func (h *fakeUserUsecase) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	if u, ok := h.users[username]; ok {
+		return u, nil
+	}
+	return nil, repository.ErrRecordNotFoundError
+}
+
+func TestRoleFromUserStore(t *testing.T) {
+	m := InitMiddleware(&fakeUserUsecase{users: map[string]*models.User{
+		"john": {Username: "john", Role: models.RoleReadOnly},
+	}})
+
+	// Role changed after token was issued
+	assert.Equal(t, echo.ErrForbidden, callWithRole(m.IsAuthorized, http.MethodPost, &usecase.JwtCustomClaims{Name: "john", Role: models.RoleAdmin}))
+	assert.NoError(t, callWithRole(m.IsAuthorized, http.MethodGet, &usecase.JwtCustomClaims{Name: "john", Role: models.RoleAdmin}))
+	assert.Equal(t, echo.ErrForbidden, callWithRole(m.IsAdmin, http.MethodGet, &usecase.JwtCustomClaims{Name: "john", Role: models.RoleAdmin}))
+
+	// User deleted after token was issued
+	assert.Equal(t, echo.ErrUnauthorized, callWithRole(m.IsAuthorized, http.MethodGet, &usecase.JwtCustomClaims{Name: "jane", Role: models.RoleAdmin}))
+}
@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/disaster37/gobot-fat/models"
+)
+
+// MockEventUsecase record the events sent by boards
+// It implement the usecase CRUD interface used to store events
+type MockEventUsecase struct {
+	events []*models.Event
+	sync.Mutex
+}
+
+func (m *MockEventUsecase) Get(ctx context.Context, id uint, data interface{}) error { return nil }
+func (m *MockEventUsecase) List(ctx context.Context, listData interface{}) error     { return nil }
+func (m *MockEventUsecase) Update(ctx context.Context, data interface{}) error       { return nil }
+func (m *MockEventUsecase) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+	return nil
+}
+func (m *MockEventUsecase) Init(ctx context.Context, data interface{}) error { return nil }
+
+// Create record the event
+func (m *MockEventUsecase) Create(ctx context.Context, data interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+	m.events = append(m.events, data.(*models.Event))
+	return nil
+}
+
+// Events return a copy of recorded events
+func (m *MockEventUsecase) Events() []*models.Event {
+	m.Lock()
+	defer m.Unlock()
+	events := make([]*models.Event, len(m.events))
+	copy(events, m.events)
+	return events
+}
+
+// Kinds return the number of recorded events of this kind
+func (m *MockEventUsecase) Kinds(kind string) (nb int) {
+	m.Lock()
+	defer m.Unlock()
+	for _, event := range m.events {
+		if event.EventKind == kind {
+			nb++
+		}
+	}
+	return nb
+}
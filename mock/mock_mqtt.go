@@ -0,0 +1,142 @@
+package mock
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/mqtt"
+)
+
+// MockMQTTClient is a stand-in broker that keep messages in memory
+// With OrderMatters, like paho by default, publish wait that message handler in progress return, during Timeout
+type MockMQTTClient struct {
+	IsConnected  bool
+	OrderMatters bool
+	Timeout      time.Duration
+	messages     map[string][]byte
+	handlers     map[string]mqtt.MessageHandler
+	chDelivery   chan struct{}
+	timeouts     int
+	onConnect    func()
+	connectErr   error
+	sync.RWMutex
+}
+
+// NewMockMQTTClient create new in memory MQTT client
+func NewMockMQTTClient() *MockMQTTClient {
+	return &MockMQTTClient{
+		Timeout:    1 * time.Second,
+		messages:   make(map[string][]byte),
+		handlers:   make(map[string]mqtt.MessageHandler),
+		chDelivery: make(chan struct{}, 1),
+	}
+}
+
+func (m *MockMQTTClient) Connect() error {
+	m.Lock()
+	defer m.Unlock()
+	if m.connectErr != nil {
+		return m.connectErr
+	}
+	m.IsConnected = true
+	return nil
+}
+
+// SetConnectError make Connect fail with err, like when broker is down
+func (m *MockMQTTClient) SetConnectError(err error) {
+	m.Lock()
+	defer m.Unlock()
+	m.connectErr = err
+}
+
+func (m *MockMQTTClient) Disconnect() {
+	m.Lock()
+	defer m.Unlock()
+	m.IsConnected = false
+}
+
+func (m *MockMQTTClient) Publish(topic string, retained bool, payload []byte) error {
+	if m.OrderMatters {
+		select {
+		case m.chDelivery <- struct{}{}:
+			defer func() { <-m.chDelivery }()
+		case <-time.After(m.Timeout):
+			m.Lock()
+			m.timeouts++
+			m.Unlock()
+			return errors.New("Timeout when wait MQTT broker")
+		}
+	}
+
+	m.Lock()
+	// Like broker, empty retained payload remove the retained message
+	if retained && len(payload) == 0 {
+		delete(m.messages, topic)
+	} else {
+		m.messages[topic] = payload
+	}
+	handler := m.handlers[topic]
+	m.Unlock()
+
+	if handler != nil {
+		handler(topic, payload)
+	}
+	return nil
+}
+
+// OnConnect set the handler called by Reconnect
+func (m *MockMQTTClient) OnConnect(handler func()) {
+	m.Lock()
+	defer m.Unlock()
+	m.onConnect = handler
+}
+
+// Reconnect simulate a broker restart without session, subscriptions are lost and will message is published
+func (m *MockMQTTClient) Reconnect(willTopic string) {
+	m.Lock()
+	m.handlers = make(map[string]mqtt.MessageHandler)
+	m.messages[willTopic] = []byte(mqtt.PayloadOffline)
+	handler := m.onConnect
+	m.Unlock()
+
+	if handler != nil {
+		handler()
+	}
+}
+
+func (m *MockMQTTClient) Subscribe(topic string, handler mqtt.MessageHandler) error {
+	m.Lock()
+	defer m.Unlock()
+	m.handlers[topic] = handler
+	return nil
+}
+
+// Message return the last payload published on topic
+func (m *MockMQTTClient) Message(topic string) []byte {
+	m.RLock()
+	defer m.RUnlock()
+	return m.messages[topic]
+}
+
+// Topics return the topics that start with prefix
+func (m *MockMQTTClient) Topics(prefix string) []string {
+	m.RLock()
+	defer m.RUnlock()
+
+	topics := make([]string, 0)
+	for topic := range m.messages {
+		if strings.HasPrefix(topic, prefix) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// Timeouts return the number of publish that timed out because of message handler in progress
+func (m *MockMQTTClient) Timeouts() int {
+	m.RLock()
+	defer m.RUnlock()
+	return m.timeouts
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// BackupFormatVersion is the version of backup archive format, increased on each breaking change
+const BackupFormatVersion = 1
+
+// Backup is the archive of configs and states, with recent events if asked
+type Backup struct {
+	// FormatVersion is the archive format version
+	FormatVersion int `json:"format_version"`
+
+	// CreatedAt is when backup was done
+	CreatedAt time.Time `json:"created_at"`
+
+	DFPConfigs  []*DFPConfig  `json:"dfp_configs"`
+	DFPStates   []*DFPState   `json:"dfp_states"`
+	TFPConfigs  []*TFPConfig  `json:"tfp_configs"`
+	TFPStates   []*TFPState   `json:"tfp_states"`
+	TankConfigs []*TankConfig `json:"tank_configs"`
+
+	// Events are only exported, they are not restored
+	Events []*Event `json:"events,omitempty"`
+}
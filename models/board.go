@@ -1,5 +1,29 @@
 package models
 
+import (
+	"time"
+)
+
+const (
+	// BoardStateStarting is the state of board during the first start
+	BoardStateStarting = "starting"
+
+	// BoardStateRunning is the state of board started successfully
+	BoardStateRunning = "running"
+
+	// BoardStateRetrying is the state of board that failed to start and wait before try again
+	BoardStateRetrying = "retrying"
+
+	// BoardStateStopping is the state of board while it's stopped on background
+	BoardStateStopping = "stopping"
+
+	// BoardStateStopped is the state of board not started or stopped
+	BoardStateStopped = "stopped"
+
+	// BoardStateFailed is the state of board that failed to stop
+	BoardStateFailed = "failed"
+)
+
 // Board represent generic board
 type Board struct {
 
@@ -8,4 +32,68 @@ type Board struct {
 
 	// IsOnline is true if board is online
 	IsOnline bool `json:"is_online" jsonapi:"attr,is_online"`
+
+	// IsInitialized is true when board handle events
+	IsInitialized bool `json:"is_initialized" jsonapi:"attr,is_initialized"`
+
+	// LastPoll is the last time board was read successfully, nil if never
+	LastPoll *time.Time `json:"last_poll,omitempty" jsonapi:"attr,last_poll,iso8601,omitempty"`
+
+	// State is the lifecycle state: starting, running, retrying, stopped or failed
+	State string `json:"state" jsonapi:"attr,state"`
+
+	// StartedAt is the last time board was started successfully, nil when stopped
+	StartedAt *time.Time `json:"started_at,omitempty" jsonapi:"attr,started_at,iso8601,omitempty"`
+
+	// Uptime is the number of seconds since board is started
+	Uptime int64 `json:"uptime" jsonapi:"attr,uptime"`
+
+	// RebootCount is the number of board reboots detected
+	RebootCount int64 `json:"reboot_count" jsonapi:"attr,reboot_count"`
+
+	// OfflineCount is the number of times board went offline
+	OfflineCount int64 `json:"offline_count" jsonapi:"attr,offline_count"`
+
+	// LastError is the last error when read board
+	LastError string `json:"last_error,omitempty" jsonapi:"attr,last_error,omitempty"`
+
+	// LastErrorAt is the time of last error
+	LastErrorAt *time.Time `json:"last_error_at,omitempty" jsonapi:"attr,last_error_at,iso8601,omitempty"`
+
+	// AverageLatency is the average time of requests on board, in milliseconds
+	AverageLatency float64 `json:"average_latency" jsonapi:"attr,average_latency"`
+}
+
+// BoardDiagnostic is the board diagnostic, kept across restarts to diagnose flaky boards
+type BoardDiagnostic struct {
+	ModelGeneric
+
+	ID uint `jsonapi:"primary,board-diagnostics" gorm:"primary_key"`
+
+	// Name is the board name
+	Name string `json:"name" jsonapi:"attr,name" gorm:"column:name;uniqueIndex"`
+
+	// StartedAt is the last time board was started successfully, nil when stopped
+	StartedAt *time.Time `json:"started_at,omitempty" jsonapi:"attr,started_at,iso8601,omitempty" gorm:"column:started_at"`
+
+	// LastPoll is the last time board was read successfully
+	LastPoll *time.Time `json:"last_poll,omitempty" jsonapi:"attr,last_poll,iso8601,omitempty" gorm:"column:last_poll"`
+
+	// RebootCount is the number of board reboots detected
+	RebootCount int64 `json:"reboot_count" jsonapi:"attr,reboot_count" gorm:"column:reboot_count"`
+
+	// OfflineCount is the number of times board went offline
+	OfflineCount int64 `json:"offline_count" jsonapi:"attr,offline_count" gorm:"column:offline_count"`
+
+	// LastError is the last error when read board
+	LastError string `json:"last_error,omitempty" jsonapi:"attr,last_error,omitempty" gorm:"column:last_error"`
+
+	// LastErrorAt is the time of last error
+	LastErrorAt *time.Time `json:"last_error_at,omitempty" jsonapi:"attr,last_error_at,iso8601,omitempty" gorm:"column:last_error_at"`
+
+	// AverageLatency is the average time of requests on board, in milliseconds
+	AverageLatency float64 `json:"average_latency" jsonapi:"attr,average_latency" gorm:"column:average_latency"`
+
+	// Requests is the number of requests used to compute average latency
+	Requests int64 `json:"requests" jsonapi:"attr,requests" gorm:"column:requests"`
 }
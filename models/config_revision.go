@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ConfigRevision is a version of DFP, TFP or tank config, saved on each update
+type ConfigRevision struct {
+	ModelGeneric
+
+	ID uint `jsonapi:"primary,config-revisions" gorm:"primary_key"`
+
+	// The config resource, like dfp-configs
+	Kind string `json:"kind" jsonapi:"attr,kind" gorm:"column:kind;index:idx_config_revision"`
+
+	// The config ID
+	ConfigID uint `json:"config_id" jsonapi:"attr,config_id" gorm:"column:config_id;index:idx_config_revision"`
+
+	// The config version
+	ConfigVersion int64 `json:"config_version" jsonapi:"attr,config_version" gorm:"column:config_version"`
+
+	// The config attributes on this version
+	Data map[string]interface{} `json:"data" jsonapi:"attr,data" gorm:"column:data;type:text;serializer:json"`
+
+	// The user that update config, empty when updated by board
+	Author string `json:"author,omitempty" jsonapi:"attr,author,omitempty" gorm:"column:author"`
+
+	// The origin of update, like api
+	Origin string `json:"origin,omitempty" jsonapi:"attr,origin,omitempty" gorm:"column:origin"`
+
+	// When config was updated
+	Timestamp time.Time `json:"timestamp" jsonapi:"attr,timestamp,iso8601" gorm:"column:timestamp"`
+}
+
+// ConfigChange is an attribute that changed between two config versions
+type ConfigChange struct {
+	Attribute string      `json:"attribute"`
+	From      interface{} `json:"from"`
+	To        interface{} `json:"to"`
+}
+
+// ConfigDiff is the list of changes between two config versions
+type ConfigDiff struct {
+	ID string `jsonapi:"primary,config-diffs"`
+
+	// The version compared
+	From int64 `json:"from" jsonapi:"attr,from"`
+
+	// The version compared with
+	To int64 `json:"to" jsonapi:"attr,to"`
+
+	// The changed attributes
+	Changes []*ConfigChange `json:"changes" jsonapi:"attr,changes"`
+}
+
+func (h ConfigRevision) TableName() string {
+	return "config_revisions"
+}
+
+func (h *ConfigRevision) String() string {
+	data, err := json.Marshal(h)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func (h *ConfigRevision) SetID(id uint) {
+	h.ID = id
+}
+
+func (h *ConfigRevision) GetID() uint {
+	return h.ID
+}
@@ -5,21 +5,83 @@ import (
 	"time"
 )
 
+// Document is a model which ID can be generated by repository, like Elasticsearch
+// The document ID is a string and it's the ID returned by API
+type Document interface {
+	SetDocumentID(id string)
+}
+
 // Event contain data event
+// The DocumentID is the Elasticsearch ID, or the SQL ID when events are searched on SQL
 type Event struct {
 	ModelGeneric
 
-	ID                      uint      `jsonapi:"primary,events" gorm:"primary_key"`
-	SourceID                string    `json:"source_id" validate:"required"`
-	SourceName              string    `json:"source_name" validate:"required"`
-	Timestamp               time.Time `json:"timestamp" validate:"required"`
-	EventType               string    `json:"type" validate:"required"`
-	EventKind               string    `json:"kind" validate:"required"`
-	Temperature             float64   `json:"temperature,omitempty"`
-	Humidity                float64   `json:"humidity,omitempty"`
-	Duration                int64     `json:"duration,omitempty"`
-	DurationFromLastWashing int64     `json:"duration_from_last,omitempty"`
-	Level                   int64     `json:"level,omitempty"`
+	ID                      uint      `gorm:"primary_key"`
+	DocumentID              string    `json:"-" jsonapi:"primary,events" gorm:"-"`
+	SourceID                string    `json:"source_id" jsonapi:"attr,source_id" validate:"required"`
+	SourceName              string    `json:"source_name" jsonapi:"attr,source_name" validate:"required"`
+	Timestamp               time.Time `json:"timestamp" jsonapi:"attr,timestamp,iso8601" validate:"required"`
+	EventType               string    `json:"type" jsonapi:"attr,event_type" validate:"required"`
+	EventKind               string    `json:"kind" jsonapi:"attr,event_kind" validate:"required"`
+	Temperature             float64   `json:"temperature,omitempty" jsonapi:"attr,temperature,omitempty"`
+	Humidity                float64   `json:"humidity,omitempty" jsonapi:"attr,humidity,omitempty"`
+	Duration                int64     `json:"duration,omitempty" jsonapi:"attr,duration,omitempty"`
+	DurationFromLastWashing int64     `json:"duration_from_last,omitempty" jsonapi:"attr,duration_from_last,omitempty"`
+	Level                   int64     `json:"level,omitempty" jsonapi:"attr,level,omitempty"`
+	Message                 string    `json:"message,omitempty" jsonapi:"attr,message,omitempty"`
+	User                    string    `json:"user,omitempty" jsonapi:"attr,user,omitempty"`
+	SourceIP                string    `json:"source_ip,omitempty" jsonapi:"attr,source_ip,omitempty"`
+	Route                   string    `json:"route,omitempty" jsonapi:"attr,route,omitempty"`
+	Origin                  string    `json:"origin,omitempty" jsonapi:"attr,origin,omitempty"`
+	Result                  string    `json:"result,omitempty" jsonapi:"attr,result,omitempty"`
+	Status                  int       `json:"status,omitempty" jsonapi:"attr,status,omitempty"`
+}
+
+// EventFilter is the criteria to search events
+type EventFilter struct {
+
+	// SourceName is the board name that produce the event
+	SourceName string
+
+	// Kind is the event kind, like wash or start_board
+	Kind string
+
+	// Type is the event type, like the relay name
+	Type string
+
+	// User is the user that trigger action
+	User string
+
+	// Origin is api, button or auto
+	Origin string
+
+	// Result is success or failure of API call
+	Result string
+
+	// IsAudit is true to only get events that trigger by someone or automatically
+	IsAudit bool
+
+	// From is the begin of time range
+	From time.Time
+
+	// To is the end of time range
+	To time.Time
+
+	// Sort is the field used to sort events
+	Sort string
+
+	// SortDesc is true to sort from the newest event
+	SortDesc bool
+
+	// Page is the page number, start from 1
+	Page int
+
+	// Size is the number of events per page
+	Size int
+}
+
+func (h Event) TableName() string {
+	return "events"
 }
 
 func (h *Event) String() string {
@@ -37,3 +99,8 @@ func (h *Event) SetID(id uint) {
 func (h *Event) GetID() uint {
 	return h.ID
 }
+
+// SetDocumentID set the ID generated by repository
+func (h *Event) SetDocumentID(id string) {
+	h.DocumentID = id
+}
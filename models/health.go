@@ -0,0 +1,44 @@
+package models
+
+const (
+	// HealthStatusUp is when all is working
+	HealthStatusUp = "up"
+
+	// HealthStatusDegraded is when service work, but some non critical dependencies are down
+	HealthStatusDegraded = "degraded"
+
+	// HealthStatusDown is when service can't work
+	HealthStatusDown = "down"
+)
+
+// Health is the status of service and its dependencies
+type Health struct {
+	ID string `jsonapi:"primary,health"`
+
+	// Status is up, degraded or down
+	Status string `json:"status" jsonapi:"attr,status"`
+
+	// Checks are the status of each dependency
+	Checks []*HealthCheck `json:"checks" jsonapi:"attr,checks"`
+}
+
+// HealthCheck is the status of one dependency, like database or board
+type HealthCheck struct {
+	// Name is the dependency name, like the board name
+	Name string `json:"name"`
+
+	// Kind is the dependency kind, like database, elasticsearch, smtp, board or events
+	Kind string `json:"kind"`
+
+	// Status is up, degraded or down
+	Status string `json:"status"`
+
+	// Critical is true when service can't work without this dependency
+	Critical bool `json:"critical"`
+
+	// Detail explain the status, like the error
+	Detail string `json:"detail,omitempty"`
+
+	// Data are the values checked, like the time since last poll
+	Data map[string]interface{} `json:"data,omitempty"`
+}
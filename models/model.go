@@ -11,7 +11,7 @@ type ModelGeneric struct {
 	DeletedAt *time.Time `sql:"index"`
 
 	// Version of configuration
-	Version int64 `json:"version" gorm:"column:version;type:bigint" validate:"required"`
+	Version int64 `json:"version" gorm:"column:version;type:bigint" validate:"gte=0"`
 }
 
 type Model interface {
@@ -22,6 +22,12 @@ type Model interface {
 	SetID(id uint)
 }
 
+// Fixed is implemented by model with attributes that identify it and can't be changed after create
+type Fixed interface {
+	// FixedAttributes return the JSON names of fixed attributes
+	FixedAttributes() []string
+}
+
 // SetVersion permit to set version
 func (h *ModelGeneric) SetVersion(version int64) {
 	h.Version = version
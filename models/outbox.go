@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEntry is an Elasticsearch write that failed and wait to be retried
+type OutboxEntry struct {
+	ModelGeneric
+
+	ID uint `jsonapi:"primary,outbox" gorm:"primary_key"`
+
+	// The Elasticsearch index where to write document
+	Index string `json:"index" jsonapi:"attr,index" gorm:"column:index_name"`
+
+	// The document ID, 0 when Elasticsearch manage it
+	DocumentID uint `json:"document_id" jsonapi:"attr,document_id" gorm:"column:document_id"`
+
+	// The document as JSON
+	Document string `json:"document" gorm:"column:document;type:text"`
+
+	// The number of failed retries
+	Attempts int64 `json:"attempts" jsonapi:"attr,attempts" gorm:"column:attempts"`
+
+	// The next retry date
+	NextRetry time.Time `json:"next_retry" jsonapi:"attr,next_retry,iso8601" gorm:"column:next_retry"`
+
+	// The last error
+	LastError string `json:"last_error" jsonapi:"attr,last_error" gorm:"column:last_error"`
+}
+
+// OutboxStatus is the outbox queue state
+type OutboxStatus struct {
+	ID string `jsonapi:"primary,outbox-status"`
+
+	// The number of writes that wait to be retried
+	Depth int64 `json:"depth" jsonapi:"attr,depth"`
+
+	// The number of writes retried successfully by flush
+	Flushed int64 `json:"flushed,omitempty" jsonapi:"attr,flushed,omitempty"`
+}
+
+func (h OutboxEntry) TableName() string {
+	return "outbox"
+}
+
+func (h *OutboxEntry) String() string {
+	data, err := json.Marshal(h)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func (h *OutboxEntry) SetID(id uint) {
+	h.ID = id
+}
+
+func (h *OutboxEntry) GetID() uint {
+	return h.ID
+}
@@ -17,4 +17,13 @@ type Tank struct {
 
 	// The current distance in cm
 	Distance int `json:"distance" jsonapi:"attr,distance"`
+
+	// True when the level is under the low level threshold
+	IsLowLevel bool `json:"is_low_level" jsonapi:"attr,is_low_level"`
+
+	// True when the level is upper the high level threshold
+	IsHighLevel bool `json:"is_high_level" jsonapi:"attr,is_high_level"`
+
+	// True when the level is under the dry run threshold
+	IsDryRun bool `json:"is_dry_run" jsonapi:"attr,is_dry_run"`
 }
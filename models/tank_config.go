@@ -4,6 +4,14 @@ import (
 	"encoding/json"
 )
 
+const (
+	// TankOriginConfig is the origin of tank declared on config file
+	TankOriginConfig = "config"
+
+	// TankOriginAPI is the origin of tank created over API, it can be deleted
+	TankOriginAPI = "api"
+)
+
 // TankConfig contain the tank config
 type TankConfig struct {
 	ModelGeneric
@@ -11,19 +19,40 @@ type TankConfig struct {
 	ID uint `jsonapi:"primary,tank-configs" gorm:"primary_key"`
 
 	// Enable is set to true if board is enabled
-	Enable bool `json:"enable" jsonapi:"attr,enable" gorm:"column:enable" validate:"required"`
+	Enable bool `json:"enable" jsonapi:"attr,enable" gorm:"column:enable"`
 
 	// The board name
 	Name string `json:"name" jsonapi:"attr,name" gorm:"unique,column:name"`
 
+	// The aREST URL of board created over API. The URL of board declared on config file is not saved here
+	URL string `json:"url" jsonapi:"attr,url" gorm:"column:url" validate:"omitempty,url"`
+
+	// Origin is where tank is declared, TankOriginConfig or TankOriginAPI
+	Origin string `json:"origin" jsonapi:"attr,origin" gorm:"column:origin"`
+
 	// The tank depth in cm
-	Depth int64 `json:"depth" jsonapi:"attr,depth" gorm:"column:depth" validate:"required"`
+	Depth int64 `json:"depth" jsonapi:"attr,depth" gorm:"column:depth" validate:"min=1"`
 
 	// The sensor heigh in cm
-	SensorHeight int64 `json:"sensor_height" jsonapi:"attr,sensor_height" gorm:"column:sensor_height" validate:"required"`
+	SensorHeight int64 `json:"sensor_height" jsonapi:"attr,sensor_height" gorm:"column:sensor_height" validate:"min=0"`
 
 	// The liter per cm
-	LiterPerCm int64 `json:"liter_per_cm" jsonapi:"attr,liter_per_cm" gorm:"column:liter_per_cm" validate:"required"`
+	LiterPerCm int64 `json:"liter_per_cm" jsonapi:"attr,liter_per_cm" gorm:"column:liter_per_cm" validate:"min=1"`
+
+	// The low level alert threshold in percent, 0 to disable it
+	LowLevelThreshold int64 `json:"low_level_threshold" jsonapi:"attr,low_level_threshold" gorm:"column:low_level_threshold" validate:"min=0,max=100"`
+
+	// The high level alert threshold in percent, 0 to disable it
+	HighLevelThreshold int64 `json:"high_level_threshold" jsonapi:"attr,high_level_threshold" gorm:"column:high_level_threshold" validate:"min=0,max=100"`
+
+	// The hysteresis in percent to leave low or high level alert
+	LevelHysteresis int64 `json:"level_hysteresis" jsonapi:"attr,level_hysteresis" gorm:"column:level_hysteresis" validate:"min=0,max=100"`
+
+	// The level in percent under which the pumps that use this tank must be stopped, 0 to disable it
+	DryRunThreshold int64 `json:"dry_run_threshold" jsonapi:"attr,dry_run_threshold" gorm:"column:dry_run_threshold" validate:"min=0,max=100"`
+
+	// The level in percent upper which the pumps can run again after dry run
+	DryRunRestartThreshold int64 `json:"dry_run_restart_threshold" jsonapi:"attr,dry_run_restart_threshold" gorm:"column:dry_run_restart_threshold" validate:"min=0,max=100"`
 }
 
 func (h TankConfig) TableName() string {
@@ -45,3 +74,8 @@ func (h *TankConfig) SetID(id uint) {
 func (h *TankConfig) GetID() uint {
 	return h.ID
 }
+
+// FixedAttributes return the name, the URL and the origin, they identify the board of tank
+func (h *TankConfig) FixedAttributes() []string {
+	return []string{"name", "url", "origin"}
+}
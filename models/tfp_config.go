@@ -2,6 +2,9 @@ package models
 
 import (
 	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,37 +15,46 @@ type TFPConfig struct {
 	ID uint `jsonapi:"primary,tfp-configs" gorm:"primary_key"`
 
 	// Enable is set to true if board is enabled
-	Enable bool `json:"enable" jsonapi:"attr,enable" gorm:"column:enable" validate:"required"`
+	Enable bool `json:"enable" jsonapi:"attr,enable" gorm:"column:enable"`
 
 	// UVC1BlisterMaxTime is the max usage in hour of UVC1 blister
-	UVC1BlisterMaxTime int64 `json:"uvc1_blister_max_time" jsonapi:"attr,uvc1_blister_max_time" gorm:"column:uvc1_blister_max_time" validate:"required"`
+	UVC1BlisterMaxTime int64 `json:"uvc1_blister_max_time" jsonapi:"attr,uvc1_blister_max_time" gorm:"column:uvc1_blister_max_time" validate:"min=1"`
 
 	// UVC1BlisterMaxTime is the max usage in hour of UVC2 blister
-	UVC2BlisterMaxTime int64 `json:"uvc2_blister_max_time" jsonapi:"attr,uvc2_blister_max_time" gorm:"column:uvc2_blister_max_time" validate:"required"`
+	UVC2BlisterMaxTime int64 `json:"uvc2_blister_max_time" jsonapi:"attr,uvc2_blister_max_time" gorm:"column:uvc2_blister_max_time" validate:"min=1"`
 
 	// OzoneBlisterMaxTime is the max usage in hour of ozonne blister
-	OzoneBlisterMaxTime int64 `json:"ozone_blister_max_time" jsonapi:"attr,ozone_blister_max_time" gorm:"column:ozone_blister_max_time" validate:"required"`
+	OzoneBlisterMaxTime int64 `json:"ozone_blister_max_time" jsonapi:"attr,ozone_blister_max_time" gorm:"column:ozone_blister_max_time" validate:"min=1"`
+
+	// BlisterAlertThresholds is the list of blister usage percent, comma separated, that send alert. For exemple `90,100`
+	BlisterAlertThresholds string `json:"blister_alert_thresholds" jsonapi:"attr,blister_alert_thresholds" gorm:"column:blister_alert_thresholds" validate:"omitempty,percents"`
+
+	// IsBlisterAutoCutoff permit to stop UVC when blister reach its max usage
+	IsBlisterAutoCutoff bool `json:"is_blister_auto_cutoff" jsonapi:"attr,is_blister_auto_cutoff" gorm:"column:is_blister_auto_cutoff"`
 
 	// IsWaterfallAuto permit to start / stop waterfall pump automatically
-	IsWaterfallAuto bool `json:"is_waterfall_auto" jsonapi:"attr,is_waterfall_auto" gorm:"column:is_waterfall_auto" validate:"required"`
+	IsWaterfallAuto bool `json:"is_waterfall_auto" jsonapi:"attr,is_waterfall_auto" gorm:"column:is_waterfall_auto"`
 
 	// StartTimeWaterfall is the hour of day when start waterfall pump
-	StartTimeWaterfall string `json:"start_time_waterfall" jsonapi:"attr,start_time_waterfall" gorm:"column:start_time_waterfall" validate:"required"`
+	StartTimeWaterfall string `json:"start_time_waterfall" jsonapi:"attr,start_time_waterfall" gorm:"column:start_time_waterfall" validate:"hhmm"`
 
 	// StopTimeWaterfall is the hour of day when stop waterfall pump
-	StopTimeWaterfall string `json:"stop_time_waterfall" jsonapi:"attr,stop_time_waterfall" gorm:"column:stop_time_waterfall" validate:"required"`
+	StopTimeWaterfall string `json:"stop_time_waterfall" jsonapi:"attr,stop_time_waterfall" gorm:"column:stop_time_waterfall" validate:"hhmm,nefield=StartTimeWaterfall"`
+
+	// BacteriumDuration is the number of hours UVC are stopped after introduce bacterium
+	BacteriumDuration int64 `json:"bacterium_duration" jsonapi:"attr,bacterium_duration" gorm:"column:bacterium_duration" validate:"min=0"`
 
 	//Mode is ozone, or UVC or none
-	Mode string `json:"mode" gorm:"column:mode" jsonapi:"attr,mode" validate:"required"`
+	Mode string `json:"mode" gorm:"column:mode" jsonapi:"attr,mode" validate:"oneof=ozone uvc none"`
 
 	// UVC1BlisterTime is the date when replace UVC1 blister
-	UVC1BlisterTime time.Time `json:"uvc1_blister_time" jsonapi:"attr,uvc1_blister_time,iso8601" gorm:"column:uvc1_blister_time" validate:"required"`
+	UVC1BlisterTime time.Time `json:"uvc1_blister_time" jsonapi:"attr,uvc1_blister_time,iso8601" gorm:"column:uvc1_blister_time"`
 
 	// UVC2BlisterTime is the date when replace UVC2 blister
-	UVC2BlisterTime time.Time `json:"uvc2_blister_time" jsonapi:"attr,uvc2_blister_time,iso8601" gorm:"column:uvc2_blister_time" validate:"required"`
+	UVC2BlisterTime time.Time `json:"uvc2_blister_time" jsonapi:"attr,uvc2_blister_time,iso8601" gorm:"column:uvc2_blister_time"`
 
 	// OzoneBlisterTime is the date when replace Ozone blister
-	OzoneBlisterTime time.Time `json:"ozone_blister_time" jsonapi:"attr,ozone_blister_time,iso8601" gorm:"column:ozone_blister_time" validate:"required"`
+	OzoneBlisterTime time.Time `json:"ozone_blister_time" jsonapi:"attr,ozone_blister_time,iso8601" gorm:"column:ozone_blister_time"`
 }
 
 // String print the current object as json
@@ -54,6 +66,22 @@ func (h *TFPConfig) String() string {
 	return string(str)
 }
 
+// BlisterThresholds return the blister alert thresholds sorted
+// Bad values are ignored
+func (h *TFPConfig) BlisterThresholds() []int64 {
+	thresholds := make([]int64, 0)
+	for _, value := range strings.Split(h.BlisterAlertThresholds, ",") {
+		threshold, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || threshold <= 0 {
+			continue
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+
+	return thresholds
+}
+
 // TableName permit to return the current table name
 func (h TFPConfig) TableName() string {
 	return "tfpconfig"
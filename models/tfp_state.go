@@ -40,6 +40,39 @@ type TFPState struct {
 	// OzoneBlisterNbHour is the blister usage in hour of Ozone
 	OzoneBlisterNbHour int64 `json:"ozone_blister_nb_hour" jsonapi:"attr,ozone_blister_nb_hour" gorm:"column:ozone_blister_nb_hour" validate:"required"`
 
+	// UVC1BlisterAlert is the last threshold in percent of UVC1 blister usage that send alert
+	UVC1BlisterAlert int64 `json:"uvc1_blister_alert" jsonapi:"attr,uvc1_blister_alert" gorm:"column:uvc1_blister_alert"`
+
+	// UVC2BlisterAlert is the last threshold in percent of UVC2 blister usage that send alert
+	UVC2BlisterAlert int64 `json:"uvc2_blister_alert" jsonapi:"attr,uvc2_blister_alert" gorm:"column:uvc2_blister_alert"`
+
+	// OzoneBlisterAlert is the last threshold in percent of ozone blister usage that send alert
+	OzoneBlisterAlert int64 `json:"ozone_blister_alert" jsonapi:"attr,ozone_blister_alert" gorm:"column:ozone_blister_alert"`
+
+	// UVC1BlisterRemainingHours is the number of hours before replace UVC1 blister
+	// It's computed from config and not stored
+	UVC1BlisterRemainingHours int64 `json:"uvc1_blister_remaining_hours" jsonapi:"attr,uvc1_blister_remaining_hours" gorm:"-"`
+
+	// UVC2BlisterRemainingHours is the number of hours before replace UVC2 blister
+	// It's computed from config and not stored
+	UVC2BlisterRemainingHours int64 `json:"uvc2_blister_remaining_hours" jsonapi:"attr,uvc2_blister_remaining_hours" gorm:"-"`
+
+	// OzoneBlisterRemainingHours is the number of hours before replace ozone blister
+	// It's computed from config and not stored
+	OzoneBlisterRemainingHours int64 `json:"ozone_blister_remaining_hours" jsonapi:"attr,ozone_blister_remaining_hours" gorm:"-"`
+
+	// UVC1BlisterReplacementDate is the estimated date to replace UVC1 blister
+	// It's computed from config and not stored
+	UVC1BlisterReplacementDate time.Time `json:"uvc1_blister_replacement_date" jsonapi:"attr,uvc1_blister_replacement_date,iso8601,omitempty" gorm:"-"`
+
+	// UVC2BlisterReplacementDate is the estimated date to replace UVC2 blister
+	// It's computed from config and not stored
+	UVC2BlisterReplacementDate time.Time `json:"uvc2_blister_replacement_date" jsonapi:"attr,uvc2_blister_replacement_date,iso8601,omitempty" gorm:"-"`
+
+	// OzoneBlisterReplacementDate is the estimated date to replace ozone blister
+	// It's computed from config and not stored
+	OzoneBlisterReplacementDate time.Time `json:"ozone_blister_replacement_date" jsonapi:"attr,ozone_blister_replacement_date,iso8601,omitempty" gorm:"-"`
+
 	// IsSecurity is true when security is fire
 	IsSecurity bool `json:"is_security" jsonapi:"attr,is_security" gorm:"column:is_security" validate:"required"`
 
@@ -49,9 +82,17 @@ type TFPState struct {
 	// IsDisableSecurity permit to not handle security state
 	IsDisableSecurity bool `json:"is_disable_security" jsonapi:"attr,is_disable_security" gorm:"column:is_disable_security" validate:"required"`
 
+	// IsDryRun is true when the pond tank is too low to run the pumps
+	// It's read from the pond tank board on start, then set by its events, and not stored
+	IsDryRun bool `json:"is_dry_run" jsonapi:"attr,is_dry_run" gorm:"-"`
+
 	// BacteriumTime is the time when introduce bacterium to power off UVC during 48h
 	BacteriumTime time.Time `json:"bacterium_time" jsonapi:"attr,bacterium_time,iso8601" gorm:"column:bacterium_time" validate:"required"`
 
+	// BacteriumRemainingTime is the number of seconds before restore UVC
+	// It's computed from BacteriumTime and not stored
+	BacteriumRemainingTime int64 `json:"bacterium_remaining_time" jsonapi:"attr,bacterium_remaining_time" gorm:"-"`
+
 	AcknoledgeWaterfallAuto bool `json:"acknoledge_waterfall_auto" jsonapi:"attr,acknoledge_waterfall_auto" gorm:"column:acknoledge_waterfall_auto" validate:"required"`
 
 	// IsWaterfallAuto is managed by tfpConfig
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+const (
+	// RoleAdmin can do all, including manage users
+	RoleAdmin = "admin"
+
+	// RoleOperator can read and call actions or update settings
+	RoleOperator = "operator"
+
+	// RoleReadOnly can only read
+	RoleReadOnly = "read-only"
+)
+
+// User is an account that can login on API
+type User struct {
+	ModelGeneric
+
+	ID uint `jsonapi:"primary,users" gorm:"primary_key"`
+
+	// The login
+	Username string `json:"username" jsonapi:"attr,username" gorm:"unique;column:username" validate:"required"`
+
+	// The password, only used to create or update user. It's never stored nor returned
+	Password string `json:"password,omitempty" jsonapi:"attr,password,omitempty" gorm:"-"`
+
+	// The bcrypt hash of password
+	PasswordHash string `json:"-" gorm:"column:password_hash"`
+
+	// The role, admin, operator or read-only
+	Role string `json:"role" jsonapi:"attr,role" gorm:"column:role" validate:"required"`
+}
+
+func (h User) TableName() string {
+	return "users"
+}
+
+func (h *User) String() string {
+	data, err := json.Marshal(h)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func (h *User) SetID(id uint) {
+	h.ID = id
+}
+
+func (h *User) GetID() uint {
+	return h.ID
+}
+
+// IsValidRole return true if role is known
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleOperator, RoleReadOnly:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate check models with `validate` tags and cross fields rules
+var validate = newValidator()
+
+// ValidationError is an attribute with invalid value
+type ValidationError struct {
+	// Attribute is the JSON:API attribute name
+	Attribute string
+
+	// Message explain why the value is invalid
+	Message string
+}
+
+// ValidationErrors is the list of invalid attributes
+type ValidationErrors []*ValidationError
+
+// Error return all invalid attributes on one line
+func (h ValidationErrors) Error() string {
+	messages := make([]string, 0, len(h))
+	for _, err := range h {
+		messages = append(messages, fmt.Sprintf("%s %s", err.Attribute, err.Message))
+	}
+	return strings.Join(messages, ", ")
+}
+
+// Validate check data with its `validate` tags and cross fields rules
+// It return ValidationErrors when some attributes are invalid
+func Validate(data interface{}) error {
+	err := validate.Struct(data)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	validationErrors := make(ValidationErrors, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		validationErrors = append(validationErrors, &ValidationError{
+			Attribute: fieldError.Field(),
+			Message:   message(data, fieldError),
+		})
+	}
+
+	return validationErrors
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// Use JSON:API attribute name on errors
+	v.RegisterTagNameFunc(attributeName)
+
+	_ = v.RegisterValidation("hhmm", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse("15:04", fl.Field().String())
+		return err == nil
+	})
+
+	_ = v.RegisterValidation("percents", func(fl validator.FieldLevel) bool {
+		for _, value := range strings.Split(fl.Field().String(), ",") {
+			percent, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || percent <= 0 {
+				return false
+			}
+		}
+		return true
+	})
+
+	v.RegisterStructValidation(validateTankConfig, TankConfig{})
+
+	return v
+}
+
+// validateTankConfig check rules between level thresholds
+func validateTankConfig(sl validator.StructLevel) {
+	config := sl.Current().Interface().(TankConfig)
+
+	if config.LowLevelThreshold > 0 && config.HighLevelThreshold > 0 && config.HighLevelThreshold <= config.LowLevelThreshold {
+		sl.ReportError(config.HighLevelThreshold, "high_level_threshold", "HighLevelThreshold", "gtfield", "LowLevelThreshold")
+	}
+	if config.DryRunThreshold > 0 && config.DryRunRestartThreshold <= config.DryRunThreshold {
+		sl.ReportError(config.DryRunRestartThreshold, "dry_run_restart_threshold", "DryRunRestartThreshold", "gtfield", "DryRunThreshold")
+	}
+}
+
+// attributeName return the JSON:API attribute name of field, or its JSON name
+func attributeName(field reflect.StructField) string {
+	if tag := strings.Split(field.Tag.Get("jsonapi"), ","); len(tag) > 1 && tag[0] == "attr" {
+		return tag[1]
+	}
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// message return human readable reason of validation error
+func message(data interface{}, fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "min", "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fieldError.Param())
+	case "max", "lte":
+		return fmt.Sprintf("must be lower than or equal to %s", fieldError.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(fieldError.Param()), ", "))
+	case "hhmm":
+		return "must be a time formatted as HH:MM"
+	case "percents":
+		return "must be a comma separated list of percents, like 90,100"
+	case "ltfield":
+		return fmt.Sprintf("must be lower than %s", fieldName(data, fieldError.Param()))
+	case "gtfield":
+		return fmt.Sprintf("must be greater than %s", fieldName(data, fieldError.Param()))
+	case "nefield":
+		return fmt.Sprintf("must be different from %s", fieldName(data, fieldError.Param()))
+	default:
+		return fmt.Sprintf("is invalid (%s)", fieldError.Tag())
+	}
+}
+
+// fieldName return the attribute name of struct field
+func fieldName(data interface{}, name string) string {
+	t := reflect.TypeOf(data)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if field, ok := t.FieldByName(name); ok {
+		return attributeName(field)
+	}
+	return name
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validationAttributes(err error) map[string]string {
+	attributes := map[string]string{}
+	if validationErrors, ok := err.(ValidationErrors); ok {
+		for _, validationError := range validationErrors {
+			attributes[validationError.Attribute] = validationError.Message
+		}
+	}
+	return attributes
+}
+
+func TestValidateDFPConfig(t *testing.T) {
+	config := &DFPConfig{
+		ForceWashingDuration:           180,
+		ForceWashingDurationWhenFrozen: 120,
+		TemperatureThresholdWhenFrozen: -5,
+		WaitTimeBetweenWashing:         30,
+		WashingDuration:                8,
+		StartWashingPumpBeforeWashing:  2,
+		WaitTimeBeforeUnsetSecurity:    7200,
+		TemperatureSensorPolling:       60,
+	}
+	assert.NoError(t, Validate(config))
+
+	// Ranges and cross fields
+	config.WashingDuration = -1
+	config.TemperatureThresholdWhenFrozen = -100
+	config.WaitTimeBetweenWashing = 150
+	err := Validate(config)
+	assert.Error(t, err)
+	attributes := validationAttributes(err)
+	assert.Len(t, attributes, 3)
+	assert.Equal(t, "must be greater than or equal to 1", attributes["washing_duration"])
+	assert.Equal(t, "must be greater than or equal to -50", attributes["temperature_threshold_when_frozen"])
+	assert.Equal(t, "must be lower than force_washing_duration_when_frozen", attributes["wait_time_between_washing"])
+}
+
+func TestValidateTFPConfig(t *testing.T) {
+	config := &TFPConfig{
+		UVC1BlisterMaxTime:     6000,
+		UVC2BlisterMaxTime:     6000,
+		OzoneBlisterMaxTime:    16000,
+		StartTimeWaterfall:     "10:00",
+		StopTimeWaterfall:      "20:00",
+		Mode:                   "none",
+		BlisterAlertThresholds: "90,100",
+	}
+	assert.NoError(t, Validate(config))
+
+	// Blister thresholds are optional
+	config.BlisterAlertThresholds = ""
+	assert.NoError(t, Validate(config))
+
+	// Enum, format and cross fields
+	config.Mode = "bad"
+	config.StartTimeWaterfall = "25:00"
+	config.BlisterAlertThresholds = "90,bad"
+	err := Validate(config)
+	assert.Error(t, err)
+	attributes := validationAttributes(err)
+	assert.Len(t, attributes, 3)
+	assert.Equal(t, "must be one of ozone, uvc, none", attributes["mode"])
+	assert.Equal(t, "must be a time formatted as HH:MM", attributes["start_time_waterfall"])
+	assert.Contains(t, attributes, "blister_alert_thresholds")
+
+	config.Mode = "uvc"
+	config.StartTimeWaterfall = "20:00"
+	config.BlisterAlertThresholds = "90"
+	attributes = validationAttributes(Validate(config))
+	assert.Equal(t, "must be different from start_time_waterfall", attributes["stop_time_waterfall"])
+}
+
+func TestValidateTankConfig(t *testing.T) {
+	config := &TankConfig{
+		Name:                   "pond",
+		Depth:                  200,
+		SensorHeight:           20,
+		LiterPerCm:             50,
+		LowLevelThreshold:      20,
+		HighLevelThreshold:     95,
+		LevelHysteresis:        5,
+		DryRunThreshold:        10,
+		DryRunRestartThreshold: 20,
+	}
+	assert.NoError(t, Validate(config))
+
+	// Alerts and dry run are disabled
+	config.LowLevelThreshold = 0
+	config.HighLevelThreshold = 0
+	config.DryRunThreshold = 0
+	config.DryRunRestartThreshold = 0
+	assert.NoError(t, Validate(config))
+
+	// Ranges and cross fields
+	config.Depth = 0
+	config.LevelHysteresis = 101
+	config.LowLevelThreshold = 50
+	config.HighLevelThreshold = 40
+	config.DryRunThreshold = 30
+	config.DryRunRestartThreshold = 30
+	err := Validate(config)
+	assert.Error(t, err)
+	attributes := validationAttributes(err)
+	assert.Len(t, attributes, 4)
+	assert.Equal(t, "must be greater than or equal to 1", attributes["depth"])
+	assert.Equal(t, "must be lower than or equal to 100", attributes["level_hysteresis"])
+	assert.Equal(t, "must be greater than low_level_threshold", attributes["high_level_threshold"])
+	assert.Equal(t, "must be greater than dry_run_threshold", attributes["dry_run_restart_threshold"])
+	assert.Contains(t, err.Error(), "depth must be greater than or equal to 1")
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebsocketMessage is the message pushed to websocket clients
+type WebsocketMessage struct {
+
+	// Topic is the kind of data (state, io, tank or board)
+	Topic string `json:"topic"`
+
+	// Board is the board name that produce the data
+	Board string `json:"board"`
+
+	// Event is the gobot event that trigger the message, empty when it's detected by polling
+	Event string `json:"event,omitempty"`
+
+	// Timestamp is the date when message is produced
+	Timestamp time.Time `json:"timestamp"`
+
+	// Data is the snapshot of board data
+	Data interface{} `json:"data"`
+}
+
+func (h *WebsocketMessage) String() string {
+	str, err := json.Marshal(h)
+	if err != nil {
+		panic(err)
+	}
+	return string(str)
+}
+
+// WebsocketRequest is the message sent by websocket clients to manage subscription
+type WebsocketRequest struct {
+
+	// Action is the action to do, only subscribe is supported
+	Action string `json:"action"`
+
+	// Boards is the list of board name to follow, all boards if empty
+	Boards []string `json:"boards"`
+
+	// Topics is the list of topic to follow, all topics if empty
+	Topics []string `json:"topics"`
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/mqtt"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/pkg/errors"
+)
+
+type pahoClient struct {
+	client    paho.Client
+	timeout   time.Duration
+	onConnect func()
+	sync.Mutex
+}
+
+// NewPahoClient create new MQTT client with paho library
+// The will message publish `offline` on availability topic when connection is lost
+// The session is not kept by broker, so the OnConnect handler must subscribe again after reconnect
+func NewPahoClient(url string, clientID string, username string, password string, availabilityTopic string, timeout time.Duration) mqtt.Client {
+	h := &pahoClient{
+		timeout: timeout,
+	}
+
+	options := paho.NewClientOptions().
+		AddBroker(url).
+		SetClientID(clientID).
+		SetUsername(username).
+		SetPassword(password).
+		SetAutoReconnect(true).
+		SetCleanSession(true).
+		// Handlers run commands that take time, they must not block the acknowledge of publish
+		SetOrderMatters(false).
+		SetWill(availabilityTopic, mqtt.PayloadOffline, 1, true).
+		// Paho call it on its own goroutine
+		SetOnConnectHandler(func(c paho.Client) {
+			h.Lock()
+			handler := h.onConnect
+			h.Unlock()
+			if handler != nil {
+				handler()
+			}
+		})
+	h.client = paho.NewClient(options)
+
+	return h
+}
+
+// Connect open the connection on broker
+func (h *pahoClient) Connect() error {
+	return h.wait(h.client.Connect())
+}
+
+// Disconnect close the connection
+func (h *pahoClient) Disconnect() {
+	h.client.Disconnect(uint(h.timeout.Milliseconds()))
+}
+
+// Publish send payload on topic
+func (h *pahoClient) Publish(topic string, retained bool, payload []byte) error {
+	return h.wait(h.client.Publish(topic, 1, retained, payload))
+}
+
+// Subscribe call handler for each message received on topic
+func (h *pahoClient) Subscribe(topic string, handler mqtt.MessageHandler) error {
+	return h.wait(h.client.Subscribe(topic, 1, func(c paho.Client, msg paho.Message) {
+		handler(msg.Topic(), msg.Payload())
+	}))
+}
+
+// OnConnect set the handler called each time connection is established, also after reconnect
+func (h *pahoClient) OnConnect(handler func()) {
+	h.Lock()
+	defer h.Unlock()
+	h.onConnect = handler
+}
+
+func (h *pahoClient) wait(token paho.Token) error {
+	if !token.WaitTimeout(h.timeout) {
+		return errors.New("Timeout when wait MQTT broker")
+	}
+
+	return token.Error()
+}
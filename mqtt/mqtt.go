@@ -0,0 +1,56 @@
+package mqtt
+
+import (
+	"context"
+)
+
+const (
+	// PayloadOn is the payload used by Home Assistant to turn on switch
+	PayloadOn = "ON"
+
+	// PayloadOff is the payload used by Home Assistant to turn off switch
+	PayloadOff = "OFF"
+
+	// PayloadPress is the payload used by Home Assistant when press button
+	PayloadPress = "PRESS"
+
+	// PayloadOnline is the payload of availability topic when bridge is connected
+	PayloadOnline = "online"
+
+	// PayloadOffline is the payload of availability topic when bridge is disconnected
+	PayloadOffline = "offline"
+)
+
+// MessageHandler is called when message is received on subscribed topic
+type MessageHandler func(topic string, payload []byte)
+
+// Client is the MQTT client interface
+// It permit to use stand-in broker on tests
+type Client interface {
+	// Connect open the connection on broker
+	Connect() error
+
+	// Disconnect close the connection
+	Disconnect()
+
+	// Publish send payload on topic
+	Publish(topic string, retained bool, payload []byte) error
+
+	// Subscribe call handler for each message received on topic
+	Subscribe(topic string, handler MessageHandler) error
+
+	// OnConnect set the handler called each time connection is established, also after reconnect
+	OnConnect(handler func())
+}
+
+// Usecase is the MQTT bridge interface
+type Usecase interface {
+	// Start connect on broker, publish discovery payloads and start to publish states
+	Start(ctx context.Context) error
+
+	// StartOnBackground start the bridge on background, and try again while it failed
+	StartOnBackground(ctx context.Context)
+
+	// Stop publish states and disconnect from broker
+	Stop(ctx context.Context)
+}
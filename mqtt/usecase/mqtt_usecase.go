@@ -0,0 +1,558 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/dfp"
+	"github.com/disaster37/gobot-fat/mqtt"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/tfp"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultRetryInterval is the time to wait before try again to start bridge
+	defaultRetryInterval = 10 * time.Second
+
+	componentSwitch = "switch"
+	componentSensor = "sensor"
+	componentButton = "button"
+
+	topicState = "state"
+	topicIO    = "io"
+)
+
+// entity is one Home Assistant entity
+type entity struct {
+	component   string
+	board       string
+	key         string
+	name        string
+	topic       string
+	field       string
+	unit        string
+	deviceClass string
+	command     func(ctx context.Context, payload string) error
+}
+
+type mqttUsecase struct {
+	client          mqtt.Client
+	dfpUsecases     map[string]dfp.Usecase
+	tfpUsecases     map[string]tfp.Usecase
+	tankUsecase     tank.Usecase
+	prefix          string
+	discoveryPrefix string
+	interval        time.Duration
+	timeout         time.Duration
+	lastPayloads    map[string]string
+	discoveredTanks map[string]bool
+	chStop          chan bool
+	chRefresh       chan struct{}
+	chRetry         chan bool
+	retryInterval   time.Duration
+	isStarted       bool
+	muPublish       sync.Mutex
+	sync.Mutex
+}
+
+// NewMQTTUsecase will create new mqttUsecase object of mqtt.Usecase interface
+// It publish DFP, TFP and tank states on `prefix` topics and Home Assistant discovery payloads on `discoveryPrefix`.
+// dfpUsecases and tfpUsecases are the usecases of each board by board name, they can be empty when board is disabled.
+// tankUsecase can be nil when there are no tank.
+func NewMQTTUsecase(client mqtt.Client, dfpUsecases map[string]dfp.Usecase, tfpUsecases map[string]tfp.Usecase, tankUsecase tank.Usecase, prefix string, discoveryPrefix string, interval time.Duration, timeout time.Duration) mqtt.Usecase {
+	return &mqttUsecase{
+		client:          client,
+		dfpUsecases:     dfpUsecases,
+		tfpUsecases:     tfpUsecases,
+		tankUsecase:     tankUsecase,
+		prefix:          prefix,
+		discoveryPrefix: discoveryPrefix,
+		interval:        interval,
+		timeout:         timeout,
+		lastPayloads:    make(map[string]string),
+		discoveredTanks: make(map[string]bool),
+		chRefresh:       make(chan struct{}, 1),
+		retryInterval:   defaultRetryInterval,
+	}
+}
+
+// Start connect on broker, publish discovery payloads and start to publish states
+func (h *mqttUsecase) Start(ctx context.Context) error {
+	h.Lock()
+	if h.isStarted {
+		h.Unlock()
+		return nil
+	}
+	h.Unlock()
+
+	h.client.OnConnect(h.handleReconnect)
+	if err := h.client.Connect(); err != nil {
+		return errors.Wrap(err, "Error when connect on MQTT broker")
+	}
+	if err := h.announce(); err != nil {
+		h.client.Disconnect()
+		return err
+	}
+
+	h.Lock()
+	h.isStarted = true
+	h.chStop = make(chan bool)
+	chStop := h.chStop
+	h.Unlock()
+
+	h.publishStates(ctx)
+
+	go func() {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				h.Stop(context.Background())
+				return
+			case <-chStop:
+				return
+			case <-ticker.C:
+				h.publishStates(ctx)
+			case <-h.chRefresh:
+				h.publishStates(ctx)
+			}
+		}
+	}()
+
+	log.Info("MQTT bridge started")
+
+	return nil
+}
+
+// StartOnBackground start the bridge on background, and try again while it failed
+// It stop to try when context is canceled or bridge is stopped
+func (h *mqttUsecase) StartOnBackground(ctx context.Context) {
+	h.Lock()
+	if h.chRetry != nil {
+		h.Unlock()
+		return
+	}
+	h.chRetry = make(chan bool)
+	chRetry := h.chRetry
+	h.Unlock()
+
+	go func() {
+		for {
+			err := h.Start(ctx)
+			if err == nil {
+				// Stop called during start
+				select {
+				case <-chRetry:
+					h.Stop(ctx)
+				default:
+				}
+				return
+			}
+			log.Errorf("Failed to start MQTT bridge, try again in %s: %s", h.retryInterval, err.Error())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-chRetry:
+				return
+			case <-time.After(h.retryInterval):
+			}
+		}
+	}()
+}
+
+// announce publish online on availability topic and discovery payloads, then subscribe on command topics
+func (h *mqttUsecase) announce() error {
+	if err := h.client.Publish(h.availabilityTopic(), true, []byte(mqtt.PayloadOnline)); err != nil {
+		return err
+	}
+
+	for _, e := range h.entities() {
+		if err := h.publishDiscovery(e); err != nil {
+			return err
+		}
+		if e.command != nil {
+			if err := h.client.Subscribe(h.commandTopic(e), h.handleCommand(e)); err != nil {
+				return errors.Wrapf(err, "Error when subscribe on %s", h.commandTopic(e))
+			}
+		}
+	}
+
+	return nil
+}
+
+// handleReconnect announce again the bridge after client reconnect
+// The subscriptions are lost with the session, and the will message set availability to offline.
+// The states and tanks are published again on next refresh.
+func (h *mqttUsecase) handleReconnect() {
+	h.Lock()
+	isStarted := h.isStarted
+	h.Unlock()
+	if !isStarted {
+		return
+	}
+
+	log.Info("MQTT bridge reconnected")
+	if err := h.announce(); err != nil {
+		log.Errorf("Error when announce MQTT bridge after reconnect: %s", err.Error())
+		return
+	}
+
+	h.muPublish.Lock()
+	h.discoveredTanks = make(map[string]bool)
+	h.muPublish.Unlock()
+	h.Lock()
+	h.lastPayloads = make(map[string]string)
+	h.Unlock()
+
+	select {
+	case h.chRefresh <- struct{}{}:
+	default:
+	}
+}
+
+// Stop publish states and disconnect from broker
+// It also stop to try to start the bridge
+func (h *mqttUsecase) Stop(ctx context.Context) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.chRetry != nil {
+		close(h.chRetry)
+		h.chRetry = nil
+	}
+
+	if !h.isStarted {
+		return
+	}
+
+	close(h.chStop)
+	h.isStarted = false
+
+	if err := h.client.Publish(h.availabilityTopic(), true, []byte(mqtt.PayloadOffline)); err != nil {
+		log.Errorf("Error when publish MQTT availability: %s", err.Error())
+	}
+	h.client.Disconnect()
+
+	log.Info("MQTT bridge stopped")
+}
+
+// entities return the entities of each DFP and TFP
+// Tank entities are discovered when publish states, because of tanks are read from tank usecase
+func (h *mqttUsecase) entities() []*entity {
+	entities := make([]*entity, 0)
+
+	for _, name := range sortedNames(h.dfpUsecases) {
+		entities = append(entities, dfpEntities(name, h.dfpUsecases[name])...)
+	}
+	for _, name := range sortedNames(h.tfpUsecases) {
+		entities = append(entities, tfpEntities(name, h.tfpUsecases[name])...)
+	}
+
+	return entities
+}
+
+// dfpEntities return the switches, button and sensors of DFP
+func dfpEntities(name string, dfpUsecase dfp.Usecase) []*entity {
+	label := strings.ToUpper(name)
+	newSwitch := func(key, title, topic, field string, f func(ctx context.Context, status bool) error) *entity {
+		return &entity{component: componentSwitch, board: name, key: key, name: fmt.Sprintf("%s %s", label, title), topic: topic, field: field, command: switchCommand(f)}
+	}
+	newSensor := func(key, title, field string) *entity {
+		return &entity{component: componentSensor, board: name, key: key, name: fmt.Sprintf("%s %s", label, title), topic: topicState, field: field, unit: "°C", deviceClass: "temperature"}
+	}
+
+	return []*entity{
+		newSwitch("auto", "auto", topicState, "is_running", func(ctx context.Context, status bool) error {
+			if status {
+				return dfpUsecase.Start(ctx)
+			}
+			return dfpUsecase.Stop(ctx)
+		}),
+		newSwitch("drum", "drum", topicIO, "drum_relay", dfpUsecase.ManualDrum),
+		newSwitch("pump", "pump", topicIO, "pump_relay", dfpUsecase.ManualPump),
+		newSwitch("security", "security", topicState, "is_security", dfpUsecase.Security),
+		newSwitch("disable_security", "disable security", topicState, "is_disable_security", dfpUsecase.DisableSecurity),
+		newSwitch("emergency_stop", "emergency stop", topicState, "is_emmergency_stopped", dfpUsecase.EmergencyStop),
+		{component: componentButton, board: name, key: "wash", name: fmt.Sprintf("%s wash", label), command: func(ctx context.Context, payload string) error {
+			if payload != mqtt.PayloadPress {
+				return errors.Errorf("Payload %s not supported", payload)
+			}
+			return dfpUsecase.Wash(ctx)
+		}},
+		newSensor("water_temperature", "water temperature", "water_tempareture"),
+		newSensor("ambient_temperature", "ambient temperature", "ambient_tempareture"),
+	}
+}
+
+// tfpEntities return the switches, button and sensors of TFP
+func tfpEntities(name string, tfpUsecase tfp.Usecase) []*entity {
+	label := strings.ToUpper(name)
+	newSwitch := func(key, title, topic, field string, f func(ctx context.Context, status bool) error) *entity {
+		return &entity{component: componentSwitch, board: name, key: key, name: fmt.Sprintf("%s %s", label, title), topic: topic, field: field, command: switchCommand(f)}
+	}
+	newSensor := func(key, title, field string) *entity {
+		return &entity{component: componentSensor, board: name, key: key, name: fmt.Sprintf("%s %s", label, title), topic: topicState, field: field, unit: "h", deviceClass: "duration"}
+	}
+
+	return []*entity{
+		newSwitch("pond_pump", "pond pump", topicIO, "pond_pump_relay", tfpUsecase.PondPump),
+		newSwitch("waterfall_pump", "waterfall pump", topicIO, "waterfall_pump_relay", tfpUsecase.WaterfallPump),
+		newSwitch("uvc1", "UVC1", topicIO, "uvc1_relay", tfpUsecase.UVC1),
+		newSwitch("uvc2", "UVC2", topicIO, "uvc2_relay", tfpUsecase.UVC2),
+		newSwitch("pond_bubble", "pond bubble", topicIO, "pond_bubble", tfpUsecase.PondBubble),
+		newSwitch("filter_bubble", "filter bubble", topicIO, "filter_bubble", tfpUsecase.FilterBubble),
+		newSwitch("waterfall_auto", "waterfall auto", topicState, "is_waterfall_auto", tfpUsecase.WaterfallAuto),
+		{component: componentButton, board: name, key: "bacterium_introduced", name: fmt.Sprintf("%s bacterium introduced", label), command: func(ctx context.Context, payload string) error {
+			if payload != mqtt.PayloadPress {
+				return errors.Errorf("Payload %s not supported", payload)
+			}
+			return tfpUsecase.BacteriumIntroduced(ctx)
+		}},
+		newSensor("uvc1_blister", "UVC1 blister", "uvc1_blister_nb_hour"),
+		newSensor("uvc2_blister", "UVC2 blister", "uvc2_blister_nb_hour"),
+		newSensor("ozone_blister", "ozone blister", "ozone_blister_nb_hour"),
+	}
+}
+
+// sortedNames return the board names in alphabetic order, to always publish entities in same order
+func sortedNames[T any](usecases map[string]T) []string {
+	names := make([]string, 0, len(usecases))
+	for name := range usecases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// tankEntities return the sensors of tank
+func tankEntities(name string) []*entity {
+	return []*entity{
+		{component: componentSensor, board: name, key: "level", name: fmt.Sprintf("%s level", name), field: "level", unit: "cm", deviceClass: "distance"},
+		{component: componentSensor, board: name, key: "volume", name: fmt.Sprintf("%s volume", name), field: "volume", unit: "L", deviceClass: "volume_storage"},
+		{component: componentSensor, board: name, key: "percent", name: fmt.Sprintf("%s percent", name), field: "percent", unit: "%"},
+	}
+}
+
+// publishStates publish DFP, TFP and tanks states when they change
+func (h *mqttUsecase) publishStates(ctx context.Context) {
+	h.muPublish.Lock()
+	defer h.muPublish.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	for _, name := range sortedNames(h.dfpUsecases) {
+		dfpUsecase := h.dfpUsecases[name]
+		if state, err := dfpUsecase.GetState(ctx); err != nil {
+			log.Errorf("Error when get DFP %s state for MQTT: %s", name, err.Error())
+		} else {
+			h.publishState(h.stateTopic(name, topicState), state)
+		}
+		if io, err := dfpUsecase.GetIO(ctx); err != nil {
+			log.Errorf("Error when get DFP %s IO for MQTT: %s", name, err.Error())
+		} else {
+			h.publishState(h.stateTopic(name, topicIO), io)
+		}
+	}
+
+	for _, name := range sortedNames(h.tfpUsecases) {
+		tfpUsecase := h.tfpUsecases[name]
+		if state, err := tfpUsecase.GetState(ctx); err != nil {
+			log.Errorf("Error when get TFP %s state for MQTT: %s", name, err.Error())
+		} else {
+			h.publishState(h.stateTopic(name, topicState), state)
+		}
+		if io, err := tfpUsecase.GetIO(ctx); err != nil {
+			log.Errorf("Error when get TFP %s IO for MQTT: %s", name, err.Error())
+		} else {
+			h.publishState(h.stateTopic(name, topicIO), io)
+		}
+	}
+
+	if h.tankUsecase != nil {
+		tanks, err := h.tankUsecase.Tanks(ctx)
+		if err != nil {
+			log.Errorf("Error when get tanks for MQTT: %s", err.Error())
+		}
+		for name, data := range tanks {
+			if data == nil {
+				continue
+			}
+			if !h.discoveredTanks[name] {
+				for _, e := range tankEntities(name) {
+					if err := h.publishDiscovery(e); err != nil {
+						log.Errorf("Error when publish MQTT discovery for tank %s: %s", name, err.Error())
+					}
+				}
+				h.discoveredTanks[name] = true
+			}
+			h.publishState(h.stateTopic(name, ""), data)
+		}
+
+		// Tanks deleted over API are removed from Home Assistant
+		// The list is partial on error, so deleted tanks are only checked when all tanks are read
+		if err == nil {
+			for name := range h.discoveredTanks {
+				if _, ok := tanks[name]; !ok {
+					h.unpublishTank(name)
+				}
+			}
+		}
+	}
+}
+
+// unpublishTank remove the discovery payloads and the retained state of tank
+func (h *mqttUsecase) unpublishTank(name string) {
+	for _, e := range tankEntities(name) {
+		if err := h.client.Publish(h.discoveryTopic(e), true, []byte{}); err != nil {
+			log.Errorf("Error when remove MQTT discovery for tank %s: %s", name, err.Error())
+			return
+		}
+	}
+	topic := h.stateTopic(name, "")
+	if err := h.client.Publish(topic, true, []byte{}); err != nil {
+		log.Errorf("Error when remove MQTT state for tank %s: %s", name, err.Error())
+		return
+	}
+
+	h.Lock()
+	delete(h.lastPayloads, topic)
+	h.Unlock()
+	delete(h.discoveredTanks, name)
+}
+
+// publishState publish data as JSON only if it change since the last time
+func (h *mqttUsecase) publishState(topic string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Errorf("Error when marshal MQTT state for %s: %s", topic, err.Error())
+		return
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	if h.lastPayloads[topic] == string(payload) {
+		return
+	}
+
+	if err = h.client.Publish(topic, true, payload); err != nil {
+		log.Errorf("Error when publish MQTT state on %s: %s", topic, err.Error())
+		return
+	}
+	h.lastPayloads[topic] = string(payload)
+}
+
+// publishDiscovery publish the Home Assistant discovery payload of entity
+func (h *mqttUsecase) publishDiscovery(e *entity) error {
+	discovery := map[string]interface{}{
+		"name":               e.name,
+		"unique_id":          uniqueID(e),
+		"availability_topic": h.availabilityTopic(),
+		"device": map[string]interface{}{
+			"identifiers":  []string{fmt.Sprintf("gobot_fat_%s", e.board)},
+			"name":         e.board,
+			"manufacturer": "gobot-fat",
+		},
+	}
+
+	switch e.component {
+	case componentSwitch:
+		discovery["state_topic"] = h.stateTopic(e.board, e.topic)
+		discovery["value_template"] = fmt.Sprintf("{{ '%s' if value_json.%s else '%s' }}", mqtt.PayloadOn, e.field, mqtt.PayloadOff)
+		discovery["command_topic"] = h.commandTopic(e)
+		discovery["payload_on"] = mqtt.PayloadOn
+		discovery["payload_off"] = mqtt.PayloadOff
+	case componentSensor:
+		discovery["state_topic"] = h.stateTopic(e.board, e.topic)
+		discovery["value_template"] = fmt.Sprintf("{{ value_json.%s }}", e.field)
+		discovery["unit_of_measurement"] = e.unit
+		if e.deviceClass != "" {
+			discovery["device_class"] = e.deviceClass
+		}
+	case componentButton:
+		discovery["command_topic"] = h.commandTopic(e)
+		discovery["payload_press"] = mqtt.PayloadPress
+	}
+
+	payload, err := json.Marshal(discovery)
+	if err != nil {
+		return err
+	}
+
+	topic := h.discoveryTopic(e)
+	if err = h.client.Publish(topic, true, payload); err != nil {
+		return errors.Wrapf(err, "Error when publish discovery on %s", topic)
+	}
+
+	return nil
+}
+
+// handleCommand return the handler that call usecase when receive command
+func (h *mqttUsecase) handleCommand(e *entity) mqtt.MessageHandler {
+	return func(topic string, payload []byte) {
+		log.Debugf("Receive MQTT command %s on %s", string(payload), topic)
+
+		ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+		defer cancel()
+
+		if err := e.command(ctx, string(payload)); err != nil {
+			log.Errorf("Error when run MQTT command on %s: %s", topic, err.Error())
+			return
+		}
+
+		// Publish new state without waiting the next interval
+		// It's published outside the handler, because of client can't acknowledge publish while handler run
+		select {
+		case h.chRefresh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// switchCommand convert ON / OFF payload to status
+func switchCommand(f func(ctx context.Context, status bool) error) func(ctx context.Context, payload string) error {
+	return func(ctx context.Context, payload string) error {
+		switch payload {
+		case mqtt.PayloadOn:
+			return f(ctx, true)
+		case mqtt.PayloadOff:
+			return f(ctx, false)
+		default:
+			return errors.Errorf("Payload %s not supported", payload)
+		}
+	}
+}
+
+func (h *mqttUsecase) discoveryTopic(e *entity) string {
+	return fmt.Sprintf("%s/%s/%s/config", h.discoveryPrefix, e.component, uniqueID(e))
+}
+
+func (h *mqttUsecase) availabilityTopic() string {
+	return fmt.Sprintf("%s/status", h.prefix)
+}
+
+func uniqueID(e *entity) string {
+	return fmt.Sprintf("gobot_fat_%s_%s", e.board, e.key)
+}
+
+func (h *mqttUsecase) stateTopic(board string, topic string) string {
+	if topic == "" {
+		return fmt.Sprintf("%s/%s", h.prefix, board)
+	}
+	return fmt.Sprintf("%s/%s/%s", h.prefix, board, topic)
+}
+
+func (h *mqttUsecase) commandTopic(e *entity) string {
+	return fmt.Sprintf("%s/%s/%s/set", h.prefix, e.board, e.key)
+}
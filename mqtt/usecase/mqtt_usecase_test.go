@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/mock"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/mqtt"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/tfp"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTFPUsecase struct {
+	tfp.Usecase
+	state models.TFPState
+	io    models.TFPIO
+	sync.Mutex
+}
+
+func (h *fakeTFPUsecase) UVC1(ctx context.Context, status bool) error {
+	h.Lock()
+	defer h.Unlock()
+	h.io.UVC1Relay = status
+	return nil
+}
+func (h *fakeTFPUsecase) GetState(ctx context.Context) (models.TFPState, error) {
+	h.Lock()
+	defer h.Unlock()
+	return h.state, nil
+}
+func (h *fakeTFPUsecase) GetIO(ctx context.Context) (models.TFPIO, error) {
+	h.Lock()
+	defer h.Unlock()
+	return h.io, nil
+}
+
+type fakeTankUsecase struct {
+	tank *models.Tank
+	sync.Mutex
+}
+
+func (h *fakeTankUsecase) SetTank(tank *models.Tank) {
+	h.Lock()
+	defer h.Unlock()
+	h.tank = tank
+}
+func (h *fakeTankUsecase) Tanks(ctx context.Context) (map[string]*models.Tank, error) {
+	h.Lock()
+	defer h.Unlock()
+	if h.tank == nil {
+		return map[string]*models.Tank{}, nil
+	}
+	return map[string]*models.Tank{h.tank.ID: h.tank}, nil
+}
+func (h *fakeTankUsecase) Tank(ctx context.Context, name string) (*models.Tank, error) {
+	h.Lock()
+	defer h.Unlock()
+	return h.tank, nil
+}
+func (h *fakeTankUsecase) AddBoard(board tank.Board) {}
+func (h *fakeTankUsecase) Create(ctx context.Context, config *models.TankConfig) error {
+	return nil
+}
+func (h *fakeTankUsecase) Delete(ctx context.Context, id uint) error { return nil }
+func (h *fakeTankUsecase) Load(ctx context.Context) error            { return nil }
+
+func TestMQTTUsecase(t *testing.T) {
+	client := mock.NewMockMQTTClient()
+	tfpU := &fakeTFPUsecase{
+		state: models.TFPState{UVC1BlisterNbHour: 10},
+	}
+	tankU := &fakeTankUsecase{
+		tank: &models.Tank{ID: "tank_pond", Level: 100},
+	}
+	us := NewMQTTUsecase(client, nil, map[string]tfp.Usecase{"tfp": tfpU}, tankU, "gobot-fat", "homeassistant", 10*time.Millisecond, 1*time.Second)
+
+	err := us.Start(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, client.IsConnected)
+	assert.Equal(t, mqtt.PayloadOnline, string(client.Message("gobot-fat/status")))
+
+	// Discovery payloads
+	discovery := make(map[string]interface{})
+	err = json.Unmarshal(client.Message("homeassistant/switch/gobot_fat_tfp_uvc1/config"), &discovery)
+	assert.NoError(t, err)
+	assert.Equal(t, "gobot-fat/tfp/io", discovery["state_topic"])
+	assert.Equal(t, "gobot-fat/tfp/uvc1/set", discovery["command_topic"])
+	assert.Equal(t, "{{ 'ON' if value_json.uvc1_relay else 'OFF' }}", discovery["value_template"])
+	assert.NotEmpty(t, client.Message("homeassistant/sensor/gobot_fat_tfp_uvc1_blister/config"))
+	assert.NotEmpty(t, client.Message("homeassistant/sensor/gobot_fat_tank_pond_level/config"))
+	assert.Empty(t, client.Topics("homeassistant/switch/gobot_fat_dfp"))
+
+	// States
+	tank := &models.Tank{}
+	err = json.Unmarshal(client.Message("gobot-fat/tank_pond"), tank)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, tank.Level)
+
+	// Commands
+	err = client.Publish("gobot-fat/tfp/uvc1/set", false, []byte(mqtt.PayloadOn))
+	assert.NoError(t, err)
+	io := &models.TFPIO{}
+	assert.Eventually(t, func() bool {
+		return json.Unmarshal(client.Message("gobot-fat/tfp/io"), io) == nil && io.UVC1Relay
+	}, 1*time.Second, 5*time.Millisecond)
+
+	// Bad payload is ignored
+	err = client.Publish("gobot-fat/tfp/uvc1/set", false, []byte("bad"))
+	assert.NoError(t, err)
+	err = json.Unmarshal(client.Message("gobot-fat/tfp/io"), io)
+	assert.NoError(t, err)
+	assert.True(t, io.UVC1Relay)
+
+	// State published each interval when change
+	tankU.SetTank(&models.Tank{ID: "tank_pond", Level: 50})
+	time.Sleep(50 * time.Millisecond)
+	err = json.Unmarshal(client.Message("gobot-fat/tank_pond"), tank)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, tank.Level)
+
+	// Deleted tank is removed from Home Assistant
+	tankU.SetTank(nil)
+	assert.Eventually(t, func() bool {
+		return len(client.Topics("homeassistant/sensor/gobot_fat_tank_pond")) == 0 && client.Message("gobot-fat/tank_pond") == nil
+	}, 1*time.Second, 10*time.Millisecond)
+	assert.NotEmpty(t, client.Message("homeassistant/sensor/gobot_fat_tfp_uvc1_blister/config"))
+
+	us.Stop(context.Background())
+	assert.False(t, client.IsConnected)
+	assert.Equal(t, mqtt.PayloadOffline, string(client.Message("gobot-fat/status")))
+}
+
+func TestMQTTCommandNotBlock(t *testing.T) {
+	client := mock.NewMockMQTTClient()
+	client.OrderMatters = true
+	client.Timeout = 500 * time.Millisecond
+	tfpU := &fakeTFPUsecase{}
+	us := NewMQTTUsecase(client, nil, map[string]tfp.Usecase{"tfp": tfpU}, nil, "gobot-fat", "homeassistant", 1*time.Hour, 1*time.Second)
+	err := us.Start(context.Background())
+	assert.NoError(t, err)
+
+	// Handler return without wait publish, and state is published after
+	start := time.Now()
+	err = client.Publish("gobot-fat/tfp/uvc1/set", false, []byte(mqtt.PayloadOn))
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), client.Timeout)
+	assert.Eventually(t, func() bool {
+		io := &models.TFPIO{}
+		_ = json.Unmarshal(client.Message("gobot-fat/tfp/io"), io)
+		return io.UVC1Relay
+	}, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, client.Timeouts())
+
+	us.Stop(context.Background())
+}
+
+func TestMQTTSeveralBoards(t *testing.T) {
+	client := mock.NewMockMQTTClient()
+	tfpU := &fakeTFPUsecase{}
+	tfpShedU := &fakeTFPUsecase{state: models.TFPState{UVC1BlisterNbHour: 20}}
+	us := NewMQTTUsecase(client, nil, map[string]tfp.Usecase{"tfp": tfpU, "tfp_shed": tfpShedU}, nil, "gobot-fat", "homeassistant", 1*time.Hour, 1*time.Second)
+	err := us.Start(context.Background())
+	assert.NoError(t, err)
+
+	// Each TFP has its entities and states
+	discovery := make(map[string]interface{})
+	err = json.Unmarshal(client.Message("homeassistant/switch/gobot_fat_tfp_shed_uvc1/config"), &discovery)
+	assert.NoError(t, err)
+	assert.Equal(t, "TFP_SHED UVC1", discovery["name"])
+	assert.Equal(t, "gobot-fat/tfp_shed/io", discovery["state_topic"])
+	assert.NotEmpty(t, client.Message("homeassistant/switch/gobot_fat_tfp_uvc1/config"))
+	state := &models.TFPState{}
+	err = json.Unmarshal(client.Message("gobot-fat/tfp_shed/state"), state)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), state.UVC1BlisterNbHour)
+
+	// Command is sent to its own TFP
+	err = client.Publish("gobot-fat/tfp_shed/uvc1/set", false, []byte(mqtt.PayloadOn))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		io, _ := tfpShedU.GetIO(context.Background())
+		return io.UVC1Relay
+	}, 1*time.Second, 10*time.Millisecond)
+	io, _ := tfpU.GetIO(context.Background())
+	assert.False(t, io.UVC1Relay)
+
+	us.Stop(context.Background())
+}
+
+func TestMQTTReconnect(t *testing.T) {
+	client := mock.NewMockMQTTClient()
+	tfpU := &fakeTFPUsecase{}
+	us := NewMQTTUsecase(client, nil, map[string]tfp.Usecase{"tfp": tfpU}, nil, "gobot-fat", "homeassistant", 1*time.Hour, 1*time.Second)
+	err := us.Start(context.Background())
+	assert.NoError(t, err)
+
+	// Bridge is online again and commands are subscribed again
+	client.Reconnect("gobot-fat/status")
+	assert.Equal(t, mqtt.PayloadOnline, string(client.Message("gobot-fat/status")))
+	err = client.Publish("gobot-fat/tfp/uvc1/set", false, []byte(mqtt.PayloadOn))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		io := &models.TFPIO{}
+		_ = json.Unmarshal(client.Message("gobot-fat/tfp/io"), io)
+		return io.UVC1Relay
+	}, 1*time.Second, 10*time.Millisecond)
+
+	us.Stop(context.Background())
+}
+
+func TestMQTTStartOnBackground(t *testing.T) {
+	client := mock.NewMockMQTTClient()
+	client.SetConnectError(errors.New("test"))
+	tfpU := &fakeTFPUsecase{}
+	us := NewMQTTUsecase(client, nil, map[string]tfp.Usecase{"tfp": tfpU}, nil, "gobot-fat", "homeassistant", 1*time.Hour, 1*time.Second)
+	us.(*mqttUsecase).retryInterval = 10 * time.Millisecond
+
+	// Start again when broker come back
+	us.StartOnBackground(context.Background())
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, client.Message("gobot-fat/status"))
+	client.SetConnectError(nil)
+	assert.Eventually(t, func() bool {
+		return string(client.Message("gobot-fat/status")) == mqtt.PayloadOnline
+	}, 1*time.Second, 10*time.Millisecond)
+
+	us.Stop(context.Background())
+	assert.Equal(t, mqtt.PayloadOffline, string(client.Message("gobot-fat/status")))
+}
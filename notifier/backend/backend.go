@@ -0,0 +1,97 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/disaster37/gobot-fat/mail"
+	"github.com/disaster37/gobot-fat/notifier"
+	"github.com/pkg/errors"
+)
+
+const (
+	TypeSMTP     = "smtp"
+	TypeWebhook  = "webhook"
+	TypeNtfy     = "ntfy"
+	TypeGotify   = "gotify"
+	TypeTelegram = "telegram"
+)
+
+// NewNotifier create the notifier backend from config
+// The mail client is used by smtp backend
+func NewNotifier(config notifier.Config, mailClient mail.Mail, timeout time.Duration) (notifier.Notifier, error) {
+
+	name := config.Name
+	if name == "" {
+		name = config.Type
+	}
+	client := &http.Client{Timeout: timeout}
+
+	switch config.Type {
+	case TypeSMTP:
+		if mailClient == nil {
+			return nil, errors.Errorf("Notifier %s need mail section", name)
+		}
+		return NewSMTPNotifier(name, mailClient), nil
+	case TypeWebhook:
+		if config.URL == "" {
+			return nil, errors.Errorf("Notifier %s need url", name)
+		}
+		return NewWebhookNotifier(name, config.URL, config.Headers, client), nil
+	case TypeNtfy:
+		if config.URL == "" || config.Topic == "" {
+			return nil, errors.Errorf("Notifier %s need url and topic", name)
+		}
+		return NewNtfyNotifier(name, config.URL, config.Topic, config.Token, client), nil
+	case TypeGotify:
+		if config.URL == "" || config.Token == "" {
+			return nil, errors.Errorf("Notifier %s need url and token", name)
+		}
+		return NewGotifyNotifier(name, config.URL, config.Token, client), nil
+	case TypeTelegram:
+		if config.Token == "" || config.ChatID == "" {
+			return nil, errors.Errorf("Notifier %s need token and chat_id", name)
+		}
+		return NewTelegramNotifier(name, config.URL, config.Token, config.ChatID, client), nil
+	default:
+		return nil, errors.Errorf("Notifier type %s not supported", config.Type)
+	}
+}
+
+// post send the body on url and return error if status code is not 2xx
+func post(ctx context.Context, client *http.Client, url string, contentType string, body []byte, headers map[string]string) error {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("Error when post on %s: %d %s", url, resp.StatusCode, string(b))
+	}
+
+	return nil
+}
+
+// text return the title and message on same string, for backends that not support title
+func text(notification *notifier.Notification) string {
+	if notification.Message == "" {
+		return notification.Title
+	}
+	return fmt.Sprintf("%s\n%s", notification.Title, notification.Message)
+}
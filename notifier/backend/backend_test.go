@@ -0,0 +1,170 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/notifier"
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	path    string
+	query   string
+	headers http.Header
+	body    []byte
+}
+
+func newServer(status int) (*httptest.Server, chan *request) {
+	requests := make(chan *request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- &request{
+			path:    r.URL.Path,
+			query:   r.URL.RawQuery,
+			headers: r.Header,
+			body:    body,
+		}
+		w.WriteHeader(status)
+	}))
+
+	return server, requests
+}
+
+func testNotification() *notifier.Notification {
+	return &notifier.Notification{
+		Source:    "tank_pond",
+		Kind:      "set_dry_run",
+		Severity:  notifier.SeverityCritical,
+		Title:     "Tank is empty",
+		Message:   "Pumps are stopped",
+		Timestamp: time.Now(),
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	server, requests := newServer(http.StatusOK)
+	defer server.Close()
+
+	n := NewWebhookNotifier("hook", server.URL+"/hook", map[string]string{"Authorization": "Bearer secret"}, server.Client())
+	assert.Equal(t, "hook", n.Name())
+	assert.NoError(t, n.Send(context.Background(), testNotification()))
+
+	req := <-requests
+	payload := map[string]any{}
+	assert.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, "/hook", req.path)
+	assert.Equal(t, "Bearer secret", req.headers.Get("Authorization"))
+	assert.Equal(t, "set_dry_run", payload["kind"])
+	assert.Equal(t, "critical", payload["severity"])
+	assert.Equal(t, "Pumps are stopped", payload["message"])
+}
+
+func TestWebhookError(t *testing.T) {
+	server, requests := newServer(http.StatusInternalServerError)
+	defer server.Close()
+
+	n := NewWebhookNotifier("hook", server.URL, nil, server.Client())
+	assert.Error(t, n.Send(context.Background(), testNotification()))
+	<-requests
+}
+
+type fakeMail struct {
+	err   error
+	delay time.Duration
+	title string
+}
+
+func (m *fakeMail) SendEmail(title string, contend string) error {
+	time.Sleep(m.delay)
+	m.title = title
+	return m.err
+}
+
+func TestSMTP(t *testing.T) {
+	mailClient := &fakeMail{}
+	n := NewSMTPNotifier("mail", mailClient)
+	assert.NoError(t, n.Send(context.Background(), testNotification()))
+	assert.Equal(t, "Tank is empty", mailClient.title)
+
+	// Send error is returned
+	mailClient = &fakeMail{err: errors.New("Connection refused")}
+	n = NewSMTPNotifier("mail", mailClient)
+	assert.EqualError(t, n.Send(context.Background(), testNotification()), "Connection refused")
+
+	// Timeout
+	n = NewSMTPNotifier("mail", &fakeMail{delay: 1 * time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, n.Send(ctx, testNotification()), context.DeadlineExceeded)
+}
+
+func TestNtfy(t *testing.T) {
+	server, requests := newServer(http.StatusOK)
+	defer server.Close()
+
+	n := NewNtfyNotifier("push", server.URL+"/", "pond", "secret", server.Client())
+	assert.NoError(t, n.Send(context.Background(), testNotification()))
+
+	req := <-requests
+	assert.Equal(t, "/pond", req.path)
+	assert.Equal(t, "Tank is empty", req.headers.Get("Title"))
+	assert.Equal(t, "5", req.headers.Get("Priority"))
+	assert.Equal(t, "Bearer secret", req.headers.Get("Authorization"))
+	assert.Equal(t, "Pumps are stopped", string(req.body))
+}
+
+func TestGotify(t *testing.T) {
+	server, requests := newServer(http.StatusOK)
+	defer server.Close()
+
+	n := NewGotifyNotifier("push", server.URL, "secret", server.Client())
+	assert.NoError(t, n.Send(context.Background(), testNotification()))
+
+	req := <-requests
+	payload := map[string]any{}
+	assert.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, "/message", req.path)
+	assert.Equal(t, "token=secret", req.query)
+	assert.Equal(t, "Tank is empty", payload["title"])
+	assert.Equal(t, float64(8), payload["priority"])
+}
+
+func TestTelegram(t *testing.T) {
+	server, requests := newServer(http.StatusOK)
+	defer server.Close()
+
+	n := NewTelegramNotifier("chat", server.URL, "123:abc", "42", server.Client())
+	assert.NoError(t, n.Send(context.Background(), testNotification()))
+
+	req := <-requests
+	payload := map[string]string{}
+	assert.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, "/bot123:abc/sendMessage", req.path)
+	assert.Equal(t, "42", payload["chat_id"])
+	assert.Equal(t, "Tank is empty\nPumps are stopped", payload["text"])
+}
+
+func TestNewNotifier(t *testing.T) {
+
+	// Bad type
+	_, err := NewNotifier(notifier.Config{Type: "bad"}, nil, 1*time.Second)
+	assert.Error(t, err)
+
+	// Missing settings
+	_, err = NewNotifier(notifier.Config{Type: TypeWebhook}, nil, 1*time.Second)
+	assert.Error(t, err)
+	_, err = NewNotifier(notifier.Config{Type: TypeSMTP}, nil, 1*time.Second)
+	assert.Error(t, err)
+
+	// Name is type by default
+	n, err := NewNotifier(notifier.Config{Type: TypeTelegram, Token: "123:abc", ChatID: "42"}, nil, 1*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, TypeTelegram, n.Name())
+}
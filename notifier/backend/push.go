@@ -0,0 +1,116 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/disaster37/gobot-fat/notifier"
+)
+
+type ntfyNotifier struct {
+	name   string
+	url    string
+	topic  string
+	token  string
+	client *http.Client
+}
+
+type gotifyNotifier struct {
+	name   string
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewNtfyNotifier push notifications on ntfy topic
+// The token is optional, it's needed when topic is protected
+func NewNtfyNotifier(name string, url string, topic string, token string, client *http.Client) notifier.Notifier {
+	return &ntfyNotifier{
+		name:   name,
+		url:    strings.TrimSuffix(url, "/"),
+		topic:  topic,
+		token:  token,
+		client: client,
+	}
+}
+
+// Name return the notifier name
+func (h *ntfyNotifier) Name() string {
+	return h.name
+}
+
+// Send push the notification on topic, with title and priority headers
+func (h *ntfyNotifier) Send(ctx context.Context, notification *notifier.Notification) error {
+	headers := map[string]string{
+		"Title":    notification.Title,
+		"Priority": ntfyPriority(notification.Severity),
+		"Tags":     notification.Kind,
+	}
+	if h.token != "" {
+		headers["Authorization"] = fmt.Sprintf("Bearer %s", h.token)
+	}
+
+	message := notification.Message
+	if message == "" {
+		message = notification.Title
+	}
+
+	return post(ctx, h.client, fmt.Sprintf("%s/%s", h.url, h.topic), "text/plain", []byte(message), headers)
+}
+
+// NewGotifyNotifier push notifications on gotify with application token
+func NewGotifyNotifier(name string, url string, token string, client *http.Client) notifier.Notifier {
+	return &gotifyNotifier{
+		name:   name,
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		client: client,
+	}
+}
+
+// Name return the notifier name
+func (h *gotifyNotifier) Name() string {
+	return h.name
+}
+
+// Send push the notification on gotify message API
+func (h *gotifyNotifier) Send(ctx context.Context, notification *notifier.Notification) error {
+	body, err := json.Marshal(map[string]any{
+		"title":    notification.Title,
+		"message":  text(notification),
+		"priority": gotifyPriority(notification.Severity),
+	})
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, h.client, fmt.Sprintf("%s/message?token=%s", h.url, url.QueryEscape(h.token)), "application/json", body, nil)
+}
+
+// ntfyPriority convert severity to ntfy priority, from 1 to 5
+func ntfyPriority(severity string) string {
+	switch severity {
+	case notifier.SeverityCritical:
+		return "5"
+	case notifier.SeverityWarning:
+		return "4"
+	default:
+		return "3"
+	}
+}
+
+// gotifyPriority convert severity to gotify priority, from 0 to 10
+func gotifyPriority(severity string) int {
+	switch severity {
+	case notifier.SeverityCritical:
+		return 8
+	case notifier.SeverityWarning:
+		return 5
+	default:
+		return 2
+	}
+}
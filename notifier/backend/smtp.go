@@ -0,0 +1,42 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/disaster37/gobot-fat/mail"
+	"github.com/disaster37/gobot-fat/notifier"
+)
+
+type smtpNotifier struct {
+	name       string
+	mailClient mail.Mail
+}
+
+// NewSMTPNotifier send notifications by email
+func NewSMTPNotifier(name string, mailClient mail.Mail) notifier.Notifier {
+	return &smtpNotifier{
+		name:       name,
+		mailClient: mailClient,
+	}
+}
+
+// Name return the notifier name
+func (h *smtpNotifier) Name() string {
+	return h.name
+}
+
+// Send send the notification by email
+// It return the send error, or the context error if email is not sent before timeout
+func (h *smtpNotifier) Send(ctx context.Context, notification *notifier.Notification) error {
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- h.mailClient.SendEmail(notification.Title, notification.Message)
+	}()
+
+	select {
+	case err := <-chErr:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
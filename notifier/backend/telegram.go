@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/disaster37/gobot-fat/notifier"
+)
+
+// DefaultTelegramURL is the Telegram bot API
+const DefaultTelegramURL = "https://api.telegram.org"
+
+type telegramNotifier struct {
+	name   string
+	url    string
+	token  string
+	chatID string
+	client *http.Client
+}
+
+// NewTelegramNotifier send notifications on chat with bot
+// The url can be empty to use the Telegram bot API, or set to use compatible API
+func NewTelegramNotifier(name string, url string, token string, chatID string, client *http.Client) notifier.Notifier {
+	if url == "" {
+		url = DefaultTelegramURL
+	}
+
+	return &telegramNotifier{
+		name:   name,
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		chatID: chatID,
+		client: client,
+	}
+}
+
+// Name return the notifier name
+func (h *telegramNotifier) Name() string {
+	return h.name
+}
+
+// Send post the notification as message on chat
+func (h *telegramNotifier) Send(ctx context.Context, notification *notifier.Notification) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": h.chatID,
+		"text":    text(notification),
+	})
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, h.client, fmt.Sprintf("%s/bot%s/sendMessage", h.url, h.token), "application/json", body, nil)
+}
@@ -0,0 +1,60 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/disaster37/gobot-fat/notifier"
+)
+
+type webhookNotifier struct {
+	name    string
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// webhookPayload is the JSON body posted on webhook
+type webhookPayload struct {
+	Source    string    `json:"source"`
+	Kind      string    `json:"kind"`
+	Severity  string    `json:"severity"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewWebhookNotifier post notifications as JSON on url
+// Headers can be used to authenticate, like `Authorization`
+func NewWebhookNotifier(name string, url string, headers map[string]string, client *http.Client) notifier.Notifier {
+	return &webhookNotifier{
+		name:    name,
+		url:     url,
+		headers: headers,
+		client:  client,
+	}
+}
+
+// Name return the notifier name
+func (h *webhookNotifier) Name() string {
+	return h.name
+}
+
+// Send post the notification on webhook
+func (h *webhookNotifier) Send(ctx context.Context, notification *notifier.Notification) error {
+	body, err := json.Marshal(&webhookPayload{
+		Source:    notification.Source,
+		Kind:      notification.Kind,
+		Severity:  notification.Severity,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		Timestamp: notification.Timestamp,
+	})
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, h.client, h.url, "application/json", body, h.headers)
+}
@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// SeverityInfo is used for normal events, like start or wash
+	SeverityInfo = "info"
+
+	// SeverityWarning is used for events that need attention, like board offline or tank level
+	SeverityWarning = "warning"
+
+	// SeverityCritical is used for events that need action now, like emergency stop or dry run
+	SeverityCritical = "critical"
+)
+
+// Notification is the message sent by notifiers
+type Notification struct {
+	// Source is the board name that produce the notification
+	Source string
+
+	// Kind is the event kind, like offline_board or set_dry_run
+	Kind string
+
+	// Severity is info, warning or critical
+	Severity string
+
+	// Title is the short description
+	Title string
+
+	// Message is the long description
+	Message string
+
+	// Timestamp is when the event appear
+	Timestamp time.Time
+}
+
+// Notifier is a backend that send notifications, like SMTP or webhook
+type Notifier interface {
+	// Name return the notifier name used on logs
+	Name() string
+
+	// Send push the notification on backend
+	Send(ctx context.Context, notification *Notification) error
+}
+
+// Route permit to send only some notifications on notifier
+type Route struct {
+	// Notifier is the backend
+	Notifier Notifier
+
+	// Kinds is the event kinds sent on notifier. All kinds are sent when empty
+	Kinds []string
+
+	// MinSeverity is the minimal severity sent on notifier
+	MinSeverity string
+}
+
+// Config is the notifier setting read from `notifiers` section
+type Config struct {
+	Name        string            `mapstructure:"name"`
+	Type        string            `mapstructure:"type"`
+	URL         string            `mapstructure:"url"`
+	Token       string            `mapstructure:"token"`
+	Topic       string            `mapstructure:"topic"`
+	ChatID      string            `mapstructure:"chat_id"`
+	Headers     map[string]string `mapstructure:"headers"`
+	Kinds       []string          `mapstructure:"kinds"`
+	MinSeverity string            `mapstructure:"min_severity"`
+}
+
+// Usecase is the notifier interface used to route notifications on backends
+type Usecase interface {
+	// Notify send the notification on all notifiers that match kind and severity
+	Notify(ctx context.Context, notification *Notification) error
+}
+
+// SeverityLevel return the weight of severity to compare them
+// Unknown severity is handled as info
+func SeverityLevel(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsValidSeverity return true if severity is info, warning or critical
+func IsValidSeverity(severity string) bool {
+	switch severity {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/notifier"
+	"github.com/disaster37/gobot-fat/usecase"
+)
+
+// severities is the notification severity of each event kind
+// Kinds that are not here are info
+var severities = map[string]string{
+	helper.KindEventOfflineBoard:       notifier.SeverityWarning,
+	helper.KindEventRebootBoard:        notifier.SeverityWarning,
+	helper.KindEventSetEmergencyStop:   notifier.SeverityCritical,
+	helper.KindEventSetSecurity:        notifier.SeverityCritical,
+	helper.KindEventSetTankLowLevel:    notifier.SeverityWarning,
+	helper.KindEventSetTankHighLevel:   notifier.SeverityWarning,
+	helper.KindEventSetDryRun:          notifier.SeverityCritical,
+	helper.KindEventBlisterAlert:       notifier.SeverityWarning,
+	helper.KindEventSetDisableSecurity: notifier.SeverityWarning,
+}
+
+// notifiedEventUsecase store events and notify them
+type notifiedEventUsecase struct {
+	usecase.UsecaseCRUD
+	notifierUsecase notifier.Usecase
+}
+
+// NewEventUsecase wrap the event usecase to notify each created event
+// The notification is sent on background, so a slow backend not block boards
+func NewEventUsecase(eventUsecase usecase.UsecaseCRUD, notifierUsecase notifier.Usecase) usecase.UsecaseCRUD {
+	return &notifiedEventUsecase{
+		UsecaseCRUD:     eventUsecase,
+		notifierUsecase: notifierUsecase,
+	}
+}
+
+// Create store the event, then notify it
+func (h *notifiedEventUsecase) Create(ctx context.Context, data interface{}) error {
+
+	if err := h.UsecaseCRUD.Create(ctx, data); err != nil {
+		return err
+	}
+
+	if event, ok := data.(*models.Event); ok {
+		notification := NewNotification(event)
+		go func() {
+			_ = h.notifierUsecase.Notify(context.WithoutCancel(ctx), notification)
+		}()
+	}
+
+	return nil
+}
+
+// Severity return the notification severity of event
+// Blister alert is critical when blister is expired
+func Severity(event *models.Event) string {
+	if event.EventKind == helper.KindEventBlisterAlert && event.Level >= 100 {
+		return notifier.SeverityCritical
+	}
+
+	if severity, ok := severities[event.EventKind]; ok {
+		return severity
+	}
+
+	return notifier.SeverityInfo
+}
+
+// NewNotification convert event to notification
+func NewNotification(event *models.Event) *notifier.Notification {
+
+	message := event.Message
+	if message == "" {
+		message = fmt.Sprintf("Event %s on %s at %s", event.EventKind, event.EventType, event.Timestamp.Format("2006-01-02 15:04:05"))
+		if event.User != "" {
+			message = fmt.Sprintf("%s by %s", message, event.User)
+		}
+	}
+
+	return &notifier.Notification{
+		Source:    event.SourceName,
+		Kind:      event.EventKind,
+		Severity:  Severity(event),
+		Title:     fmt.Sprintf("[%s] %s %s", event.SourceName, event.EventKind, event.EventType),
+		Message:   message,
+		Timestamp: event.Timestamp,
+	}
+}
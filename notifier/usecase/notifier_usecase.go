@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/gobot-fat/notifier"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type route struct {
+	notifier    notifier.Notifier
+	kinds       map[string]bool
+	minSeverity int
+}
+
+type notifierUsecase struct {
+	routes  []route
+	timeout time.Duration
+}
+
+// NewNotifierUsecase will create new notifierUsecase object of notifier.Usecase interface
+// A route without min severity only send warning and critical notifications
+func NewNotifierUsecase(timeout time.Duration, routes ...notifier.Route) notifier.Usecase {
+
+	h := &notifierUsecase{
+		routes:  make([]route, 0, len(routes)),
+		timeout: timeout,
+	}
+
+	for _, r := range routes {
+		minSeverity := r.MinSeverity
+		if minSeverity == "" {
+			minSeverity = notifier.SeverityWarning
+		}
+
+		kinds := make(map[string]bool, len(r.Kinds))
+		for _, kind := range r.Kinds {
+			kinds[kind] = true
+		}
+
+		h.routes = append(h.routes, route{
+			notifier:    r.Notifier,
+			kinds:       kinds,
+			minSeverity: notifier.SeverityLevel(minSeverity),
+		})
+	}
+
+	return h
+}
+
+// Notify send the notification on all notifiers that match kind and severity
+// It try all notifiers and return the last error
+func (h *notifierUsecase) Notify(ctx context.Context, notification *notifier.Notification) (err error) {
+
+	if notification == nil {
+		return errors.New("Notification can't be null")
+	}
+
+	for _, r := range h.routes {
+		if !r.match(notification) {
+			continue
+		}
+
+		ctxSend, cancel := context.WithTimeout(ctx, h.timeout)
+		if errSend := r.notifier.Send(ctxSend, notification); errSend != nil {
+			log.Errorf("Error when send notification on %s: %s", r.notifier.Name(), errSend.Error())
+			err = errors.Wrapf(errSend, "Error when send notification on %s", r.notifier.Name())
+		} else {
+			log.Debugf("Send notification %s on %s successfully", notification.Title, r.notifier.Name())
+		}
+		cancel()
+	}
+
+	return err
+}
+
+// match return true if the notification must be sent on route
+func (r route) match(notification *notifier.Notification) bool {
+	if notifier.SeverityLevel(notification.Severity) < r.minSeverity {
+		return false
+	}
+
+	return len(r.kinds) == 0 || r.kinds[notification.Kind]
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/notifier"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordNotifier struct {
+	name          string
+	err           error
+	notifications []*notifier.Notification
+	sync.Mutex
+}
+
+func (h *recordNotifier) Name() string {
+	return h.name
+}
+
+func (h *recordNotifier) Send(ctx context.Context, notification *notifier.Notification) error {
+	h.Lock()
+	defer h.Unlock()
+	h.notifications = append(h.notifications, notification)
+	return h.err
+}
+
+func (h *recordNotifier) count() int {
+	h.Lock()
+	defer h.Unlock()
+	return len(h.notifications)
+}
+
+func TestNotify(t *testing.T) {
+	all := &recordNotifier{name: "all"}
+	critical := &recordNotifier{name: "critical"}
+	tank := &recordNotifier{name: "tank", err: errors.New("backend down")}
+
+	us := NewNotifierUsecase(1*time.Second,
+		notifier.Route{Notifier: all, MinSeverity: notifier.SeverityInfo},
+		notifier.Route{Notifier: critical, MinSeverity: notifier.SeverityCritical},
+		notifier.Route{Notifier: tank, Kinds: []string{helper.KindEventSetTankLowLevel}},
+	)
+
+	// Info is only sent on notifier that want all
+	err := us.Notify(context.Background(), &notifier.Notification{Kind: helper.KindEventWash, Severity: notifier.SeverityInfo})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, all.count())
+	assert.Equal(t, 0, critical.count())
+	assert.Equal(t, 0, tank.count())
+
+	// Kind filter
+	err = us.Notify(context.Background(), &notifier.Notification{Kind: helper.KindEventSetTankLowLevel, Severity: notifier.SeverityWarning})
+	assert.Error(t, err)
+	assert.Equal(t, 2, all.count())
+	assert.Equal(t, 0, critical.count())
+	assert.Equal(t, 1, tank.count())
+
+	// Critical
+	err = us.Notify(context.Background(), &notifier.Notification{Kind: helper.KindEventSetDryRun, Severity: notifier.SeverityCritical})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, all.count())
+	assert.Equal(t, 1, critical.count())
+	assert.Equal(t, 1, tank.count())
+
+	// Null notification
+	assert.Error(t, us.Notify(context.Background(), nil))
+}
+
+func TestEventUsecaseNotify(t *testing.T) {
+	n := &recordNotifier{name: "test"}
+	us := NewEventUsecase(usecase.NewMockUsecasetBase(), NewNotifierUsecase(1*time.Second, notifier.Route{Notifier: n}))
+
+	// Info event is not notified by default
+	err := us.Create(context.Background(), &models.Event{SourceName: "dfp", EventKind: helper.KindEventWash, EventType: "dfp"})
+	assert.NoError(t, err)
+
+	// Offline board is notified
+	err = us.Create(context.Background(), &models.Event{SourceName: "tfp", EventKind: helper.KindEventOfflineBoard, EventType: "tfp"})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return n.count() == 1 }, 1*time.Second, 10*time.Millisecond)
+
+	n.Lock()
+	assert.Equal(t, "tfp", n.notifications[0].Source)
+	assert.Equal(t, notifier.SeverityWarning, n.notifications[0].Severity)
+	assert.NotEmpty(t, n.notifications[0].Message)
+	n.Unlock()
+}
+
+func TestSeverity(t *testing.T) {
+	assert.Equal(t, notifier.SeverityInfo, Severity(&models.Event{EventKind: helper.KindEventWash}))
+	assert.Equal(t, notifier.SeverityWarning, Severity(&models.Event{EventKind: helper.KindEventRebootBoard}))
+	assert.Equal(t, notifier.SeverityCritical, Severity(&models.Event{EventKind: helper.KindEventSetEmergencyStop}))
+	assert.Equal(t, notifier.SeverityWarning, Severity(&models.Event{EventKind: helper.KindEventBlisterAlert, Level: 90}))
+	assert.Equal(t, notifier.SeverityCritical, Severity(&models.Event{EventKind: helper.KindEventBlisterAlert, Level: 100}))
+}
+
+func TestNewNotification(t *testing.T) {
+	notification := NewNotification(&models.Event{SourceName: "tank_pond", EventKind: helper.KindEventSetDryRun, EventType: "tank_pond", Message: "Tank is empty"})
+	assert.Equal(t, "Tank is empty", notification.Message)
+	assert.Equal(t, notifier.SeverityCritical, notification.Severity)
+	assert.Contains(t, notification.Title, helper.KindEventSetDryRun)
+}
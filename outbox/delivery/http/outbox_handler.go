@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/outbox"
+	"github.com/google/jsonapi"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// OutboxHandler represent the httphandler for outbox
+type OutboxHandler struct {
+	dUsecase outbox.Usecase
+}
+
+// NewOutboxHandler will initialize the outbox/ resources endpoint
+func NewOutboxHandler(e *echo.Group, us outbox.Usecase) {
+	handler := &OutboxHandler{
+		dUsecase: us,
+	}
+	e.GET("/outbox", handler.GetStatus)
+	e.POST("/outbox/action/flush", handler.Flush)
+}
+
+// GetStatus return the number of writes that wait to be retried
+func (h *OutboxHandler) GetStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	depth, err := h.dUsecase.Depth(ctx)
+	if err != nil {
+		log.Errorf("Error when get outbox depth: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
+				Title:  "Error when get outbox",
+				Detail: err.Error(),
+			},
+		})
+	}
+
+	c.Response().WriteHeader(http.StatusOK)
+	return jsonapi.MarshalPayload(c.Response(), &models.OutboxStatus{
+		ID:    "outbox",
+		Depth: depth,
+	})
+}
+
+// Flush retry now all pending writes
+// It return the status even if some writes still failed
+func (h *OutboxHandler) Flush(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	flushed, err := h.dUsecase.Flush(ctx)
+	if err != nil {
+		log.Warnf("Outbox still contain writes after flush: %s", err.Error())
+	}
+
+	depth, err := h.dUsecase.Depth(ctx)
+	if err != nil {
+		log.Errorf("Error when get outbox depth: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
+				Title:  "Error when flush outbox",
+				Detail: err.Error(),
+			},
+		})
+	}
+
+	c.Response().WriteHeader(http.StatusOK)
+	return jsonapi.MarshalPayload(c.Response(), &models.OutboxStatus{
+		ID:      "outbox",
+		Depth:   depth,
+		Flushed: flushed,
+	})
+}
@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/disaster37/gobot-fat/repository"
+)
+
+// Usecase is the outbox interface
+// It store failed Elasticsearch writes on SQL database and retry them with backoff
+type Usecase interface {
+	// Wrap return repository that queue failed Create and Update on outbox, then return no error.
+	// The index must be unique per repository, it's used to retry writes on the right repository
+	Wrap(index string, repo repository.Repository) repository.Repository
+
+	// Depth return the number of writes that wait to be retried
+	Depth(ctx context.Context) (int64, error)
+
+	// Flush retry now all writes, without wait backoff
+	// It return the number of writes that succeed
+	Flush(ctx context.Context) (int64, error)
+
+	// Start retry writes periodically
+	Start(ctx context.Context) error
+
+	// Stop retry writes
+	Stop(ctx context.Context)
+}
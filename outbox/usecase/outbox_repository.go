@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// outboxRepository queue failed writes on outbox
+type outboxRepository struct {
+	repository.Repository
+	index  string
+	outbox *outboxUsecase
+}
+
+// Create add document, or queue it on outbox when failed
+func (h *outboxRepository) Create(ctx context.Context, data interface{}) error {
+	return h.write(ctx, data, h.Repository.Create)
+}
+
+// Update update document, or queue it on outbox when failed
+func (h *outboxRepository) Update(ctx context.Context, data interface{}) error {
+	return h.write(ctx, data, h.Repository.Update)
+}
+
+// UpdateIfVersion update document only if the stored one has the version, or queue it on outbox when failed
+// A version conflict is returned and never queued. When repository not support it, it's a simple update
+func (h *outboxRepository) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+	repo, ok := h.Repository.(repository.ConditionalRepository)
+	if !ok {
+		return h.Update(ctx, data)
+	}
+
+	return h.write(ctx, data, func(ctx context.Context, data interface{}) error {
+		return repo.UpdateIfVersion(ctx, data, version)
+	})
+}
+
+func (h *outboxRepository) write(ctx context.Context, data interface{}, fn func(ctx context.Context, data interface{}) error) error {
+
+	// An older version of document wait to be retried, so we queue this one to not be overwritten by it
+	if model, ok := data.(models.Model); ok && h.outbox.isPending(ctx, h.index, model.GetID()) {
+		if err := h.outbox.add(ctx, h.index, data, errors.New("Previous write is pending")); err != nil {
+			return err
+		}
+		log.Infof("Write on %s queued on outbox behind pending write", h.index)
+		return nil
+	}
+
+	err := fn(ctx, data)
+	if err == nil || repository.IsVersionConflictError(err) {
+		return err
+	}
+
+	if errAdd := h.outbox.add(ctx, h.index, data, err); errAdd != nil {
+		log.Errorf("Error when queue write on outbox: %s", errAdd.Error())
+		return err
+	}
+	log.Warnf("Write on %s failed, it will be retried from outbox: %s", h.index, err.Error())
+
+	return nil
+}
+
+// Bulk create documents with one request when repository support it, and queue the failed ones on outbox
+func (h *outboxRepository) Bulk(ctx context.Context, listData []interface{}) (failed []interface{}, err error) {
+
+	// Documents that wait behind pending write are queued
+	toWrite := make([]interface{}, 0, len(listData))
+	for _, data := range listData {
+		if model, ok := data.(models.Model); ok && h.outbox.isPending(ctx, h.index, model.GetID()) {
+			if errAdd := h.outbox.add(ctx, h.index, data, errors.New("Previous write is pending")); errAdd != nil {
+				failed = append(failed, data)
+				err = errAdd
+			}
+			continue
+		}
+		toWrite = append(toWrite, data)
+	}
+
+	var errWrite error
+	var failedWrite []interface{}
+	if repo, ok := h.Repository.(repository.BulkRepository); ok {
+		failedWrite, errWrite = repo.Bulk(ctx, toWrite)
+	} else {
+		for _, data := range toWrite {
+			if errCreate := h.Repository.Create(ctx, data); errCreate != nil {
+				failedWrite = append(failedWrite, data)
+				errWrite = errCreate
+			}
+		}
+	}
+	if errWrite == nil {
+		return failed, err
+	}
+
+	nbQueued := 0
+	for _, data := range failedWrite {
+		if errAdd := h.outbox.add(ctx, h.index, data, errWrite); errAdd != nil {
+			log.Errorf("Error when queue write on outbox: %s", errAdd.Error())
+			failed = append(failed, data)
+			err = errWrite
+			continue
+		}
+		nbQueued++
+	}
+	if nbQueued > 0 {
+		log.Warnf("%d writes on %s failed, they will be retried from outbox: %s", nbQueued, h.index, errWrite.Error())
+	}
+
+	return failed, err
+}
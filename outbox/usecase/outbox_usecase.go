@@ -0,0 +1,388 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/metrics"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/outbox"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultInterval is the default duration between retries
+	DefaultInterval = 30 * time.Second
+
+	// DefaultMinBackoff is the default duration before the first retry
+	DefaultMinBackoff = 10 * time.Second
+
+	// DefaultMaxBackoff is the default maximum duration between two retries of same write
+	DefaultMaxBackoff = 10 * time.Minute
+)
+
+type outboxUsecase struct {
+	repo       repository.Repository
+	repos      map[string]repository.Repository
+	pending    map[string]uint
+	depth      int64
+	isLoaded   bool
+	interval   time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	timeout    time.Duration
+	chStop     chan bool
+	isStarted  bool
+	muFlush    sync.Mutex
+	sync.Mutex
+}
+
+// rawDocument is a document already serialized, used to retry writes
+type rawDocument struct {
+	id   uint
+	body json.RawMessage
+}
+
+func (h *rawDocument) SetVersion(version int64)    {}
+func (h *rawDocument) GetVersion() int64           { return 0 }
+func (h *rawDocument) SetUpdatedAt(date time.Time) {}
+func (h *rawDocument) GetID() uint                 { return h.id }
+func (h *rawDocument) SetID(id uint)               { h.id = id }
+func (h *rawDocument) MarshalJSON() ([]byte, error) {
+	return h.body, nil
+}
+
+// NewOutboxUsecase will create new outboxUsecase object of outbox.Usecase interface
+// The repo is where failed writes are stored, it must be SQL repository.
+// Default values are used when durations are not set.
+func NewOutboxUsecase(repo repository.Repository, interval time.Duration, minBackoff time.Duration, maxBackoff time.Duration, timeout time.Duration) outbox.Usecase {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if minBackoff <= 0 {
+		minBackoff = DefaultMinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	return &outboxUsecase{
+		repo:       repo,
+		repos:      make(map[string]repository.Repository),
+		pending:    make(map[string]uint),
+		interval:   interval,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+		timeout:    timeout,
+	}
+}
+
+// Wrap return repository that queue failed Create and Update on outbox
+func (h *outboxUsecase) Wrap(index string, repo repository.Repository) repository.Repository {
+	h.Lock()
+	defer h.Unlock()
+
+	h.repos[index] = repo
+
+	return &outboxRepository{
+		Repository: repo,
+		index:      index,
+		outbox:     h,
+	}
+}
+
+// Depth return the number of writes that wait to be retried
+func (h *outboxUsecase) Depth(ctx context.Context) (int64, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	if err := h.load(ctx); err != nil {
+		return 0, err
+	}
+
+	return h.depth, nil
+}
+
+// Flush retry now all writes, without wait backoff
+func (h *outboxUsecase) Flush(ctx context.Context) (int64, error) {
+	return h.process(ctx, true)
+}
+
+// Start retry writes periodically
+func (h *outboxUsecase) Start(ctx context.Context) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.isStarted {
+		return nil
+	}
+	if err := h.load(ctx); err != nil {
+		return err
+	}
+
+	h.chStop = make(chan bool)
+	h.isStarted = true
+
+	go func(chStop chan bool) {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-chStop:
+				return
+			case <-ticker.C:
+				if nb, err := h.process(context.Background(), false); err != nil {
+					log.Warnf("Outbox still contain writes after retry: %s", err.Error())
+				} else if nb > 0 {
+					log.Infof("Outbox retry %d writes successfully", nb)
+				}
+			}
+		}
+	}(h.chStop)
+
+	log.Infof("Outbox started with %d writes to retry", h.depth)
+
+	return nil
+}
+
+// Stop retry writes
+func (h *outboxUsecase) Stop(ctx context.Context) {
+	h.Lock()
+	defer h.Unlock()
+
+	if !h.isStarted {
+		return
+	}
+	close(h.chStop)
+	h.isStarted = false
+}
+
+// load read the pending writes from repository the first time
+// It must be called with lock
+func (h *outboxUsecase) load(ctx context.Context) error {
+	if h.isLoaded {
+		return nil
+	}
+
+	entries, err := h.entries(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Error when load outbox")
+	}
+	for _, entry := range entries {
+		if key := documentKey(entry.Index, entry.DocumentID); key != "" {
+			h.pending[key] = entry.ID
+		}
+	}
+	h.depth = int64(len(entries))
+	h.isLoaded = true
+	metrics.OutboxDepth.Set(float64(h.depth))
+
+	return nil
+}
+
+// entries return pending writes sorted from the oldest
+func (h *outboxUsecase) entries(ctx context.Context) ([]*models.OutboxEntry, error) {
+	list := make([]*models.OutboxEntry, 0)
+	if err := h.repo.List(ctx, &list); err != nil {
+		return nil, err
+	}
+
+	// Written entries can be kept as deleted until they are purged
+	entries := make([]*models.OutboxEntry, 0, len(list))
+	for _, entry := range list {
+		if entry.DeletedAt == nil {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	return entries, nil
+}
+
+// isPending return true if write of the same document wait to be retried
+func (h *outboxUsecase) isPending(ctx context.Context, index string, id uint) bool {
+	key := documentKey(index, id)
+	if key == "" {
+		return false
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	if err := h.load(ctx); err != nil {
+		log.Errorf("Error when check outbox: %s", err.Error())
+		return false
+	}
+
+	_, ok := h.pending[key]
+	return ok
+}
+
+// add queue the write on outbox
+// When write of the same document is already pending, it replace the document to keep only the last version
+func (h *outboxUsecase) add(ctx context.Context, index string, data interface{}, cause error) error {
+
+	document, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var id uint
+	if model, ok := data.(models.Model); ok {
+		id = model.GetID()
+	}
+
+	// The write can fail because of context is done, so we need new one to store it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.timeout)
+	defer cancel()
+
+	h.Lock()
+	defer h.Unlock()
+
+	if err = h.load(ctx); err != nil {
+		return err
+	}
+
+	key := documentKey(index, id)
+	if entryID, ok := h.pending[key]; ok {
+		entry := &models.OutboxEntry{}
+		if err = h.repo.Get(ctx, entryID, entry); err != nil {
+			return err
+		}
+		entry.Document = string(document)
+		entry.LastError = cause.Error()
+		return h.repo.Update(ctx, entry)
+	}
+
+	entry := &models.OutboxEntry{
+		Index:      index,
+		DocumentID: id,
+		Document:   string(document),
+		NextRetry:  time.Now().Add(h.minBackoff),
+		LastError:  cause.Error(),
+	}
+	if err = h.repo.Create(ctx, entry); err != nil {
+		return err
+	}
+	if key != "" {
+		h.pending[key] = entry.ID
+	}
+	h.depth++
+	metrics.OutboxDepth.Set(float64(h.depth))
+
+	return nil
+}
+
+// process retry pending writes on their repository, from the oldest.
+// When write failed, the next writes on the same index wait to keep order.
+// It return the number of writes that succeed and the last error.
+func (h *outboxUsecase) process(ctx context.Context, isForce bool) (nb int64, err error) {
+	h.muFlush.Lock()
+	defer h.muFlush.Unlock()
+
+	h.Lock()
+	if err = h.load(ctx); err != nil {
+		h.Unlock()
+		return 0, err
+	}
+	entries, err := h.entries(ctx)
+	h.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	blockedIndexes := make(map[string]bool)
+	for _, entry := range entries {
+		if blockedIndexes[entry.Index] {
+			continue
+		}
+		if !isForce && entry.NextRetry.After(time.Now()) {
+			blockedIndexes[entry.Index] = true
+			continue
+		}
+
+		if errRetry := h.retry(ctx, entry); errRetry != nil {
+			log.Debugf("Error when retry write %d on %s: %s", entry.ID, entry.Index, errRetry.Error())
+			blockedIndexes[entry.Index] = true
+			err = errRetry
+			continue
+		}
+		nb++
+	}
+
+	// Remove written entries, SQL repository keep them as deleted
+	if repo, ok := h.repo.(repository.PurgeRepository); ok && nb > 0 {
+		if _, errPurge := repo.Purge(ctx, "deleted_at", time.Now(), &models.OutboxEntry{}); errPurge != nil {
+			log.Errorf("Error when purge outbox: %s", errPurge.Error())
+		}
+	}
+
+	return nb, err
+}
+
+// retry write the pending document and remove it from outbox when succeed
+func (h *outboxUsecase) retry(ctx context.Context, entry *models.OutboxEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	// Lock to not lose document replaced during the retry
+	h.Lock()
+	defer h.Unlock()
+
+	repo, ok := h.repos[entry.Index]
+	if !ok {
+		return errors.Errorf("No repository for index %s", entry.Index)
+	}
+
+	// Read the last version of document
+	if err := h.repo.Get(ctx, entry.ID, entry); err != nil {
+		return err
+	}
+
+	if err := repo.Update(ctx, &rawDocument{id: entry.DocumentID, body: json.RawMessage(entry.Document)}); err != nil {
+		entry.Attempts++
+		entry.NextRetry = time.Now().Add(h.backoff(entry.Attempts))
+		entry.LastError = err.Error()
+		if errUpdate := h.repo.Update(ctx, entry); errUpdate != nil {
+			log.Errorf("Error when update outbox: %s", errUpdate.Error())
+		}
+		return err
+	}
+
+	if err := h.repo.Delete(ctx, entry.ID, &models.OutboxEntry{}); err != nil && !repository.IsRecordNotFoundError(err) {
+		return err
+	}
+	if key := documentKey(entry.Index, entry.DocumentID); key != "" {
+		delete(h.pending, key)
+	}
+	h.depth--
+	metrics.OutboxDepth.Set(float64(h.depth))
+
+	return nil
+}
+
+// backoff return the duration before the next retry, it double at each attempt
+func (h *outboxUsecase) backoff(attempts int64) time.Duration {
+	backoff := h.minBackoff
+	for i := int64(1); i < attempts && backoff < h.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > h.maxBackoff {
+		backoff = h.maxBackoff
+	}
+
+	return backoff
+}
+
+// documentKey return the key of document, or empty string when Elasticsearch manage the ID
+func documentKey(index string, id uint) string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/%d", index, id)
+}
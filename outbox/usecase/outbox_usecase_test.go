@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryRepository store outbox entries on memory
+type memoryRepository struct {
+	entries map[uint]models.OutboxEntry
+	lastID  uint
+	sync.Mutex
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		entries: map[uint]models.OutboxEntry{},
+	}
+}
+
+func (m *memoryRepository) Get(ctx context.Context, id uint, data interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+	entry, ok := m.entries[id]
+	if !ok {
+		return repository.ErrRecordNotFoundError
+	}
+	*data.(*models.OutboxEntry) = entry
+	return nil
+}
+
+func (m *memoryRepository) List(ctx context.Context, listData interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+	entries := listData.(*[]*models.OutboxEntry)
+	for _, entry := range m.entries {
+		tmp := entry
+		*entries = append(*entries, &tmp)
+	}
+	return nil
+}
+
+func (m *memoryRepository) Update(ctx context.Context, data interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+	entry := data.(*models.OutboxEntry)
+	m.entries[entry.ID] = *entry
+	return nil
+}
+
+func (m *memoryRepository) Create(ctx context.Context, data interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+	m.lastID++
+	entry := data.(*models.OutboxEntry)
+	entry.ID = m.lastID
+	m.entries[entry.ID] = *entry
+	return nil
+}
+
+func (m *memoryRepository) Delete(ctx context.Context, id uint, data interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.entries[id]; !ok {
+		return repository.ErrRecordNotFoundError
+	}
+	delete(m.entries, id)
+	return nil
+}
+
+// elasticRepository record written documents and can be down
+type elasticRepository struct {
+	repository.Repository
+	isDown    bool
+	documents []string
+	sync.Mutex
+}
+
+func (m *elasticRepository) setDown(isDown bool) {
+	m.Lock()
+	defer m.Unlock()
+	m.isDown = isDown
+}
+
+func (m *elasticRepository) Update(ctx context.Context, data interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.isDown {
+		return errors.New("Elasticsearch is down")
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	m.documents = append(m.documents, string(b))
+	return nil
+}
+
+func (m *elasticRepository) Create(ctx context.Context, data interface{}) error {
+	return m.Update(ctx, data)
+}
+
+func (m *elasticRepository) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+	if version != 1 {
+		return repository.ErrVersionConflict
+	}
+	return m.Update(ctx, data)
+}
+
+func TestOutbox(t *testing.T) {
+	store := newMemoryRepository()
+	es := &elasticRepository{}
+	us := NewOutboxUsecase(store, 1*time.Hour, 1*time.Hour, 2*time.Hour, 1*time.Second)
+	repo := us.Wrap("event", es)
+
+	// Write directly when Elasticsearch is up
+	err := repo.Create(context.Background(), &models.Event{SourceName: "dfp", EventKind: "wash"})
+	assert.NoError(t, err)
+	assert.Len(t, es.documents, 1)
+	depth, err := us.Depth(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), depth)
+
+	// Queue writes when Elasticsearch is down
+	es.setDown(true)
+	err = repo.Create(context.Background(), &models.Event{SourceName: "dfp", EventKind: "temperature", Temperature: 12})
+	assert.NoError(t, err)
+	err = repo.Create(context.Background(), &models.Event{SourceName: "dfp", EventKind: "wash"})
+	assert.NoError(t, err)
+	depth, err = us.Depth(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), depth)
+
+	// Not retried before backoff
+	nb, err := us.(*outboxUsecase).process(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), nb)
+
+	// Flush failed and backoff increase
+	nb, err = us.Flush(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), nb)
+	entry := &models.OutboxEntry{}
+	assert.NoError(t, store.Get(context.Background(), 1, entry))
+	assert.Equal(t, int64(1), entry.Attempts)
+	assert.NotEmpty(t, entry.LastError)
+
+	// Flush when Elasticsearch is back, on the same order
+	es.setDown(false)
+	nb, err = us.Flush(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), nb)
+	assert.Len(t, es.documents, 3)
+	assert.Contains(t, es.documents[1], "temperature")
+	depth, err = us.Depth(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), depth)
+}
+
+func TestOutboxKeepLastVersion(t *testing.T) {
+	store := newMemoryRepository()
+	es := &elasticRepository{}
+	us := NewOutboxUsecase(store, 1*time.Hour, 1*time.Hour, 2*time.Hour, 1*time.Second)
+	repo := us.Wrap("tank", es)
+
+	config := &models.TankConfig{Name: "pond", Depth: 100}
+	config.ID = 1
+
+	es.setDown(true)
+	assert.NoError(t, repo.Update(context.Background(), config))
+
+	// Next write of same document wait behind pending one, even if Elasticsearch is back
+	es.setDown(false)
+	config.Depth = 200
+	assert.NoError(t, repo.Update(context.Background(), config))
+	assert.Empty(t, es.documents)
+	depth, err := us.Depth(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), depth)
+
+	// Only the last version is written
+	nb, err := us.Flush(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nb)
+	assert.Len(t, es.documents, 1)
+	assert.Contains(t, es.documents[0], `"depth":200`)
+
+	// Write directly now
+	assert.NoError(t, repo.Update(context.Background(), config))
+	assert.Len(t, es.documents, 2)
+}
+
+func TestOutboxUpdateIfVersion(t *testing.T) {
+	store := newMemoryRepository()
+	es := &elasticRepository{}
+	us := NewOutboxUsecase(store, 1*time.Hour, 1*time.Hour, 2*time.Hour, 1*time.Second)
+	repo := us.Wrap("tank", es).(repository.ConditionalRepository)
+
+	config := &models.TankConfig{Name: "pond", Depth: 100}
+	config.ID = 1
+
+	// Write directly when version match
+	assert.NoError(t, repo.UpdateIfVersion(context.Background(), config, 1))
+	assert.Len(t, es.documents, 1)
+
+	// Version conflict is returned and not queued
+	err := repo.UpdateIfVersion(context.Background(), config, 2)
+	assert.True(t, repository.IsVersionConflictError(err))
+	depth, err := us.Depth(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), depth)
+
+	// Queue write when Elasticsearch is down
+	es.setDown(true)
+	assert.NoError(t, repo.UpdateIfVersion(context.Background(), config, 1))
+	depth, err = us.Depth(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), depth)
+}
+
+func TestOutboxLoadPending(t *testing.T) {
+	store := newMemoryRepository()
+	assert.NoError(t, store.Create(context.Background(), &models.OutboxEntry{Index: "event", Document: `{"kind":"wash"}`}))
+	assert.NoError(t, store.Create(context.Background(), &models.OutboxEntry{Index: "unknown", Document: `{}`}))
+
+	es := &elasticRepository{}
+	us := NewOutboxUsecase(store, 1*time.Hour, 1*time.Hour, 2*time.Hour, 1*time.Second)
+	us.Wrap("event", es)
+
+	depth, err := us.Depth(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), depth)
+
+	// Entry without repository stay on outbox
+	nb, err := us.Flush(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), nb)
+	assert.Equal(t, []string{`{"kind":"wash"}`}, es.documents)
+}
+
+func TestOutboxSQL(t *testing.T) {
+	conn, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "gobot-fat.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.AutoMigrate(&models.OutboxEntry{}); err != nil {
+		t.Fatal(err)
+	}
+	store := repository.NewSQLRepository(conn)
+	es := &elasticRepository{isDown: true}
+	us := NewOutboxUsecase(store, 1*time.Hour, 1*time.Hour, 2*time.Hour, 1*time.Second)
+	repo := us.Wrap("event", es)
+	assert.NoError(t, repo.Create(context.Background(), &models.Event{SourceName: "dfp", EventKind: "wash"}))
+
+	// Written entries are removed, not only deleted
+	es.setDown(false)
+	nb, err := us.Flush(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nb)
+	entries := make([]*models.OutboxEntry, 0)
+	assert.NoError(t, store.List(context.Background(), &entries))
+	assert.Empty(t, entries)
+}
+
+func TestBackoff(t *testing.T) {
+	us := NewOutboxUsecase(newMemoryRepository(), 0, 10*time.Second, 1*time.Minute, 1*time.Second).(*outboxUsecase)
+
+	assert.Equal(t, DefaultInterval, us.interval)
+	assert.Equal(t, 10*time.Second, us.backoff(1))
+	assert.Equal(t, 20*time.Second, us.backoff(2))
+	assert.Equal(t, 40*time.Second, us.backoff(3))
+	assert.Equal(t, 1*time.Minute, us.backoff(4))
+	assert.Equal(t, 1*time.Minute, us.backoff(10))
+}
+
+func TestOutboxBulk(t *testing.T) {
+	store := newMemoryRepository()
+	es := &elasticRepository{}
+	us := NewOutboxUsecase(store, 1*time.Hour, 1*time.Hour, 2*time.Hour, 1*time.Second)
+	repo := us.Wrap("event", es).(repository.BulkRepository)
+
+	events := []interface{}{
+		&models.Event{SourceName: "dfp", EventKind: "wash"},
+		&models.Event{SourceName: "dfp", EventKind: "temperature"},
+	}
+
+	// Write directly when Elasticsearch is up
+	failed, err := repo.Bulk(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+	assert.Len(t, es.documents, 2)
+
+	// Queue writes when Elasticsearch is down
+	es.setDown(true)
+	failed, err = repo.Bulk(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+	depth, err := us.Depth(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), depth)
+}
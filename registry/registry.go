@@ -0,0 +1,219 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	// TypeDFP is the type of Drum Filter Pond board
+	TypeDFP = "dfp"
+
+	// TypeTFP is the type of Technical Filter Pond board
+	TypeTFP = "tfp"
+
+	// TypeTank is the type of tank board
+	TypeTank = "tank"
+)
+
+// ErrInvalidInstance is returned when board instance is not valid
+var ErrInvalidInstance = errors.New("Invalid board instance")
+
+// Instance is a board declared on config
+type Instance struct {
+	// Type is the board type, like tank
+	Type string
+
+	// Name is the board name, unique for all boards
+	Name string
+
+	// ID is the ID of board config and state, unique for the board type
+	ID uint
+
+	// Enable is false when only config and state are handled, without board
+	Enable bool
+
+	// Settings is the board section of config
+	Settings *viper.Viper
+}
+
+// Factory create the repositories, usecases, HTTP routes and board of instance
+type Factory func(ctx context.Context, instance *Instance) error
+
+// Registry create the boards from their type
+type Registry struct {
+	factories map[string]Factory
+	types     []string
+}
+
+// NewRegistry create empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+		types:     make([]string, 0),
+	}
+}
+
+// Register add the factory of board type
+// The boards are created in the order types are registered, so board that depend on other must be registered after
+func (h *Registry) Register(boardType string, factory Factory) {
+	if _, ok := h.factories[boardType]; !ok {
+		h.types = append(h.types, boardType)
+	}
+	h.factories[boardType] = factory
+}
+
+// Init create each board instance with the factory of its type
+func (h *Registry) Init(ctx context.Context, instances []*Instance) error {
+	if err := h.check(instances); err != nil {
+		return err
+	}
+
+	// Sort by type order, and keep config order for same type
+	order := make(map[string]int, len(h.types))
+	for i, boardType := range h.types {
+		order[boardType] = i
+	}
+	sorted := make([]*Instance, len(instances))
+	copy(sorted, instances)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return order[sorted[i].Type] < order[sorted[j].Type]
+	})
+
+	for _, instance := range sorted {
+		log.Infof("Init %s board %s with ID %d", instance.Type, instance.Name, instance.ID)
+		if err := h.factories[instance.Type](ctx, instance); err != nil {
+			return errors.Wrapf(err, "Error when init %s board %s", instance.Type, instance.Name)
+		}
+	}
+
+	return nil
+}
+
+// check return error if type is not registered, or if name or ID is used twice
+func (h *Registry) check(instances []*Instance) error {
+	problems := make([]string, 0)
+	names := make(map[string]bool, len(instances))
+	ids := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		if _, ok := h.factories[instance.Type]; !ok {
+			problems = append(problems, fmt.Sprintf("board %s: type %s not supported", instance.Name, instance.Type))
+		}
+		if instance.Name == "" {
+			problems = append(problems, fmt.Sprintf("%s board %d: name is required", instance.Type, instance.ID))
+		} else if names[instance.Name] {
+			problems = append(problems, fmt.Sprintf("board %s: name already used", instance.Name))
+		}
+		names[instance.Name] = true
+
+		key := fmt.Sprintf("%s/%d", instance.Type, instance.ID)
+		if ids[key] {
+			problems = append(problems, fmt.Sprintf("board %s: ID %d already used by other %s board", instance.Name, instance.ID, instance.Type))
+		}
+		ids[key] = true
+	}
+
+	if len(problems) > 0 {
+		return errors.Wrap(ErrInvalidInstance, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// Instances read the boards declared on `boards` list of config
+// Each item need `type` and `name`. The `id` is the next free ID of type when not set, and `enable` is true when not set.
+// The reservedIDs by type are the IDs used by boards that are not on config, like tanks created over API, they are skipped for the next free ID.
+// Without `boards` list, it read the `dfp`, `tfp`, `tank_pond` and `tank_garden` sections, with their historical IDs.
+func Instances(configHandler *viper.Viper, reservedIDs map[string][]uint) ([]*Instance, error) {
+	if !configHandler.IsSet("boards") {
+		return legacyInstances(configHandler), nil
+	}
+
+	items := make([]map[string]interface{}, 0)
+	if err := configHandler.UnmarshalKey("boards", &items); err != nil {
+		return nil, errors.Wrap(ErrInvalidInstance, err.Error())
+	}
+
+	instances := make([]*Instance, 0, len(items))
+	usedIDs := make(map[string]map[uint]bool)
+	for boardType, ids := range reservedIDs {
+		usedIDs[boardType] = make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			usedIDs[boardType][id] = true
+		}
+	}
+	for _, item := range items {
+		settings := viper.New()
+		if err := settings.MergeConfigMap(item); err != nil {
+			return nil, errors.Wrap(ErrInvalidInstance, err.Error())
+		}
+		settings.SetDefault("enable", true)
+		instance := newInstance(configHandler, settings.GetString("type"), settings)
+		if usedIDs[instance.Type] == nil {
+			usedIDs[instance.Type] = make(map[uint]bool)
+		}
+		if instance.ID != 0 {
+			usedIDs[instance.Type][instance.ID] = true
+		}
+		instances = append(instances, instance)
+	}
+
+	// Set next free ID when not set
+	for _, instance := range instances {
+		if instance.ID != 0 {
+			continue
+		}
+		id := uint(1)
+		for usedIDs[instance.Type][id] {
+			id++
+		}
+		instance.ID = id
+		usedIDs[instance.Type][id] = true
+	}
+
+	return instances, nil
+}
+
+// legacyInstances return the boards of historical config sections
+func legacyInstances(configHandler *viper.Viper) []*Instance {
+	sections := []struct {
+		section   string
+		boardType string
+		id        uint
+	}{
+		{section: "dfp", boardType: TypeDFP, id: 1},
+		{section: "tfp", boardType: TypeTFP, id: 1},
+		{section: "tank_pond", boardType: TypeTank, id: 1},
+		{section: "tank_garden", boardType: TypeTank, id: 2},
+	}
+
+	instances := make([]*Instance, 0, len(sections))
+	for _, section := range sections {
+		settings := configHandler.Sub(section.section)
+		if settings == nil {
+			continue
+		}
+		settings.Set("id", section.id)
+		instances = append(instances, newInstance(configHandler, section.boardType, settings))
+	}
+
+	return instances
+}
+
+func newInstance(configHandler *viper.Viper, boardType string, settings *viper.Viper) *Instance {
+	settings.Set("fake-board", configHandler.GetBool("fake-board"))
+
+	return &Instance{
+		Type:     boardType,
+		Name:     settings.GetString("name"),
+		ID:       settings.GetUint("id"),
+		Enable:   settings.GetBool("enable"),
+		Settings: settings,
+	}
+}
@@ -0,0 +1,126 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func readConfig(t *testing.T, config string) *viper.Viper {
+	configHandler := viper.New()
+	configHandler.SetConfigType("yaml")
+	if err := configHandler.ReadConfig(bytes.NewBufferString(config)); err != nil {
+		t.Fatal(err)
+	}
+	return configHandler
+}
+
+func TestInstances(t *testing.T) {
+
+	// Boards list
+	configHandler := readConfig(t, `
+fake-board: true
+boards:
+  - type: 'tank'
+    name: 'tank_pond'
+    url: 'http://192.168.0.190'
+  - type: 'tank'
+    name: 'tank_garden'
+    id: 1
+    enable: false
+  - type: 'tank'
+    name: 'tank_rain'
+  - type: 'tfp'
+    name: 'tfp'
+    pin:
+      relay:
+        uvc1: 2
+`)
+	instances, err := Instances(configHandler, nil)
+	assert.NoError(t, err)
+	assert.Len(t, instances, 4)
+	assert.Equal(t, TypeTank, instances[0].Type)
+	assert.Equal(t, "tank_pond", instances[0].Name)
+	assert.Equal(t, uint(2), instances[0].ID)
+	assert.True(t, instances[0].Enable)
+	assert.Equal(t, "http://192.168.0.190", instances[0].Settings.GetString("url"))
+	assert.True(t, instances[0].Settings.GetBool("fake-board"))
+	assert.Equal(t, uint(1), instances[1].ID)
+	assert.False(t, instances[1].Enable)
+	assert.Equal(t, uint(3), instances[2].ID)
+	assert.Equal(t, uint(1), instances[3].ID)
+	assert.Equal(t, "2", instances[3].Settings.GetString("pin.relay.uvc1"))
+
+	// Reserved IDs are skipped
+	instances, err = Instances(configHandler, map[string][]uint{TypeTank: {2, 4}})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), instances[0].ID)
+	assert.Equal(t, uint(5), instances[2].ID)
+	assert.Equal(t, uint(1), instances[3].ID)
+
+	// Historical sections
+	configHandler = readConfig(t, `
+dfp:
+  name: 'dfp'
+  enable: true
+tank_pond:
+  name: 'tank_pond'
+  enable: true
+tank_garden:
+  name: 'tank_garden'
+  enable: false
+`)
+	instances, err = Instances(configHandler, nil)
+	assert.NoError(t, err)
+	assert.Len(t, instances, 3)
+	assert.Equal(t, TypeDFP, instances[0].Type)
+	assert.Equal(t, uint(1), instances[0].ID)
+	assert.Equal(t, "tank_garden", instances[2].Name)
+	assert.Equal(t, uint(2), instances[2].ID)
+	assert.False(t, instances[2].Enable)
+}
+
+func TestRegistry(t *testing.T) {
+	created := make([]string, 0)
+	factory := func(ctx context.Context, instance *Instance) error {
+		created = append(created, instance.Name)
+		return nil
+	}
+	boardRegistry := NewRegistry()
+	boardRegistry.Register(TypeTank, factory)
+	boardRegistry.Register(TypeTFP, factory)
+
+	// Create boards by type order
+	err := boardRegistry.Init(context.Background(), []*Instance{
+		{Type: TypeTFP, Name: "tfp", ID: 1},
+		{Type: TypeTank, Name: "tank_pond", ID: 1},
+		{Type: TypeTFP, Name: "tfp2", ID: 2},
+		{Type: TypeTank, Name: "tank_garden", ID: 2},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tank_pond", "tank_garden", "tfp", "tfp2"}, created)
+
+	// Invalid instances
+	created = created[:0]
+	err = boardRegistry.Init(context.Background(), []*Instance{
+		{Type: TypeDFP, Name: "dfp", ID: 1},
+		{Type: TypeTank, Name: "tank", ID: 1},
+		{Type: TypeTank, Name: "tank", ID: 1},
+	})
+	assert.True(t, errors.Is(err, ErrInvalidInstance))
+	assert.Contains(t, err.Error(), "type dfp not supported")
+	assert.Contains(t, err.Error(), "name already used")
+	assert.Contains(t, err.Error(), "ID 1 already used")
+	assert.Empty(t, created)
+
+	// Factory error
+	boardRegistry.Register(TypeDFP, func(ctx context.Context, instance *Instance) error {
+		return errors.New("test")
+	})
+	err = boardRegistry.Init(context.Background(), []*Instance{{Type: TypeDFP, Name: "dfp", ID: 1}})
+	assert.Error(t, err)
+}
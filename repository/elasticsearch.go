@@ -159,6 +159,155 @@ func (h *ElasticsearchRepositoryGen) List(ctx context.Context, listData interfac
 	return nil
 }
 
+// Search return documents that match query
+// When ID is not managed, documents ID are generated by Elasticsearch and are only set on items that implement models.Document
+func (h *ElasticsearchRepositoryGen) Search(ctx context.Context, query *Query, listData interface{}) (total int64, err error) {
+
+	if query == nil {
+		return 0, errors.New("Query can't be null")
+	}
+	if listData == nil {
+		return 0, errors.New("Data can't be null")
+	}
+	if reflect.TypeOf(listData).Kind() != reflect.Ptr {
+		return 0, errors.New("ListData must be a pointer")
+	}
+	if reflect.TypeOf(listData).Elem().Kind() != reflect.Slice {
+		return 0, errors.New("ListData must contain slice")
+	}
+
+	ld := reflect.ValueOf(listData).Elem()
+
+	b, err := json.Marshal(h.buildQuery(query))
+	if err != nil {
+		return 0, err
+	}
+	log.Debugf("Query: %s", string(b))
+
+	res, err := h.Conn.Search(
+		h.Conn.Search.WithIndex(h.Index),
+		h.Conn.Search.WithBody(bytes.NewReader(b)),
+		h.Conn.Search.WithTrackTotalHits(true),
+		h.Conn.Search.WithContext(ctx),
+		h.Conn.Search.WithPretty(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	// Index not yet created when nothing was written
+	if res.StatusCode == 404 {
+		return 0, nil
+	}
+	if res.IsError() {
+		return 0, errors.Errorf("Error when read response: %s", res.String())
+	}
+
+	ret := new(olivere.SearchResult)
+	if err := h.decode(res.Body, ret); err != nil {
+		return 0, err
+	}
+	if ret.Hits == nil {
+		return 0, nil
+	}
+	if ret.Hits.TotalHits != nil {
+		total = ret.Hits.TotalHits.Value
+	}
+
+	for _, hit := range ret.Hits.Hits {
+		tmp := reflect.New(reflect.TypeOf(listData).Elem().Elem())
+		if err = json.Unmarshal(hit.Source, tmp.Interface()); err != nil {
+			return 0, err
+		}
+		var data models.Model
+		if tmp.Elem().Kind() == reflect.Ptr {
+			data = tmp.Elem().Interface().(models.Model)
+		} else {
+			data = tmp.Interface().(models.Model)
+		}
+
+		if idDoc, err := strconv.ParseUint(hit.Id, 10, 32); err == nil {
+			data.SetID(uint(idDoc))
+		}
+		if doc, ok := data.(models.Document); ok {
+			doc.SetDocumentID(hit.Id)
+		}
+
+		ld.Set(reflect.Append(ld, tmp.Elem()))
+	}
+
+	log.Debugf("Data: %+v", listData)
+
+	return total, nil
+}
+
+// buildQuery convert generic query to Elasticsearch query DSL
+func (h *ElasticsearchRepositoryGen) buildQuery(query *Query) map[string]interface{} {
+
+	filters := make([]interface{}, 0, len(query.Filters)+1)
+	for field, value := range query.Filters {
+		filters = append(filters, map[string]interface{}{
+			"match_phrase": map[string]interface{}{
+				field: value,
+			},
+		})
+	}
+
+	for _, field := range query.Exists {
+		filters = append(filters, map[string]interface{}{
+			"exists": map[string]interface{}{
+				"field": field,
+			},
+		})
+	}
+
+	if query.TimeField != "" && (!query.From.IsZero() || !query.To.IsZero()) {
+		timeRange := make(map[string]interface{})
+		if !query.From.IsZero() {
+			timeRange["gte"] = query.From.Format(time.RFC3339Nano)
+		}
+		if !query.To.IsZero() {
+			timeRange["lte"] = query.To.Format(time.RFC3339Nano)
+		}
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{
+				query.TimeField: timeRange,
+			},
+		})
+	}
+
+	body := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		},
+		"from": query.Offset,
+	}
+
+	if query.Size > 0 {
+		body["size"] = query.Size
+	}
+
+	if query.SortField != "" {
+		order := "asc"
+		if query.SortDesc {
+			order = "desc"
+		}
+		body["sort"] = []interface{}{
+			map[string]interface{}{
+				query.SortField: map[string]interface{}{
+					"order": order,
+				},
+			},
+		}
+	}
+
+	return body
+}
+
 // Update document on Elasticsearch
 func (h *ElasticsearchRepositoryGen) Update(ctx context.Context, data interface{}) error {
 
@@ -214,11 +363,242 @@ func (h *ElasticsearchRepositoryGen) Update(ctx context.Context, data interface{
 	return nil
 }
 
+// UpdateIfVersion update document on Elasticsearch only if the stored document has the version
+// The write use if_seq_no and if_primary_term of the read document, so a concurrent write between both is detected
+func (h *ElasticsearchRepositoryGen) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+
+	if data == nil {
+		return errors.New("Data can't be null")
+	}
+	if reflect.TypeOf(data).Kind() != reflect.Ptr {
+		return errors.New("Data must a pointer")
+	}
+	if !h.IsManageID {
+		return errors.New("Conditional update need to manage document ID")
+	}
+	log.Debugf("Data: %s", data)
+
+	dataModel := data.(models.Model)
+
+	// Read the current document with its sequence number
+	res, err := h.Conn.Get(
+		h.Index,
+		fmt.Sprintf("%d", dataModel.GetID()),
+		h.Conn.Get.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.IsError() && res.StatusCode != 404 {
+		return errors.Errorf("Error when read response: %s", res.String())
+	}
+	ret := new(olivere.GetResult)
+	if err := h.decode(res.Body, ret); err != nil {
+		return err
+	}
+	if !ret.Found {
+		return ErrRecordNotFoundError
+	}
+	if ret.SeqNo == nil || ret.PrimaryTerm == nil {
+		return errors.New("Document has no sequence number")
+	}
+	current := &models.ModelGeneric{}
+	if err = json.Unmarshal(ret.Source, current); err != nil {
+		return err
+	}
+	if current.GetVersion() != version {
+		return ErrVersionConflict
+	}
+
+	dataModel.SetUpdatedAt(time.Now())
+	sdata, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	resIndex, err := h.Conn.Index(
+		h.Index,
+		bytes.NewBuffer(sdata),
+		h.Conn.Index.WithDocumentID(fmt.Sprintf("%d", dataModel.GetID())),
+		h.Conn.Index.WithIfSeqNo(int(*ret.SeqNo)),
+		h.Conn.Index.WithIfPrimaryTerm(int(*ret.PrimaryTerm)),
+		h.Conn.Index.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resIndex.Body.Close() }()
+
+	// Document was updated between read and write
+	if resIndex.StatusCode == 409 {
+		return ErrVersionConflict
+	}
+	if resIndex.IsError() {
+		return errors.Errorf("Error when read response: %s", resIndex.String())
+	}
+
+	log.Debugf("Response: %s", resIndex.String())
+
+	return nil
+}
+
 // Create add new document on Elasticsearch
 func (h *ElasticsearchRepositoryGen) Create(ctx context.Context, data interface{}) error {
 	return h.Update(ctx, data)
 }
 
+// bulkResponse is the result of bulk request
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// Bulk create documents on Elasticsearch with one request
+// It return the documents that failed, all of them when request failed
+func (h *ElasticsearchRepositoryGen) Bulk(ctx context.Context, listData []interface{}) (failed []interface{}, err error) {
+
+	if len(listData) == 0 {
+		return nil, nil
+	}
+
+	b := &bytes.Buffer{}
+	for _, data := range listData {
+		dataModel, ok := data.(models.Model)
+		if !ok {
+			return listData, errors.New("Data must be a model")
+		}
+		dataModel.SetUpdatedAt(time.Now())
+
+		action := map[string]interface{}{}
+		if h.IsManageID {
+			action["_id"] = fmt.Sprintf("%d", dataModel.GetID())
+		}
+		sAction, err := json.Marshal(map[string]interface{}{"index": action})
+		if err != nil {
+			return listData, err
+		}
+		sData, err := json.Marshal(data)
+		if err != nil {
+			return listData, err
+		}
+		b.Write(sAction)
+		b.WriteByte('\n')
+		b.Write(sData)
+		b.WriteByte('\n')
+	}
+
+	res, err := h.Conn.Bulk(
+		b,
+		h.Conn.Bulk.WithIndex(h.Index),
+		h.Conn.Bulk.WithContext(ctx),
+	)
+	if err != nil {
+		return listData, err
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	if res.IsError() {
+		return listData, errors.Errorf("Error when read response: %s", res.String())
+	}
+
+	ret := &bulkResponse{}
+	if err = h.decode(res.Body, ret); err != nil {
+		return listData, err
+	}
+	if !ret.Errors {
+		return nil, nil
+	}
+
+	failed = make([]interface{}, 0)
+	for i, item := range ret.Items {
+		for _, result := range item {
+			if result.Status >= 300 && i < len(listData) {
+				failed = append(failed, listData[i])
+				err = errors.Errorf("Error when create document: %s", string(result.Error))
+			}
+		}
+	}
+
+	return failed, err
+}
+
+// Purge remove documents where field is before date with delete by query
+func (h *ElasticsearchRepositoryGen) Purge(ctx context.Context, field string, before time.Time, data interface{}) (int64, error) {
+
+	b, err := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{
+			"range": map[string]interface{}{
+				field: map[string]interface{}{
+					"lt": before.Format(time.RFC3339Nano),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := h.Conn.DeleteByQuery(
+		[]string{h.Index},
+		bytes.NewReader(b),
+		h.Conn.DeleteByQuery.WithContext(ctx),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	// Index not yet created when nothing was written
+	if res.StatusCode == 404 {
+		return 0, nil
+	}
+	if res.IsError() {
+		return 0, errors.Errorf("Error when read response: %s", res.String())
+	}
+
+	ret := &struct {
+		Deleted int64 `json:"deleted"`
+	}{}
+	if err = h.decode(res.Body, ret); err != nil {
+		return 0, err
+	}
+
+	return ret.Deleted, nil
+}
+
+// Delete remove document from Elasticsearch with ID
+func (h *ElasticsearchRepositoryGen) Delete(ctx context.Context, id uint, data interface{}) error {
+
+	res, err := h.Conn.Delete(
+		h.Index,
+		fmt.Sprintf("%d", id),
+		h.Conn.Delete.WithContext(ctx),
+		h.Conn.Delete.WithPretty(),
+	)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode == 404 {
+		return ErrRecordNotFoundError
+	}
+	if res.IsError() {
+		return errors.Errorf("Error when read response: %s", res.String())
+	}
+
+	log.Debugf("Response: %s", res.String())
+
+	return nil
+}
+
 func (h *ElasticsearchRepositoryGen) decode(body io.Reader, ret interface{}) error {
 
 	decoder := json.NewDecoder(body)
@@ -2,13 +2,16 @@ package repository
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/disaster37/gobot-fat/mock"
 	"github.com/disaster37/gobot-fat/models"
 	elastic "github.com/elastic/go-elasticsearch/v8"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -187,6 +190,50 @@ func TestUpdateElasticsearch(t *testing.T) {
 
 }
 
+func TestUpdateIfVersionElasticsearch(t *testing.T) {
+
+	var query string
+	indexStatus := http.StatusOK
+	mocktrans := &mock.MockTransport{}
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) {
+		header := http.Header{"X-Elastic-Product": []string{"Elasticsearch"}}
+		if req.Method == http.MethodGet {
+			return &http.Response{StatusCode: http.StatusOK, Body: mock.Fixture("get_config.json"), Header: header}, nil
+		}
+		query = req.URL.RawQuery
+		return &http.Response{StatusCode: indexStatus, Body: mock.Fixture("update_config.json"), Header: header}, nil
+	}
+	conn, _ := elastic.NewClient(elastic.Config{Transport: mocktrans})
+	repository := NewElasticsearchRepository(conn, "test").(ConditionalRepository)
+
+	dfpConfig := &models.DFPConfig{
+		WashingDuration: 20,
+	}
+	dfpConfig.Version = 2
+	dfpConfig.ID = 1
+
+	// Stored document has the version
+	err := repository.UpdateIfVersion(context.Background(), dfpConfig, 1)
+	assert.NoError(t, err)
+	assert.Contains(t, query, "if_seq_no=2")
+	assert.Contains(t, query, "if_primary_term=1")
+
+	// Stored document has another version
+	query = ""
+	err = repository.UpdateIfVersion(context.Background(), dfpConfig, 2)
+	assert.True(t, IsVersionConflictError(err))
+	assert.Empty(t, query)
+
+	// Document updated between read and write
+	indexStatus = http.StatusConflict
+	err = repository.UpdateIfVersion(context.Background(), dfpConfig, 1)
+	assert.True(t, IsVersionConflictError(err))
+
+	// When record is nil
+	err = repository.UpdateIfVersion(context.Background(), nil, 1)
+	assert.Error(t, err)
+}
+
 func TestCreateElasticsearch(t *testing.T) {
 
 	mocktrans := &mock.MockTransport{
@@ -226,3 +273,173 @@ func TestCreateElasticsearch(t *testing.T) {
 	assert.Error(t, err)
 
 }
+
+func TestBulkElasticsearch(t *testing.T) {
+
+	// When all documents are created
+	var body string
+	mocktrans := &mock.MockTransport{
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       mock.Fixture("bulk_event.json"),
+			Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}},
+		},
+	}
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+		return mocktrans.Response, nil
+	}
+	conn, _ := elastic.NewClient(elastic.Config{Transport: mocktrans})
+	repository := NewElasticsearchRepository(conn, "test", false).(BulkRepository)
+
+	events := []interface{}{
+		&models.Event{SourceName: "dfp", EventKind: "wash"},
+		&models.Event{SourceName: "dfp", EventKind: "temperature"},
+	}
+
+	failed, err := repository.Bulk(context.Background(), events)
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+	assert.Equal(t, 4, strings.Count(body, "\n"))
+	assert.Contains(t, body, `{"index":{}}`)
+	assert.Contains(t, body, `"kind":"temperature"`)
+
+	// When some documents failed
+	mocktrans = &mock.MockTransport{
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       mock.Fixture("bulk_event_error.json"),
+			Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}},
+		},
+	}
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) { return mocktrans.Response, nil }
+	conn, _ = elastic.NewClient(elastic.Config{Transport: mocktrans})
+	repository = NewElasticsearchRepository(conn, "test", false).(BulkRepository)
+
+	failed, err = repository.Bulk(context.Background(), events)
+	assert.Error(t, err)
+	assert.Equal(t, []interface{}{events[1]}, failed)
+
+	// When request failed
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) { return nil, errors.New("Elasticsearch is down") }
+	failed, err = repository.Bulk(context.Background(), events)
+	assert.Error(t, err)
+	assert.Equal(t, events, failed)
+
+}
+
+func TestPurgeElasticsearch(t *testing.T) {
+
+	var body string
+	mocktrans := &mock.MockTransport{
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       mock.Fixture("delete_by_query.json"),
+			Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}},
+		},
+	}
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+		return mocktrans.Response, nil
+	}
+	conn, _ := elastic.NewClient(elastic.Config{Transport: mocktrans})
+	repository := NewElasticsearchRepository(conn, "test", false).(PurgeRepository)
+
+	nb, err := repository.Purge(context.Background(), "timestamp", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), &models.Event{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), nb)
+	assert.Equal(t, `{"query":{"range":{"timestamp":{"lt":"2024-05-01T00:00:00Z"}}}}`, body)
+
+}
+
+func TestDeleteElasticsearch(t *testing.T) {
+
+	mocktrans := &mock.MockTransport{
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       mock.Fixture("delete_config.json"),
+			Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}},
+		},
+	}
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) { return mocktrans.Response, nil }
+	conn, _ := elastic.NewClient(elastic.Config{Transport: mocktrans})
+	repository := NewElasticsearchRepository(conn, "test")
+
+	err := repository.Delete(context.Background(), 1, &models.DFPConfig{})
+	assert.NoError(t, err)
+
+	// When record not found
+	mocktrans.Response = &http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       mock.Fixture("delete_not_found.json"),
+		Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}},
+	}
+	err = repository.Delete(context.Background(), 1, &models.DFPConfig{})
+	assert.ErrorIs(t, err, ErrRecordNotFoundError)
+}
+
+func TestSearchElasticsearch(t *testing.T) {
+
+	// When records found
+	var body []byte
+	mocktrans := &mock.MockTransport{
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       mock.Fixture("search_event.json"),
+			Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}},
+		},
+	}
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) {
+		body, _ = io.ReadAll(req.Body)
+		return mocktrans.Response, nil
+	}
+	conn, _ := elastic.NewClient(elastic.Config{Transport: mocktrans})
+	repository := NewElasticsearchRepository(conn, "test", false).(SearchRepository)
+
+	query := &Query{
+		Filters: map[string]interface{}{
+			"source_name": "dfp",
+		},
+		TimeField: "timestamp",
+		From:      time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+		SortField: "timestamp",
+		SortDesc:  true,
+		Offset:    10,
+		Size:      10,
+	}
+	listEvent := make([]*models.Event, 0)
+	total, err := repository.Search(context.Background(), query, &listEvent)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), total)
+	if assert.Len(t, listEvent, 1) {
+		assert.Equal(t, "dfp", listEvent[0].SourceName)
+		assert.Equal(t, "wash", listEvent[0].EventKind)
+		assert.Equal(t, "drum", listEvent[0].EventType)
+		assert.Equal(t, "u1-5d3cBq8m2Hk0Lq7nF", listEvent[0].DocumentID)
+	}
+	assert.Contains(t, string(body), `"match_phrase":{"source_name":"dfp"}`)
+	assert.Contains(t, string(body), `"range":{"timestamp":{"gte":"2020-02-01T00:00:00Z"}}`)
+	assert.Contains(t, string(body), `"from":10`)
+
+	// When no record found
+	mocktrans = &mock.MockTransport{
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       mock.Fixture("search_not_found.json"),
+			Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}},
+		},
+	}
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) { return mocktrans.Response, nil }
+	conn, _ = elastic.NewClient(elastic.Config{Transport: mocktrans})
+	repository = NewElasticsearchRepository(conn, "test", false).(SearchRepository)
+	listEvent = make([]*models.Event, 0)
+	_, err = repository.Search(context.Background(), query, &listEvent)
+	assert.NoError(t, err)
+	assert.Empty(t, listEvent)
+
+	// When query is nil
+	_, err = repository.Search(context.Background(), nil, &listEvent)
+	assert.Error(t, err)
+}
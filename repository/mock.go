@@ -12,16 +12,21 @@ func (m *MockBase) Get(ctx context.Context, id uint, data interface{}) error { r
 func (m *MockBase) List(ctx context.Context, listData interface{}) error     { return nil }
 func (m *MockBase) Update(ctx context.Context, data interface{}) error       { return nil }
 func (m *MockBase) Create(ctx context.Context, data interface{}) error       { return nil }
+func (m *MockBase) Delete(ctx context.Context, id uint, data interface{}) error {
+	return nil
+}
 
 type Mock struct {
-	expectedData   interface{}
-	expectedError  error
-	callMethod     string
-	callParameters []interface{}
-	testGet        func(ctx context.Context, id uint, data interface{}) error
-	testList       func(ctx context.Context, listData interface{}) error
-	testUpdate     func(ctx context.Context, data interface{}) error
-	testCreate     func(ctx context.Context, data interface{}) error
+	expectedData        interface{}
+	expectedError       error
+	callMethod          string
+	callParameters      []interface{}
+	testGet             func(ctx context.Context, id uint, data interface{}) error
+	testList            func(ctx context.Context, listData interface{}) error
+	testUpdate          func(ctx context.Context, data interface{}) error
+	testUpdateIfVersion func(ctx context.Context, data interface{}, version int64) error
+	testCreate          func(ctx context.Context, data interface{}) error
+	testDelete          func(ctx context.Context, id uint, data interface{}) error
 }
 
 func NewMockBase() *MockBase {
@@ -100,6 +105,34 @@ func (h *Mock) init() {
 		return nil
 	}
 
+	h.testUpdateIfVersion = func(ctx context.Context, data interface{}, version int64) error {
+		h.callMethod = "UpdateIfVersion"
+		h.callParameters = make([]interface{}, 0)
+		h.callParameters = append(h.callParameters, ctx)
+		h.callParameters = append(h.callParameters, data)
+		h.callParameters = append(h.callParameters, version)
+		if h.expectedError != nil {
+			return h.expectedError
+		}
+
+		h.expectedData = data
+		return nil
+	}
+
+	h.testDelete = func(ctx context.Context, id uint, data interface{}) error {
+		h.callMethod = "Delete"
+		h.callParameters = make([]interface{}, 0)
+		h.callParameters = append(h.callParameters, ctx)
+		h.callParameters = append(h.callParameters, id)
+		h.callParameters = append(h.callParameters, data)
+		if h.expectedError != nil {
+			return h.expectedError
+		}
+
+		h.expectedData = nil
+		return nil
+	}
+
 }
 
 func (h *Mock) TestGet(f func(ctx context.Context, id uint, data interface{}) error) {
@@ -114,10 +147,18 @@ func (h *Mock) TestUpdate(f func(ctx context.Context, data interface{}) error) {
 	h.testUpdate = f
 }
 
+func (h *Mock) TestUpdateIfVersion(f func(ctx context.Context, data interface{}, version int64) error) {
+	h.testUpdateIfVersion = f
+}
+
 func (h *Mock) TestCreate(f func(ctx context.Context, data interface{}) error) {
 	h.testCreate = f
 }
 
+func (h *Mock) TestDelete(f func(ctx context.Context, id uint, data interface{}) error) {
+	h.testDelete = f
+}
+
 func (h *Mock) Get(ctx context.Context, id uint, data interface{}) error {
 	return h.testGet(ctx, id, data)
 }
@@ -132,11 +173,21 @@ func (h *Mock) Update(ctx context.Context, data interface{}) error {
 	return h.testUpdate(ctx, data)
 }
 
+func (h *Mock) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+
+	return h.testUpdateIfVersion(ctx, data, version)
+}
+
 func (h *Mock) Create(ctx context.Context, data interface{}) error {
 
 	return h.testCreate(ctx, data)
 }
 
+func (h *Mock) Delete(ctx context.Context, id uint, data interface{}) error {
+
+	return h.testDelete(ctx, id, data)
+}
+
 func (h *Mock) ExpectCall(functionName string, params ...interface{}) (isExpect bool, reason string) {
 
 	if functionName != h.callMethod {
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+)
+
+// NoopRepository is a repository that store nothing
+// It's used in place of Elasticsearch when it's not configured
+type NoopRepository struct{}
+
+// NewNoopRepository create new repository that store nothing
+func NewNoopRepository() Repository {
+	return &NoopRepository{}
+}
+
+// Get always return record not found
+func (h *NoopRepository) Get(ctx context.Context, id uint, data interface{}) error {
+	return ErrRecordNotFoundError
+}
+
+// List return no item
+func (h *NoopRepository) List(ctx context.Context, listData interface{}) error {
+	return nil
+}
+
+// Update do nothing
+func (h *NoopRepository) Update(ctx context.Context, data interface{}) error {
+	return nil
+}
+
+// UpdateIfVersion do nothing
+func (h *NoopRepository) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+	return nil
+}
+
+// Create do nothing
+func (h *NoopRepository) Create(ctx context.Context, data interface{}) error {
+	return nil
+}
+
+// Delete do nothing
+func (h *NoopRepository) Delete(ctx context.Context, id uint, data interface{}) error {
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -9,15 +10,87 @@ import (
 // ErrRecordNotFoundError is error when record not found on repository
 var ErrRecordNotFoundError error = errors.New("Record not found")
 
+// ErrVersionConflict is error when record was updated by another writer since it was read
+var ErrVersionConflict error = errors.New("Record was updated since it was read")
+
 // Repository is a generic repository
 type Repository interface {
 	Get(ctx context.Context, id uint, data interface{}) error
 	List(ctx context.Context, listData interface{}) error
 	Update(ctx context.Context, data interface{}) error
 	Create(ctx context.Context, data interface{}) error
+	Delete(ctx context.Context, id uint, data interface{}) error
+}
+
+// Query is a generic search request with filters, time range, sort and pagination
+type Query struct {
+	// Filters is the list of field that must match exactly the value
+	Filters map[string]interface{}
+
+	// Exists is the list of field that must have value
+	Exists []string
+
+	// TimeField is the field used to filter on time range
+	TimeField string
+
+	// From is the begin of time range, not used if zero
+	From time.Time
+
+	// To is the end of time range, not used if zero
+	To time.Time
+
+	// SortField is the field used to sort result
+	SortField string
+
+	// SortDesc is true to sort descending
+	SortDesc bool
+
+	// Offset is the number of items to skip
+	Offset int
+
+	// Size is the maximum number of items to return
+	Size int
+}
+
+// SearchRepository is a repository that can filter, sort and paginate items
+type SearchRepository interface {
+	Repository
+
+	// Search return items that match query on listData and the total number of items that match query without pagination
+	Search(ctx context.Context, query *Query, listData interface{}) (total int64, err error)
+}
+
+// BulkRepository is a repository that can create many items with one request
+type BulkRepository interface {
+	Repository
+
+	// Bulk create all items of listData. It return the items that failed and the error
+	Bulk(ctx context.Context, listData []interface{}) (failed []interface{}, err error)
+}
+
+// PurgeRepository is a repository that can remove old items
+type PurgeRepository interface {
+	Repository
+
+	// Purge remove items where field is before date, and return the number of removed items.
+	// The data is a pointer on model
+	Purge(ctx context.Context, field string, before time.Time, data interface{}) (int64, error)
+}
+
+// ConditionalRepository is a repository that can update item only if it was not updated since it was read
+type ConditionalRepository interface {
+	Repository
+
+	// UpdateIfVersion update item only if the stored item has the version, else it return ErrVersionConflict
+	UpdateIfVersion(ctx context.Context, data interface{}, version int64) error
 }
 
 // IsRecordNotFoundError return true if current error is because of record not found on repository
 func IsRecordNotFoundError(err error) bool {
 	return err == ErrRecordNotFoundError
 }
+
+// IsVersionConflictError return true if current error is because of record was updated by another writer
+func IsVersionConflictError(err error) bool {
+	return errors.Is(err, ErrVersionConflict)
+}
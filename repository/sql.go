@@ -3,10 +3,14 @@ package repository
 import (
 	"context"
 	"reflect"
+	"strings"
+	"time"
 
+	"github.com/disaster37/gobot-fat/models"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SQLRepositoryGen represent generic repository to request SQL database
@@ -84,6 +88,34 @@ func (h *SQLRepositoryGen) Update(ctx context.Context, data interface{}) error {
 	return nil
 }
 
+// UpdateIfVersion update item on SQL database only if the stored item has the version
+func (h *SQLRepositoryGen) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+
+	if data == nil {
+		return errors.New("Data can't be null")
+	}
+	if reflect.TypeOf(data).Kind() != reflect.Ptr {
+		return errors.New("Data must a pointer")
+	}
+	log.Debugf("Data: %s", data)
+
+	res := h.Conn.Model(data).Where("version = ?", version).Select("*").Updates(data)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+
+	// Nothing updated, item not exist or has another version
+	current := reflect.New(reflect.TypeOf(data).Elem()).Interface()
+	if err := h.Get(ctx, data.(models.Model).GetID(), current); err != nil {
+		return err
+	}
+
+	return ErrVersionConflict
+}
+
 // Create add new item on SQL database
 func (h *SQLRepositoryGen) Create(ctx context.Context, data interface{}) error {
 	if data == nil {
@@ -101,3 +133,183 @@ func (h *SQLRepositoryGen) Create(ctx context.Context, data interface{}) error {
 
 	return nil
 }
+
+// Delete soft delete item from SQL database with ID
+// The item is kept with its deleted date, like other deleted items. Use Purge to remove it.
+func (h *SQLRepositoryGen) Delete(ctx context.Context, id uint, data interface{}) error {
+	if data == nil {
+		return errors.New("Data can't be null")
+	}
+	if reflect.TypeOf(data).Kind() != reflect.Ptr {
+		return errors.New("Data must a pointer")
+	}
+
+	res := h.Conn.WithContext(ctx).Model(data).
+		Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).
+		Where(clause.Eq{Column: clause.Column{Name: "deleted_at"}, Value: nil}).
+		UpdateColumn("deleted_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFoundError
+	}
+
+	return nil
+}
+
+// Search return items that match query
+// Query fields are the JSON names of model, like on Elasticsearch
+func (h *SQLRepositoryGen) Search(ctx context.Context, query *Query, listData interface{}) (total int64, err error) {
+
+	if query == nil {
+		return 0, errors.New("Query can't be null")
+	}
+	if listData == nil {
+		return 0, errors.New("ListData can't be null")
+	}
+	if reflect.TypeOf(listData).Kind() != reflect.Ptr {
+		return 0, errors.New("ListData must be a pointer")
+	}
+	if reflect.TypeOf(listData).Elem().Kind() != reflect.Slice {
+		return 0, errors.New("ListData must contain slice")
+	}
+
+	itemType := reflect.TypeOf(listData).Elem().Elem()
+	if itemType.Kind() == reflect.Ptr {
+		itemType = itemType.Elem()
+	}
+	model := reflect.New(itemType).Interface()
+
+	columns, err := h.columns(model)
+	if err != nil {
+		return 0, err
+	}
+	column := func(field string) (clause.Column, error) {
+		name, ok := columns[field]
+		if !ok {
+			return clause.Column{}, errors.Errorf("Field %s not found", field)
+		}
+		return clause.Column{Name: name}, nil
+	}
+
+	tx := h.Conn.WithContext(ctx).Model(model)
+	for field, value := range query.Filters {
+		col, err := column(field)
+		if err != nil {
+			return 0, err
+		}
+		tx = tx.Where(clause.Eq{Column: col, Value: value})
+	}
+	for _, field := range query.Exists {
+		col, err := column(field)
+		if err != nil {
+			return 0, err
+		}
+		tx = tx.Where(clause.Neq{Column: col, Value: ""})
+	}
+	if query.TimeField != "" && (!query.From.IsZero() || !query.To.IsZero()) {
+		col, err := column(query.TimeField)
+		if err != nil {
+			return 0, err
+		}
+		if !query.From.IsZero() {
+			tx = tx.Where(clause.Gte{Column: col, Value: query.From})
+		}
+		if !query.To.IsZero() {
+			tx = tx.Where(clause.Lte{Column: col, Value: query.To})
+		}
+	}
+	tx = tx.Session(&gorm.Session{})
+
+	if err = tx.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	if query.SortField != "" {
+		col, err := column(query.SortField)
+		if err != nil {
+			return 0, err
+		}
+		tx = tx.Order(clause.OrderByColumn{Column: col, Desc: query.SortDesc})
+	}
+	if query.Size > 0 {
+		tx = tx.Limit(query.Size)
+	}
+	if err = tx.Offset(query.Offset).Find(listData).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// Bulk create all items on one transaction
+// When it failed, all items are failed
+func (h *SQLRepositoryGen) Bulk(ctx context.Context, listData []interface{}) (failed []interface{}, err error) {
+	if len(listData) == 0 {
+		return nil, nil
+	}
+
+	err = h.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, data := range listData {
+			if err := tx.Create(data).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return listData, err
+	}
+
+	return nil, nil
+}
+
+// Purge remove items where field is before date
+// The data is a pointer on model, to know the table
+func (h *SQLRepositoryGen) Purge(ctx context.Context, field string, before time.Time, data interface{}) (int64, error) {
+	if data == nil {
+		return 0, errors.New("Data can't be null")
+	}
+	if reflect.TypeOf(data).Kind() != reflect.Ptr {
+		return 0, errors.New("Data must a pointer")
+	}
+
+	columns, err := h.columns(data)
+	if err != nil {
+		return 0, err
+	}
+	name, ok := columns[field]
+	if !ok {
+		return 0, errors.Errorf("Field %s not found", field)
+	}
+
+	res := h.Conn.WithContext(ctx).Where(clause.Lt{Column: clause.Column{Name: name}, Value: before}).Delete(data)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
+// columns return the columns name of model by JSON name
+func (h *SQLRepositoryGen) columns(model interface{}) (map[string]string, error) {
+	stmt := &gorm.Statement{DB: h.Conn}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]string, len(stmt.Schema.Fields))
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.DBName
+		}
+		columns[name] = field.DBName
+	}
+
+	return columns, nil
+}
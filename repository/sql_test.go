@@ -212,3 +212,32 @@ func TestCreateSQL(t *testing.T) {
 	err = repository.Create(context.Background(), nil)
 	assert.Error(t, err)
 }
+
+func TestDeleteSQL(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "dfpconfig" SET "deleted_at"=\$1 WHERE "dfpconfig"."id" = \$2 AND "deleted_at" IS NULL`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dbMock}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository := NewSQLRepository(db)
+
+	err = repository.Delete(context.Background(), 1, &models.DFPConfig{})
+	assert.NoError(t, err)
+
+	// When record not found
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "dfpconfig" SET "deleted_at"=\$1 WHERE "dfpconfig"."id" = \$2 AND "deleted_at" IS NULL`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	err = repository.Delete(context.Background(), 1, &models.DFPConfig{})
+	assert.ErrorIs(t, err, ErrRecordNotFoundError)
+
+	// When record is nil
+	err = repository.Delete(context.Background(), 1, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/glebarez/sqlite"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// OpenSQLite open the SQLite database file, it's created with its directory if needed.
+// The connection can be used with NewSQLRepository.
+// Only one connection is open because of SQLite lock the whole database on write.
+func OpenSQLite(path string) (*gorm.DB, error) {
+
+	if path == "" {
+		return nil, errors.New("SQLite path can't be empty")
+	}
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return nil, errors.Wrap(err, "Error when create SQLite directory")
+		}
+	}
+
+	conn, err := gorm.Open(sqlite.Open(path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"), &gorm.Config{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when open SQLite database")
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	return conn, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLite(t *testing.T) {
+
+	conn, err := OpenSQLite(filepath.Join(t.TempDir(), "data", "gobot-fat.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.AutoMigrate(&models.DFPConfig{}, &models.DFPState{}, &models.TFPConfig{}, &models.TFPState{}, &models.TankConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	repository := NewSQLRepository(conn)
+
+	// Create
+	tankConfig := &models.TankConfig{
+		Name:       "tank_pond",
+		Depth:      100,
+		LiterPerCm: 10,
+	}
+	err = repository.Create(context.Background(), tankConfig)
+	assert.NoError(t, err)
+	assert.NotZero(t, tankConfig.ID)
+
+	// Get
+	result := &models.TankConfig{}
+	err = repository.Get(context.Background(), tankConfig.ID, result)
+	assert.NoError(t, err)
+	assert.Equal(t, "tank_pond", result.Name)
+	assert.Equal(t, int64(100), result.Depth)
+
+	// Update
+	result.Depth = 150
+	err = repository.Update(context.Background(), result)
+	assert.NoError(t, err)
+	err = repository.Get(context.Background(), tankConfig.ID, result)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(150), result.Depth)
+
+	// Update if version
+	result.Depth = 200
+	result.Version = 1
+	err = repository.(ConditionalRepository).UpdateIfVersion(context.Background(), result, 0)
+	assert.NoError(t, err)
+	err = repository.(ConditionalRepository).UpdateIfVersion(context.Background(), result, 0)
+	assert.True(t, IsVersionConflictError(err))
+	err = repository.Get(context.Background(), tankConfig.ID, result)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), result.Depth)
+	assert.Equal(t, int64(1), result.Version)
+	err = repository.(ConditionalRepository).UpdateIfVersion(context.Background(), &models.TankConfig{ID: 999}, 0)
+	assert.True(t, IsRecordNotFoundError(err))
+
+	// List
+	list := make([]*models.TankConfig, 0)
+	err = repository.List(context.Background(), &list)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	// Delete keep item as deleted
+	err = repository.Delete(context.Background(), tankConfig.ID, &models.TankConfig{})
+	assert.NoError(t, err)
+	err = repository.Get(context.Background(), tankConfig.ID, result)
+	assert.NoError(t, err)
+	assert.NotNil(t, result.DeletedAt)
+	err = repository.Delete(context.Background(), tankConfig.ID, &models.TankConfig{})
+	assert.True(t, IsRecordNotFoundError(err))
+
+	// Purge remove deleted item
+	nb, err := repository.(PurgeRepository).Purge(context.Background(), "deleted_at", time.Now().Add(1*time.Second), &models.TankConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), nb)
+	err = repository.Get(context.Background(), tankConfig.ID, result)
+	assert.True(t, IsRecordNotFoundError(err))
+
+	// When path is empty
+	_, err = OpenSQLite("")
+	assert.Error(t, err)
+}
+
+func TestSearchSQL(t *testing.T) {
+
+	conn, err := OpenSQLite(filepath.Join(t.TempDir(), "gobot-fat.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.AutoMigrate(&models.Event{}); err != nil {
+		t.Fatal(err)
+	}
+	repository := NewSQLRepository(conn)
+	now := time.Now()
+
+	// Bulk
+	failed, err := repository.(BulkRepository).Bulk(context.Background(), []interface{}{
+		&models.Event{SourceName: "dfp", EventKind: "wash", EventType: "dfp", Timestamp: now.Add(-3 * time.Hour), Duration: 10},
+		&models.Event{SourceName: "dfp", EventKind: "wash", EventType: "dfp", Timestamp: now.Add(-2 * time.Hour), Duration: 20, User: "admin", Origin: "api"},
+		&models.Event{SourceName: "tfp", EventKind: "start", EventType: "uvc1", Timestamp: now.Add(-1 * time.Hour)},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+
+	// Search with filters and sort
+	events := make([]*models.Event, 0)
+	total, err := repository.(SearchRepository).Search(context.Background(), &Query{
+		Filters:   map[string]interface{}{"source_name": "dfp", "kind": "wash"},
+		SortField: "timestamp",
+		SortDesc:  true,
+		Size:      1,
+	}, &events)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, events, 1)
+	assert.Equal(t, int64(20), events[0].Duration)
+
+	// Search with pagination, time range and exists
+	events = make([]*models.Event, 0)
+	total, err = repository.(SearchRepository).Search(context.Background(), &Query{
+		TimeField: "timestamp",
+		From:      now.Add(-150 * time.Minute),
+		SortField: "timestamp",
+		Offset:    1,
+		Size:      10,
+	}, &events)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "tfp", events[0].SourceName)
+
+	events = make([]*models.Event, 0)
+	total, err = repository.(SearchRepository).Search(context.Background(), &Query{Exists: []string{"origin"}}, &events)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "admin", events[0].User)
+
+	// Unknown field
+	_, err = repository.(SearchRepository).Search(context.Background(), &Query{SortField: "bad"}, &events)
+	assert.Error(t, err)
+
+	// Purge
+	nb, err := repository.(PurgeRepository).Purge(context.Background(), "timestamp", now.Add(-90*time.Minute), &models.Event{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), nb)
+	events = make([]*models.Event, 0)
+	assert.NoError(t, repository.List(context.Background(), &events))
+	assert.Len(t, events, 1)
+}
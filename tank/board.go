@@ -12,3 +12,6 @@ type Board interface {
 	GetData(ctx context.Context) (data *models.Tank, err error)
 	board.Board
 }
+
+// Builder create the board of tank config
+type Builder func(config *models.TankConfig) Board
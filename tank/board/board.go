@@ -6,6 +6,7 @@ import (
 
 	"github.com/disaster37/gobot-arest/v2/drivers/extra"
 	"github.com/disaster37/gobot-arest/v2/plateforms/arest"
+	"github.com/disaster37/gobot-fat/board"
 	"github.com/disaster37/gobot-fat/helper"
 	"github.com/disaster37/gobot-fat/mock"
 	"github.com/disaster37/gobot-fat/models"
@@ -18,11 +19,17 @@ import (
 )
 
 const (
-	EventNewDistance  = "new-distance"
-	EventNewConfig    = "new-config"
-	EventBoardReboot  = "board-reboot"
-	EventBoardOffline = "board-offline"
-	EventBoardStop    = "board-stop"
+	EventNewDistance    = "new-distance"
+	EventNewConfig      = "new-config"
+	EventBoardReboot    = "board-reboot"
+	EventBoardOffline   = "board-offline"
+	EventBoardStop      = "board-stop"
+	EventSetLowLevel    = "set-low-level"
+	EventUnsetLowLevel  = "unset-low-level"
+	EventSetHighLevel   = "set-high-level"
+	EventUnsetHighLevel = "unset-high-level"
+	EventSetDryRun      = "set-dry-run"
+	EventUnsetDryRun    = "unset-dry-run"
 )
 
 type TankAdaptor interface {
@@ -43,6 +50,8 @@ type TankBoard struct {
 	name             string
 	isOnline         bool
 	isInitialized    bool
+	isLevelSeeded    bool
+	poll             *pollAdaptor
 	valueRebooted    *extra.ValueDriver
 	valueDistance    *extra.ValueDriver
 	functionRebooted *extra.FunctionDriver
@@ -70,6 +79,9 @@ func NewTank(configHandler *viper.Viper, config *models.TankConfig, eventUsecase
 
 func newTank(board TankAdaptor, configHandler *viper.Viper, config *models.TankConfig, eventUsecase usecase.UsecaseCRUD, eventer gobot.Eventer, wait time.Duration) (tankHandler tank.Board) {
 
+	// Values are read through poll, to know when board answered for the last time
+	poll := newPollAdaptor(board, configHandler.GetString("name"))
+
 	// Create struct
 	tankBoard := &TankBoard{
 		board:         board,
@@ -83,8 +95,9 @@ func newTank(board TankAdaptor, configHandler *viper.Viper, config *models.TankC
 		isOnline:         false,
 		isInitialized:    false,
 		globalEventer:    eventer,
-		valueRebooted:    extra.NewValueDriver(board, "isRebooted", wait),
-		valueDistance:    extra.NewValueDriver(board, "distance", wait),
+		poll:             poll,
+		valueRebooted:    extra.NewValueDriver(poll, "isRebooted", wait),
+		valueDistance:    extra.NewValueDriver(poll, "distance", wait),
 		functionRebooted: extra.NewFunctionDriver(board, "acknoledgeRebooted", ""),
 		Eventer:          gobot.NewEventer(),
 	}
@@ -105,6 +118,12 @@ func newTank(board TankAdaptor, configHandler *viper.Viper, config *models.TankC
 	tankBoard.AddEvent(EventBoardReboot)
 	tankBoard.AddEvent(EventBoardOffline)
 	tankBoard.AddEvent(EventBoardStop)
+	tankBoard.AddEvent(EventSetLowLevel)
+	tankBoard.AddEvent(EventUnsetLowLevel)
+	tankBoard.AddEvent(EventSetHighLevel)
+	tankBoard.AddEvent(EventUnsetHighLevel)
+	tankBoard.AddEvent(EventSetDryRun)
+	tankBoard.AddEvent(EventUnsetDryRun)
 
 	log.Infof("Board %s initialized successfully", tankBoard.Name())
 
@@ -119,10 +138,19 @@ func (h *TankBoard) Name() string {
 
 // Board get board info as object
 func (h *TankBoard) Board() *models.Board {
-	return &models.Board{
-		Name:     h.name,
-		IsOnline: h.isOnline,
+	data := &models.Board{
+		Name:          h.name,
+		IsOnline:      h.isOnline,
+		IsInitialized: h.isInitialized,
 	}
+	h.poll.Fill(data)
+
+	return data
+}
+
+// Diagnostics return the board diagnostics
+func (h *TankBoard) Diagnostics() *board.Diagnostics {
+	return h.poll.Diagnostics
 }
 
 // GetData permit to read current level on tank
@@ -46,6 +46,10 @@ func (s *TankBoardTestSuite) SetupTest() {
 		LiterPerCm:   1,
 		SensorHeight: 0,
 	}
+	s.board.data.IsLowLevel = false
+	s.board.data.IsHighLevel = false
+	s.board.data.IsDryRun = false
+	s.board.isLevelSeeded = true
 }
 
 func (s *TankBoardTestSuite) TestStartStopIsOnline() {
@@ -63,6 +67,9 @@ func (s *TankBoardTestSuite) TestStartStopIsOnline() {
 func (s *TankBoardTestSuite) TestGetBoard() {
 	assert.Equal(s.T(), "test", s.board.Board().Name)
 	assert.True(s.T(), s.board.Board().IsOnline)
+	assert.True(s.T(), s.board.Board().IsInitialized)
+	assert.Eventually(s.T(), func() bool { return s.board.Board().LastPoll != nil }, 5*time.Second, 100*time.Millisecond)
+	assert.Greater(s.T(), s.board.Diagnostics().Diagnostic().Requests, int64(0))
 }
 
 func (s *TankBoardTestSuite) TestName() {
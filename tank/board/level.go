@@ -0,0 +1,122 @@
+package tankboard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/disaster37/gobot-fat/helper"
+	log "github.com/sirupsen/logrus"
+)
+
+// handleLevelAlerts check the current percent against low and high thresholds.
+// An alert is raised when a threshold is crossed, and it's released only when the level
+// come back over the threshold plus the hysteresis to avoid flapping on sensor noise.
+// The first reading after start only seed the alerts without notify, so alerts are not sent again on each restart.
+// It return true if an alert changed
+func (h *TankBoard) handleLevelAlerts(ctx context.Context) (isChanged bool) {
+
+	percent := h.data.Percent
+	low := float64(h.config.LowLevelThreshold)
+	high := float64(h.config.HighLevelThreshold)
+	hysteresis := float64(h.config.LevelHysteresis)
+
+	if !h.isLevelSeeded {
+		h.data.IsLowLevel = h.config.LowLevelThreshold > 0 && percent <= low
+		h.data.IsHighLevel = h.config.HighLevelThreshold > 0 && percent >= high
+		h.isLevelSeeded = true
+		log.Debugf("Level alerts of tank %s seeded from first reading: low=%t, high=%t", h.name, h.data.IsLowLevel, h.data.IsHighLevel)
+		return false
+	}
+
+	// Low level
+	if !h.data.IsLowLevel && h.config.LowLevelThreshold > 0 && percent <= low {
+		h.data.IsLowLevel = true
+		h.sendLevelAlert(ctx, helper.KindEventSetTankLowLevel, EventSetLowLevel, helper.SetTankLowLevel,
+			fmt.Sprintf("Tank %s is on low level", h.name),
+			fmt.Sprintf("The tank %s is at %.0f%% (%d liters), under the low level threshold of %d%%", h.name, percent, h.data.Volume, h.config.LowLevelThreshold),
+		)
+		isChanged = true
+	} else if h.data.IsLowLevel && (h.config.LowLevelThreshold <= 0 || percent >= low+hysteresis) {
+		h.data.IsLowLevel = false
+		h.sendLevelAlert(ctx, helper.KindEventUnsetTankLowLevel, EventUnsetLowLevel, helper.UnsetTankLowLevel,
+			fmt.Sprintf("Tank %s is no more on low level", h.name),
+			fmt.Sprintf("The tank %s is at %.0f%% (%d liters)", h.name, percent, h.data.Volume),
+		)
+		isChanged = true
+	}
+
+	// High level
+	if !h.data.IsHighLevel && h.config.HighLevelThreshold > 0 && percent >= high {
+		h.data.IsHighLevel = true
+		h.sendLevelAlert(ctx, helper.KindEventSetTankHighLevel, EventSetHighLevel, helper.SetTankHighLevel,
+			fmt.Sprintf("Tank %s is on high level", h.name),
+			fmt.Sprintf("The tank %s is at %.0f%% (%d liters), upper the high level threshold of %d%%", h.name, percent, h.data.Volume, h.config.HighLevelThreshold),
+		)
+		isChanged = true
+	} else if h.data.IsHighLevel && (h.config.HighLevelThreshold <= 0 || percent <= high-hysteresis) {
+		h.data.IsHighLevel = false
+		h.sendLevelAlert(ctx, helper.KindEventUnsetTankHighLevel, EventUnsetHighLevel, helper.UnsetTankHighLevel,
+			fmt.Sprintf("Tank %s is no more on high level", h.name),
+			fmt.Sprintf("The tank %s is at %.0f%% (%d liters)", h.name, percent, h.data.Volume),
+		)
+		isChanged = true
+	}
+
+	return isChanged
+}
+
+// sendLevelAlert notify the level change by event, internal and global event.
+// The global event carry a copy of tank data, so other boards can know the tank name
+func (h *TankBoard) sendLevelAlert(ctx context.Context, kind string, internalEvent string, globalEvent string, title string, content string) {
+	log.Infof("%s: %s", title, content)
+
+	// Send event
+	helper.SendEventWithMessage(ctx, h.eventUsecase, h.name, kind, h.name, fmt.Sprintf("%s. %s", title, content), int64(h.data.Level))
+
+	// Publish internal and global event
+	data := *h.data
+	h.Publish(internalEvent, &data)
+	h.globalEventer.Publish(globalEvent, &data)
+}
+
+// handleDryRun check the current percent against the dry run thresholds.
+// The global event let the boards that pump in this tank stop and restart their pumps.
+// The dry run state is published again on each reading, so boards started after the change know it.
+// It return true if the dry run state changed
+func (h *TankBoard) handleDryRun(ctx context.Context) (isChanged bool) {
+
+	percent := h.data.Percent
+	threshold := h.config.DryRunThreshold
+	restartThreshold := h.config.DryRunRestartThreshold
+	if restartThreshold < threshold {
+		restartThreshold = threshold
+	}
+
+	if !h.data.IsDryRun && threshold > 0 && percent <= float64(threshold) {
+		h.data.IsDryRun = true
+		h.sendLevelAlert(ctx, helper.KindEventSetDryRun, EventSetDryRun, helper.SetDryRun,
+			fmt.Sprintf("Tank %s is too low to run pumps", h.name),
+			fmt.Sprintf("The tank %s is at %.0f%% (%d liters), under the dry run threshold of %d%%. Pumps are stopped until it reach %d%%", h.name, percent, h.data.Volume, threshold, restartThreshold),
+		)
+		return true
+	}
+
+	if h.data.IsDryRun && (threshold <= 0 || percent >= float64(restartThreshold)) {
+		h.data.IsDryRun = false
+		h.sendLevelAlert(ctx, helper.KindEventUnsetDryRun, EventUnsetDryRun, helper.UnsetDryRun,
+			fmt.Sprintf("Tank %s can run pumps again", h.name),
+			fmt.Sprintf("The tank %s is at %.0f%% (%d liters)", h.name, percent, h.data.Volume),
+		)
+		return true
+	}
+
+	// Publish the current dry run state without notify
+	data := *h.data
+	if h.data.IsDryRun {
+		h.globalEventer.Publish(helper.SetDryRun, &data)
+	} else {
+		h.globalEventer.Publish(helper.UnsetDryRun, &data)
+	}
+
+	return false
+}
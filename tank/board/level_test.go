@@ -0,0 +1,165 @@
+package tankboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/mock"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *TankBoardTestSuite) TestHandleLevelAlerts() {
+	waitDuration := 100 * time.Millisecond
+	eventUsecase := &mock.MockEventUsecase{}
+	defaultEventUsecase := s.board.eventUsecase
+	s.board.eventUsecase = eventUsecase
+	defer func() { s.board.eventUsecase = defaultEventUsecase }()
+
+	s.board.config.LowLevelThreshold = 20
+	s.board.config.HighLevelThreshold = 90
+	s.board.config.LevelHysteresis = 5
+
+	// Normal level
+	s.board.data.Percent = 50
+	assert.False(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.Empty(s.T(), eventUsecase.Events())
+
+	// Low level
+	status := mock.WaitEvent(s.board, EventSetLowLevel, waitDuration)
+	globalStatus := mock.WaitEvent(s.board.globalEventer, helper.SetTankLowLevel, waitDuration)
+	s.board.data.Percent = 20
+	assert.True(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.True(s.T(), <-status)
+	assert.True(s.T(), <-globalStatus)
+	assert.True(s.T(), s.board.data.IsLowLevel)
+	assert.Equal(s.T(), 1, eventUsecase.Kinds(helper.KindEventSetTankLowLevel))
+
+	// Still low level in hysteresis
+	s.board.data.Percent = 24
+	assert.False(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.True(s.T(), s.board.data.IsLowLevel)
+
+	// Leave low level
+	status = mock.WaitEvent(s.board, EventUnsetLowLevel, waitDuration)
+	globalStatus = mock.WaitEvent(s.board.globalEventer, helper.UnsetTankLowLevel, waitDuration)
+	s.board.data.Percent = 25
+	assert.True(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.True(s.T(), <-status)
+	assert.True(s.T(), <-globalStatus)
+	assert.False(s.T(), s.board.data.IsLowLevel)
+	assert.Equal(s.T(), 1, eventUsecase.Kinds(helper.KindEventUnsetTankLowLevel))
+	assert.Contains(s.T(), eventUsecase.Events()[1].Message, "25%")
+
+	// High level
+	status = mock.WaitEvent(s.board, EventSetHighLevel, waitDuration)
+	globalStatus = mock.WaitEvent(s.board.globalEventer, helper.SetTankHighLevel, waitDuration)
+	s.board.data.Percent = 95
+	assert.True(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.True(s.T(), <-status)
+	assert.True(s.T(), <-globalStatus)
+	assert.True(s.T(), s.board.data.IsHighLevel)
+
+	// Still high level in hysteresis
+	s.board.data.Percent = 86
+	assert.False(s.T(), s.board.handleLevelAlerts(context.Background()))
+
+	// Leave high level
+	s.board.data.Percent = 85
+	assert.True(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.False(s.T(), s.board.data.IsHighLevel)
+
+	// Alert is released when threshold is disabled
+	status = mock.WaitEvent(s.board.globalEventer, helper.SetTankLowLevel, waitDuration)
+	s.board.data.Percent = 10
+	assert.True(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.True(s.T(), <-status)
+	status = mock.WaitEvent(s.board.globalEventer, helper.UnsetTankLowLevel, waitDuration)
+	s.board.config.LowLevelThreshold = 0
+	assert.True(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.True(s.T(), <-status)
+	assert.False(s.T(), s.board.data.IsLowLevel)
+}
+
+func (s *TankBoardTestSuite) TestSeedLevelAlerts() {
+	eventUsecase := &mock.MockEventUsecase{}
+	defaultEventUsecase := s.board.eventUsecase
+	s.board.eventUsecase = eventUsecase
+	defer func() { s.board.eventUsecase = defaultEventUsecase }()
+
+	s.board.config.LowLevelThreshold = 20
+	s.board.config.HighLevelThreshold = 90
+	s.board.config.LevelHysteresis = 5
+
+	// First reading after restart set alert without notify
+	s.board.isLevelSeeded = false
+	s.board.data.Percent = 10
+	assert.False(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.True(s.T(), s.board.data.IsLowLevel)
+	assert.False(s.T(), s.board.data.IsHighLevel)
+	assert.Empty(s.T(), eventUsecase.Events())
+
+	// Still low level, no alert sent again
+	s.board.data.Percent = 12
+	assert.False(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.Empty(s.T(), eventUsecase.Events())
+
+	// Leave low level is notified
+	s.board.data.Percent = 30
+	assert.True(s.T(), s.board.handleLevelAlerts(context.Background()))
+	assert.Equal(s.T(), 1, eventUsecase.Kinds(helper.KindEventUnsetTankLowLevel))
+}
+
+func (s *TankBoardTestSuite) TestLevelAlertsOnDistance() {
+	waitDuration := 100 * time.Millisecond
+	s.board.config = &models.TankConfig{
+		Depth:             100,
+		LiterPerCm:        1,
+		SensorHeight:      0,
+		LowLevelThreshold: 20,
+		LevelHysteresis:   5,
+	}
+
+	status := mock.WaitEvent(s.board.globalEventer, helper.SetTankLowLevel, waitDuration)
+	s.adaptor.SetValueReadState("distance", float64(90))
+	assert.True(s.T(), <-status)
+	assert.True(s.T(), s.board.data.IsLowLevel)
+}
+
+func (s *TankBoardTestSuite) TestHandleDryRun() {
+	waitDuration := 100 * time.Millisecond
+	s.board.config.DryRunThreshold = 10
+	s.board.config.DryRunRestartThreshold = 20
+
+	// Enough water
+	s.board.data.Percent = 50
+	assert.False(s.T(), s.board.handleDryRun(context.Background()))
+
+	// Dry run
+	status := mock.WaitEvent(s.board, EventSetDryRun, waitDuration)
+	globalStatus := mock.WaitEvent(s.board.globalEventer, helper.SetDryRun, waitDuration)
+	s.board.data.Percent = 10
+	assert.True(s.T(), s.board.handleDryRun(context.Background()))
+	assert.True(s.T(), <-status)
+	assert.True(s.T(), <-globalStatus)
+	assert.True(s.T(), s.board.data.IsDryRun)
+
+	// Wait restart threshold, dry run is published again without internal event
+	status = mock.WaitEvent(s.board, EventSetDryRun, waitDuration)
+	globalStatus = mock.WaitEvent(s.board.globalEventer, helper.SetDryRun, waitDuration)
+	s.board.data.Percent = 19
+	assert.False(s.T(), s.board.handleDryRun(context.Background()))
+	assert.False(s.T(), <-status)
+	assert.True(s.T(), <-globalStatus)
+	assert.True(s.T(), s.board.data.IsDryRun)
+
+	// Restart
+	status = mock.WaitEvent(s.board, EventUnsetDryRun, waitDuration)
+	globalStatus = mock.WaitEvent(s.board.globalEventer, helper.UnsetDryRun, waitDuration)
+	s.board.data.Percent = 20
+	assert.True(s.T(), s.board.handleDryRun(context.Background()))
+	assert.True(s.T(), <-status)
+	assert.True(s.T(), <-globalStatus)
+	assert.False(s.T(), s.board.data.IsDryRun)
+}
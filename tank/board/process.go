@@ -25,8 +25,12 @@ func (h *TankBoard) work() {
 			// Publish internal event
 			h.Publish(EventNewConfig, tankConfig)
 
-			// Compute new values
-			h.computeTankLevel(float64(h.data.Distance))
+			// Compute new values, only if distance is already read
+			if h.isLevelSeeded {
+				h.computeTankLevel(float64(h.data.Distance))
+				h.handleLevelAlerts(ctx)
+				h.handleDryRun(ctx)
+			}
 		}
 	})
 
@@ -39,6 +43,7 @@ func (h *TankBoard) work() {
 		if isRebooted {
 			// Board rebooted
 			log.Infof("Detect board %s is rebooted", h.name)
+			h.poll.Rebooted()
 
 			// Force reconnect to init pin and set output as expected
 			if err := h.board.Reconnect(); err != nil {
@@ -60,9 +65,16 @@ func (h *TankBoard) work() {
 
 	// Handle board error / offline
 	h.on(h.valueRebooted, extra.Error, func(s interface{}) {
+		err := s.(error)
+
+		// Count only when board go offline, error is fired on each read while it's offline
+		if h.isOnline {
+			h.poll.Offline(err)
+		} else {
+			h.poll.Failed(err)
+		}
 		h.isOnline = false
 
-		err := s.(error)
 		log.Errorf("Board %s is offline: %s", h.name, err.Error())
 
 		// Send offline event
@@ -83,6 +95,12 @@ func (h *TankBoard) work() {
 		// Send event
 		helper.SendEvent(ctx, h.eventUsecase, h.name, helper.KindEventTankLevel, "level", int64(h.data.Level))
 
+		// Check low and high level
+		h.handleLevelAlerts(ctx)
+
+		// Check the pumps can run
+		h.handleDryRun(ctx)
+
 		// Publish internal event
 		h.Publish(EventNewDistance, int64(s.(float64)))
 	})
@@ -91,6 +109,7 @@ func (h *TankBoard) work() {
 	h.on(h.valueDistance, extra.Error, func(s interface{}) {
 		err := s.(error)
 		log.Errorf("Error when read value distance on board %s: %s", h.name, err.Error())
+		h.poll.Failed(err)
 	})
 
 	h.isInitialized = true
@@ -41,14 +41,27 @@ func (s *TankBoardTestSuite) TestWork() {
 		isReconnectCalled = true
 		return nil
 	})
+	rebootCount := s.board.Board().RebootCount
 	status = mock.WaitEvent(s.board, EventBoardReboot, waitDuration)
 	s.adaptor.SetValueReadState("isRebooted", true)
 	assert.True(s.T(), <-status)
 	assert.True(s.T(), isReconnectCalled)
+	assert.Equal(s.T(), rebootCount+1, s.board.Board().RebootCount)
 
 	// Check offline
+	offlineCount := s.board.Board().OfflineCount
 	status = mock.WaitEvent(s.board, EventBoardOffline, waitDuration)
 	s.board.valueRebooted.Publish(extra.Error, errors.New("test"))
 	assert.True(s.T(), <-status)
 	assert.False(s.T(), s.board.IsOnline())
+	assert.Equal(s.T(), offlineCount+1, s.board.Board().OfflineCount)
+	assert.Equal(s.T(), "test", s.board.Board().LastError)
+	assert.NotNil(s.T(), s.board.Board().LastErrorAt)
+
+	// Count offline only once while board is offline
+	status = mock.WaitEvent(s.board, EventBoardOffline, waitDuration)
+	s.board.valueRebooted.Publish(extra.Error, errors.New("test2"))
+	assert.True(s.T(), <-status)
+	assert.Equal(s.T(), offlineCount+1, s.board.Board().OfflineCount)
+	assert.Equal(s.T(), "test2", s.board.Board().LastError)
 }
@@ -4,10 +4,38 @@ import (
 	"context"
 
 	"github.com/disaster37/gobot-fat/models"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidTank is returned when tank can't be created because of bad value
+	ErrInvalidTank = errors.New("Invalid tank")
+
+	// ErrTankNotFound is returned when there are no tank with this ID
+	ErrTankNotFound = errors.New("Tank not found")
+
+	// ErrTankAlreadyExist is returned when tank or board name is already used
+	ErrTankAlreadyExist = errors.New("Tank already exist")
+
+	// ErrTankDeclared is returned when delete tank declared on config file
+	ErrTankDeclared = errors.New("Tank is declared on config file, remove it from config")
 )
 
 // Usecase represent the tfp usecase
 type Usecase interface {
 	Tanks(ctx context.Context) (values map[string]*models.Tank, err error)
 	Tank(ctx context.Context, name string) (value *models.Tank, err error)
+
+	// AddBoard add tank board on list
+	AddBoard(board Board)
+
+	// Create save the config of new tank, then create and start its board
+	Create(ctx context.Context, config *models.TankConfig) error
+
+	// Delete stop and remove the board of tank created over API, then soft delete its config
+	Delete(ctx context.Context, id uint) error
+
+	// Load create the boards of tanks created over API, after restart
+	// They are started with the other boards
+	Load(ctx context.Context) error
 }
@@ -2,22 +2,38 @@ package usecase
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	"github.com/disaster37/gobot-fat/board"
 	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
 	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
 type tankUsecase struct {
 	tanks          []tank.Board
+	config         usecase.UsecaseCRUD
+	board          board.Usecase
+	builder        tank.Builder
 	contextTimeout time.Duration
+
+	// action serialize create and delete of tanks
+	action sync.Mutex
+	sync.RWMutex
 }
 
 // NewTankUsecase will create new tankUsecase object of tank.Usecase interface
-func NewTankUsecase(handlers []tank.Board, timeout time.Duration) tank.Usecase {
+// The builder create the boards of tanks created over API, that are added on board usecase
+func NewTankUsecase(handlers []tank.Board, config usecase.UsecaseCRUD, board board.Usecase, builder tank.Builder, timeout time.Duration) tank.Usecase {
 	return &tankUsecase{
 		tanks:          handlers,
+		config:         config,
+		board:          board,
+		builder:        builder,
 		contextTimeout: timeout,
 	}
 }
@@ -27,6 +43,8 @@ func (h *tankUsecase) Tanks(ctx context.Context) (values map[string]*models.Tank
 
 	values = make(map[string]*models.Tank)
 
+	h.RLock()
+	defer h.RUnlock()
 	for _, tank := range h.tanks {
 		data, err := tank.GetData(ctx)
 		if err != nil {
@@ -44,6 +62,8 @@ func (h *tankUsecase) Tank(ctx context.Context, name string) (value *models.Tank
 
 	log.Debugf("Name: %s", name)
 
+	h.RLock()
+	defer h.RUnlock()
 	for _, tank := range h.tanks {
 		if tank.Name() == name {
 			return tank.GetData(ctx)
@@ -52,3 +72,146 @@ func (h *tankUsecase) Tank(ctx context.Context, name string) (value *models.Tank
 
 	return nil, nil
 }
+
+// AddBoard add tank board on list
+func (h *tankUsecase) AddBoard(board tank.Board) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.tanks = append(h.tanks, board)
+}
+
+// removeBoard remove tank board from list
+func (h *tankUsecase) removeBoard(name string) {
+	h.Lock()
+	defer h.Unlock()
+
+	tanks := make([]tank.Board, 0, len(h.tanks))
+	for _, tank := range h.tanks {
+		if tank.Name() != name {
+			tanks = append(tanks, tank)
+		}
+	}
+	h.tanks = tanks
+}
+
+// Create save the config of new tank, then create and start its board
+// The ID of deleted tank with the same name is reused, to keep its history
+func (h *tankUsecase) Create(ctx context.Context, config *models.TankConfig) (err error) {
+	if config.URL == "" {
+		return errors.Wrap(tank.ErrInvalidTank, "url is required")
+	}
+
+	h.action.Lock()
+	defer h.action.Unlock()
+
+	configs := make([]*models.TankConfig, 0)
+	if err = h.config.List(ctx, &configs); err != nil {
+		return err
+	}
+
+	var maxID uint
+	config.ID = 0
+	for _, current := range configs {
+		if current.ID > maxID {
+			maxID = current.ID
+		}
+		if current.Name != config.Name {
+			continue
+		}
+		if current.DeletedAt == nil {
+			return errors.Wrapf(tank.ErrTankAlreadyExist, "tank %s", config.Name)
+		}
+		config.ID = current.ID
+		config.CreatedAt = current.CreatedAt
+		config.Version = current.Version
+	}
+	for _, b := range h.board.Boards() {
+		if b.Name() == config.Name {
+			return errors.Wrapf(tank.ErrTankAlreadyExist, "board %s", config.Name)
+		}
+	}
+
+	config.DeletedAt = nil
+	config.Origin = models.TankOriginAPI
+	if config.ID == 0 {
+		config.ID = maxID + 1
+		err = h.config.Create(ctx, config)
+	} else {
+		err = h.config.Update(ctx, config)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("Create tank %s with ID %d", config.Name, config.ID)
+
+	tankBoard := h.builder(config)
+	h.board.AddBoard(tankBoard)
+	h.AddBoard(tankBoard)
+
+	return h.board.Start(ctx, tankBoard.Name())
+}
+
+// Delete stop and remove the board of tank, then soft delete its config
+// It return ErrTankDeclared for tank declared on config file, else it come back on restart
+func (h *tankUsecase) Delete(ctx context.Context, id uint) error {
+	h.action.Lock()
+	defer h.action.Unlock()
+
+	config := &models.TankConfig{}
+	if err := h.config.Get(ctx, id, config); err != nil {
+		if repository.IsRecordNotFoundError(err) {
+			return tank.ErrTankNotFound
+		}
+		return err
+	}
+	if config.DeletedAt != nil {
+		return tank.ErrTankNotFound
+	}
+	if config.Origin != models.TankOriginAPI {
+		return tank.ErrTankDeclared
+	}
+
+	if err := h.board.RemoveBoard(ctx, config.Name); err != nil && err != board.ErrBoardNotFound {
+		return err
+	}
+	h.removeBoard(config.Name)
+
+	now := time.Now()
+	config.DeletedAt = &now
+	if err := h.config.Update(ctx, config); err != nil {
+		return err
+	}
+	log.Infof("Delete tank %s with ID %d", config.Name, config.ID)
+
+	return nil
+}
+
+// Load create the boards of tanks created over API
+// The tanks that already have board, like the tanks declared on config file, are skipped
+func (h *tankUsecase) Load(ctx context.Context) error {
+	h.action.Lock()
+	defer h.action.Unlock()
+
+	configs := make([]*models.TankConfig, 0)
+	if err := h.config.List(ctx, &configs); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, b := range h.board.Boards() {
+		names[b.Name()] = true
+	}
+
+	for _, config := range configs {
+		if config.DeletedAt != nil || config.Origin != models.TankOriginAPI || names[config.Name] {
+			continue
+		}
+		log.Infof("Load tank %s with ID %d", config.Name, config.ID)
+		tankBoard := h.builder(config)
+		h.board.AddBoard(tankBoard)
+		h.AddBoard(tankBoard)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+	boardUsecase "github.com/disaster37/gobot-fat/board/usecase"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/usecase"
+	"github.com/stretchr/testify/assert"
+	"gobot.io/x/gobot/v2"
+)
+
+type fakeTankBoard struct {
+	config      *models.TankConfig
+	diagnostics *board.Diagnostics
+}
+
+func (h *fakeTankBoard) GetData(ctx context.Context) (*models.Tank, error) {
+	return &models.Tank{}, nil
+}
+func (h *fakeTankBoard) IsOnline() bool                  { return true }
+func (h *fakeTankBoard) Name() string                    { return h.config.Name }
+func (h *fakeTankBoard) Board() *models.Board            { return &models.Board{Name: h.config.Name} }
+func (h *fakeTankBoard) Diagnostics() *board.Diagnostics { return h.diagnostics }
+func (h *fakeTankBoard) Start(ctx context.Context) error { return nil }
+func (h *fakeTankBoard) Stop(ctx context.Context) error  { return nil }
+
+func newFakeTankBoard(config *models.TankConfig) tank.Board {
+	return &fakeTankBoard{
+		config:      config,
+		diagnostics: board.NewDiagnostics(config.Name),
+	}
+}
+
+func TestCreateAndDelete(t *testing.T) {
+	conn, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "gobot-fat.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.AutoMigrate(&models.TankConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	eventer := gobot.NewEventer()
+	eventer.AddEvent("newTankConfig")
+	configUsecase := usecase.NewUsecase(repository.NewSQLRepository(conn), repository.NewNoopRepository(), 10*time.Second, eventer, "newTankConfig")
+
+	// Tank declared on config file
+	pond := &models.TankConfig{Name: "tank_pond", URL: "http://192.168.0.192", Origin: models.TankOriginConfig, Depth: 200, LiterPerCm: 50}
+	pond.ID = 1
+	assert.NoError(t, configUsecase.Init(context.Background(), pond))
+	boardU := boardUsecase.NewBoardUsecase(nil, 10*time.Second)
+	us := NewTankUsecase(make([]tank.Board, 0), configUsecase, boardU, newFakeTankBoard, 10*time.Second)
+	pondBoard := newFakeTankBoard(pond)
+	boardU.AddBoard(pondBoard)
+	us.AddBoard(pondBoard)
+
+	// Create tank with next ID and start its board
+	shed := &models.TankConfig{Name: "tank_shed", URL: "http://192.168.0.193", Depth: 100, LiterPerCm: 30}
+	assert.NoError(t, us.Create(context.Background(), shed))
+	assert.Equal(t, uint(2), shed.ID)
+	assert.Equal(t, models.TankOriginAPI, shed.Origin)
+	assert.Len(t, boardU.Boards(), 2)
+	tanks, err := us.Tanks(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, tanks, "tank_shed")
+	assert.Eventually(t, func() bool {
+		boards, _ := boardU.GetBoards(context.Background())
+		return boards[1].State == models.BoardStateRunning
+	}, 1*time.Second, 10*time.Millisecond)
+
+	// Name already used
+	err = us.Create(context.Background(), &models.TankConfig{Name: "tank_shed", URL: "http://192.168.0.194"})
+	assert.ErrorIs(t, err, tank.ErrTankAlreadyExist)
+	err = us.Create(context.Background(), &models.TankConfig{Name: "tank_pond", URL: "http://192.168.0.194"})
+	assert.ErrorIs(t, err, tank.ErrTankAlreadyExist)
+
+	// URL is required
+	err = us.Create(context.Background(), &models.TankConfig{Name: "tank_other"})
+	assert.ErrorIs(t, err, tank.ErrInvalidTank)
+
+	// Tank declared on config file can't be deleted, even with URL
+	assert.ErrorIs(t, us.Delete(context.Background(), 1), tank.ErrTankDeclared)
+	assert.ErrorIs(t, us.Delete(context.Background(), 10), tank.ErrTankNotFound)
+
+	// Board is removed and config is soft deleted
+	assert.NoError(t, us.Delete(context.Background(), 2))
+	assert.Len(t, boardU.Boards(), 1)
+	tanks, err = us.Tanks(context.Background())
+	assert.NoError(t, err)
+	assert.NotContains(t, tanks, "tank_shed")
+	config := &models.TankConfig{}
+	assert.NoError(t, configUsecase.Get(context.Background(), 2, config))
+	assert.NotNil(t, config.DeletedAt)
+	assert.ErrorIs(t, us.Delete(context.Background(), 2), tank.ErrTankNotFound)
+
+	// Deleted tank is created again with the same ID
+	shed = &models.TankConfig{Name: "tank_shed", URL: "http://192.168.0.195", Depth: 100, LiterPerCm: 30}
+	assert.NoError(t, us.Create(context.Background(), shed))
+	assert.Equal(t, uint(2), shed.ID)
+	assert.Nil(t, shed.DeletedAt)
+	assert.Len(t, boardU.Boards(), 2)
+
+	// Load create the boards of tanks created over API after restart
+	boardU = boardUsecase.NewBoardUsecase(nil, 10*time.Second)
+	us = NewTankUsecase(make([]tank.Board, 0), configUsecase, boardU, newFakeTankBoard, 10*time.Second)
+	pondBoard = newFakeTankBoard(pond)
+	boardU.AddBoard(pondBoard)
+	us.AddBoard(pondBoard)
+	assert.NoError(t, us.Load(context.Background()))
+	assert.Len(t, boardU.Boards(), 2)
+	tanks, err = us.Tanks(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, tanks, "tank_shed")
+
+	boardU.Stops(context.Background())
+}
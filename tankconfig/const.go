@@ -1,7 +1,25 @@
 package tankconfig
 
+import "github.com/disaster37/gobot-fat/models"
+
 const (
 	IDPondTank    = 1
 	IDGardenTank  = 2
 	NewTankConfig = "new-tank-config"
 )
+
+// NewDefault return the default config of new tank
+func NewDefault(name string) *models.TankConfig {
+	return &models.TankConfig{
+		Enable:                 true,
+		Name:                   name,
+		Depth:                  200,
+		SensorHeight:           20,
+		LiterPerCm:             50,
+		LowLevelThreshold:      20,
+		HighLevelThreshold:     95,
+		LevelHysteresis:        5,
+		DryRunThreshold:        10,
+		DryRunRestartThreshold: 20,
+	}
+}
@@ -6,26 +6,36 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/disaster37/gobot-fat/helper"
 	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/tankconfig"
 	"github.com/disaster37/gobot-fat/usecase"
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
 // TankConfigHandler  represent the httphandler for tank_config
 type TankConfigHandler struct {
-	us usecase.UsecaseCRUD
+	us     usecase.UsecaseCRUD
+	tankUs tank.Usecase
 }
 
 // NewTankConfigHandler will initialize the Tank_config/ resources endpoint
-func NewTankConfigHandler(e *echo.Group, us usecase.UsecaseCRUD) {
+// The tanks are created and deleted with tank usecase, to handle their board. The middlewares are only applied on create and delete
+func NewTankConfigHandler(e *echo.Group, us usecase.UsecaseCRUD, tankUs tank.Usecase, m ...echo.MiddlewareFunc) {
 	handler := &TankConfigHandler{
-		us: us,
+		us:     us,
+		tankUs: tankUs,
 	}
 	e.GET("/tank-configs", handler.List)
 	e.GET("/tank-configs/:id", handler.Get)
+	e.POST("/tank-configs", handler.Create, m...)
 	e.PATCH("/tank-configs/:id", handler.Update)
+	e.DELETE("/tank-configs/:id", handler.Delete, m...)
 }
 
 // Get will get the tank_config
@@ -49,8 +59,16 @@ func (h *TankConfigHandler) List(c echo.Context) error {
 		})
 	}
 
+	// Hide deleted tanks
+	configs := make([]*models.TankConfig, 0, len(data))
+	for _, config := range data {
+		if config.DeletedAt == nil {
+			configs = append(configs, config)
+		}
+	}
+
 	c.Response().WriteHeader(http.StatusOK)
-	return jsonapi.MarshalPayload(c.Response(), data)
+	return jsonapi.MarshalPayload(c.Response(), configs)
 }
 
 // Get will get the tank_config
@@ -75,7 +93,17 @@ func (h *TankConfigHandler) Get(c echo.Context) error {
 	}
 
 	config := &models.TankConfig{}
-	if err = h.us.Get(ctx, uint(id), config); err != nil {
+	if err = h.get(ctx, uint(id), config); err != nil {
+		if repository.IsRecordNotFoundError(err) {
+			c.Response().WriteHeader(http.StatusNotFound)
+			return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+				{
+					Status: fmt.Sprintf("%d", http.StatusNotFound),
+					Title:  "Error when get tank_config",
+					Detail: err.Error(),
+				},
+			})
+		}
 		log.Errorf("Error when get tank_config: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -87,6 +115,7 @@ func (h *TankConfigHandler) Get(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(config.Version))
 	c.Response().WriteHeader(http.StatusOK)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), config)
 }
@@ -99,8 +128,9 @@ func (h *TankConfigHandler) Update(c echo.Context) error {
 	}
 	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
 
-	config := &models.TankConfig{}
-	if err = jsonapi.UnmarshalPayload(c.Request().Body, config); err != nil {
+	// Version read by client, to not overwrite another update
+	version, isVersion, err := helper.ExpectedVersion(c.Request())
+	if err != nil {
 		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
@@ -110,6 +140,7 @@ func (h *TankConfigHandler) Update(c echo.Context) error {
 			},
 		})
 	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.Response().WriteHeader(http.StatusBadRequest)
@@ -121,11 +152,68 @@ func (h *TankConfigHandler) Update(c echo.Context) error {
 			},
 		})
 	}
+
+	// Only attributes sent by client are updated
+	config := &models.TankConfig{}
+	if err = h.get(ctx, uint(id), config); err != nil {
+		if repository.IsRecordNotFoundError(err) {
+			c.Response().WriteHeader(http.StatusNotFound)
+			return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+				{
+					Status: fmt.Sprintf("%d", http.StatusNotFound),
+					Title:  "Error when update tank_config",
+					Detail: err.Error(),
+				},
+			})
+		}
+		log.Errorf("Error when get tank_config: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
+				Title:  "Error when update tank_config",
+				Detail: err.Error(),
+			},
+		})
+	}
+	// The name, URL and origin identify the board, they can't be updated
+	name, url, origin := config.Name, config.URL, config.Origin
+	if err = jsonapi.UnmarshalPayload(c.Request().Body, config); err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  "Error when update tank_config",
+				Detail: err.Error(),
+			},
+		})
+	}
 	config.ID = uint(id)
+	config.Name, config.URL, config.Origin = name, url, origin
+
+	if err = models.Validate(config); err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return helper.MarshalValidationErrors(c.Response(), "Error when update tank_config", err)
+	}
 
 	log.Debugf("Data: %+v", config)
 
-	if err = h.us.Update(ctx, config); err != nil {
+	if isVersion {
+		err = h.us.UpdateIfVersion(ctx, config, version)
+	} else {
+		err = h.us.Update(ctx, config)
+	}
+	if err != nil {
+		if repository.IsVersionConflictError(err) {
+			c.Response().WriteHeader(http.StatusConflict)
+			return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+				{
+					Status: fmt.Sprintf("%d", http.StatusConflict),
+					Title:  "Error when update tank_config",
+					Detail: err.Error(),
+				},
+			})
+		}
 		log.Errorf("Error when update tank_config: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -137,6 +225,94 @@ func (h *TankConfigHandler) Update(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(config.Version))
+	c.Response().WriteHeader(http.StatusCreated)
+	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), config)
+}
+
+// Create will add new tank and start its board
+func (h *TankConfigHandler) Create(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	// The attributes not sent by client have default value
+	config := tankconfig.NewDefault("")
+	if err := jsonapi.UnmarshalPayload(c.Request().Body, config); err != nil {
+		return h.error(c, "Error when create tank_config", errors.Wrap(tank.ErrInvalidTank, err.Error()))
+	}
+	if config.Name == "" {
+		return h.error(c, "Error when create tank_config", errors.Wrap(tank.ErrInvalidTank, "name is required"))
+	}
+	if err := models.Validate(config); err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return helper.MarshalValidationErrors(c.Response(), "Error when create tank_config", err)
+	}
+
+	if err := h.tankUs.Create(ctx, config); err != nil {
+		return h.error(c, "Error when create tank_config", err)
+	}
+
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(config.Version))
 	c.Response().WriteHeader(http.StatusCreated)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), config)
 }
+
+// Delete will stop the board of tank and remove it
+func (h *TankConfigHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+		return h.error(c, "Error when delete tank_config", errors.Wrap(tank.ErrInvalidTank, err.Error()))
+	}
+
+	if err = h.tankUs.Delete(ctx, uint(id)); err != nil {
+		c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+		return h.error(c, "Error when delete tank_config", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// get return the tank config, or ErrRecordNotFoundError if it's deleted
+func (h *TankConfigHandler) get(ctx context.Context, id uint, config *models.TankConfig) error {
+	if err := h.us.Get(ctx, id, config); err != nil {
+		return err
+	}
+	if config.DeletedAt != nil {
+		return repository.ErrRecordNotFoundError
+	}
+
+	return nil
+}
+
+// error write the jsonapi error with the status that match the error
+func (h *TankConfigHandler) error(c echo.Context, title string, err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, tank.ErrInvalidTank):
+		status = http.StatusBadRequest
+	case errors.Is(err, tank.ErrTankAlreadyExist), errors.Is(err, tank.ErrTankDeclared):
+		status = http.StatusConflict
+	case errors.Is(err, tank.ErrTankNotFound):
+		status = http.StatusNotFound
+	default:
+		log.Errorf("%s: %s", title, err.Error())
+	}
+
+	c.Response().WriteHeader(status)
+	return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+		{
+			Status: fmt.Sprintf("%d", status),
+			Title:  title,
+			Detail: err.Error(),
+		},
+	})
+}
@@ -22,6 +22,7 @@ type Board interface {
 	StartFilterBubble(ctx context.Context) error
 	StopFilterBubble(ctx context.Context) error
 	StopRelais(ctx context.Context) error
+	BacteriumIntroduced(ctx context.Context) error
 	State() models.TFPState
 	IO() models.TFPIO
 	Config() models.TFPConfig
@@ -0,0 +1,121 @@
+package tfpboard
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/disaster37/gobot-fat/helper"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultBacteriumDuration is the number of hours UVC are stopped after introduce bacterium, when not set on config
+const DefaultBacteriumDuration = 48
+
+var ErrBacteriumPeriod = errors.New("UVC can't start during bacterium period")
+
+// BacteriumIntroduced stop UVC during the bacterium period
+// UVC are restored to their previous state when the period is finished
+func (h *TFPBoard) BacteriumIntroduced(ctx context.Context) error {
+	log.Debug("Bacterium introduced")
+
+	h.state.BacteriumTime = time.Now()
+	if err := h.stateUsecase.Update(ctx, h.state); err != nil {
+		return err
+	}
+
+	if err := h.holdOffUVC(); err != nil {
+		return err
+	}
+
+	// Send event
+	helper.SendEvent(ctx, h.eventUsecase, h.name, helper.KindEventSetBacterium, h.name)
+
+	// Publish internal event
+	h.Publish(EventSetBacterium, nil)
+
+	log.Infof("UVC are stopped until %s", h.state.BacteriumTime.Add(h.bacteriumDuration()).Format(time.RFC3339))
+
+	return nil
+}
+
+// bacteriumDuration return the duration of bacterium period
+func (h *TFPBoard) bacteriumDuration() time.Duration {
+	if h.config.BacteriumDuration > 0 {
+		return time.Duration(h.config.BacteriumDuration) * time.Hour
+	}
+
+	return DefaultBacteriumDuration * time.Hour
+}
+
+// bacteriumRemainingTime return the remaining time before the end of bacterium period
+func (h *TFPBoard) bacteriumRemainingTime() time.Duration {
+	if h.state.BacteriumTime.IsZero() {
+		return 0
+	}
+
+	remaining := time.Until(h.state.BacteriumTime.Add(h.bacteriumDuration()))
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+// isBacteriumPeriod return true if UVC must be stopped because of bacterium
+func (h *TFPBoard) isBacteriumPeriod() bool {
+	return h.bacteriumRemainingTime() > 0
+}
+
+// holdOffUVC stop UVC relays without change the state, to restore them after bacterium period
+func (h *TFPBoard) holdOffUVC() error {
+	if err := h.relayUVC1.Off(); err != nil {
+		return err
+	}
+	if err := h.relayUVC2.Off(); err != nil {
+		return err
+	}
+	h.isBacteriumHoldOff = true
+
+	return nil
+}
+
+// handleBacteriumPeriod stop UVC during bacterium period and restore them when it's finished
+// It's also called when board start, so the period survive restart
+func (h *TFPBoard) handleBacteriumPeriod() {
+	ctx := helper.WithOrigin(context.Background(), helper.OriginAuto)
+
+	if h.isBacteriumPeriod() {
+		if !h.isBacteriumHoldOff {
+			if err := h.holdOffUVC(); err != nil {
+				log.Errorf("Error when stop UVC during bacterium period: %s", err.Error())
+			}
+		}
+		return
+	}
+
+	if !h.isBacteriumHoldOff {
+		return
+	}
+	h.isBacteriumHoldOff = false
+	log.Info("Bacterium period finished, restore UVC")
+
+	if h.canStartRelay() && h.state.PondPumpRunning {
+		if h.state.UVC1Running {
+			if err := h.StartUVC1(ctx); err != nil {
+				log.Errorf("When start UVC1: %s", err.Error())
+			}
+		}
+		if h.state.UVC2Running {
+			if err := h.StartUVC2(ctx); err != nil {
+				log.Errorf("When start UVC2: %s", err.Error())
+			}
+		}
+	}
+
+	// Send event
+	helper.SendEvent(ctx, h.eventUsecase, h.name, helper.KindEventUnsetBacterium, h.name)
+
+	// Publish internal event
+	h.Publish(EventUnsetBacterium, nil)
+}
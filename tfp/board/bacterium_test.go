@@ -0,0 +1,81 @@
+package tfpboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/gobot-fat/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *TFPBoardTestSuite) TestBacteriumPeriod() {
+
+	waitDuration := 100 * time.Millisecond
+
+	err := s.board.StartPondPumpWithUVC(context.Background())
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	// Bacterium introduced stop UVC but keep state to restore them
+	status := mock.WaitEvent(s.board, EventSetBacterium, waitDuration)
+	err = s.board.BacteriumIntroduced(context.Background())
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), <-status)
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayPompPond.Pin()))
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC2.Pin()))
+	assert.True(s.T(), s.board.state.UVC1Running)
+	assert.True(s.T(), s.board.state.UVC2Running)
+	assert.False(s.T(), s.board.state.BacteriumTime.IsZero())
+
+	// Remaining time is visible on state
+	state := s.board.State()
+	assert.InDelta(s.T(), int64(DefaultBacteriumDuration*3600), state.BacteriumRemainingTime, 5)
+
+	// Can't start UVC during period
+	err = s.board.StartUVC1(context.Background())
+	assert.ErrorIs(s.T(), err, ErrBacteriumPeriod)
+	err = s.board.StartUVC2(context.Background())
+	assert.ErrorIs(s.T(), err, ErrBacteriumPeriod)
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC2.Pin()))
+
+	// Start pond pump with UVC only start pond pump
+	err = s.board.StartPondPumpWithUVC(context.Background())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+
+	// Nothink change while period is not finished
+	s.board.handleBacteriumPeriod()
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC2.Pin()))
+
+	// Restore UVC when period is finished
+	s.board.config.BacteriumDuration = 1
+	s.board.state.BacteriumTime = time.Now().Add(-2 * time.Hour)
+	status = mock.WaitEvent(s.board, EventUnsetBacterium, waitDuration)
+	s.board.handleBacteriumPeriod()
+	assert.True(s.T(), <-status)
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayUVC2.Pin()))
+	assert.Equal(s.T(), int64(0), s.board.State().BacteriumRemainingTime)
+
+	// Period survive restart
+	board, adaptor := initTestBoard()
+	board.state.PondPumpRunning = true
+	board.state.UVC1Running = true
+	board.state.UVC2Running = true
+	board.state.BacteriumTime = time.Now().Add(-1 * time.Hour)
+	err = board.Start(context.Background())
+	assert.NoError(s.T(), err)
+	for !board.isInitialized {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(s.T(), 0, adaptor.GetDigitalPinState(board.relayPompPond.Pin()))
+	assert.Equal(s.T(), 1, adaptor.GetDigitalPinState(board.relayUVC1.Pin()))
+	assert.Equal(s.T(), 1, adaptor.GetDigitalPinState(board.relayUVC2.Pin()))
+	assert.True(s.T(), board.isBacteriumHoldOff)
+	err = board.Stop(context.Background())
+	assert.NoError(s.T(), err)
+}
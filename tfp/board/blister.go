@@ -0,0 +1,174 @@
+package tfpboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	blisterUVC1  = "uvc1"
+	blisterUVC2  = "uvc2"
+	blisterOzone = "ozone"
+)
+
+var ErrBlisterExpired = errors.New("UVC can't start because of blister is expired")
+
+// blister is the usage of one blister
+type blister struct {
+	name    string
+	nbHour  int64
+	maxTime int64
+	alert   *int64
+}
+
+// percent return the blister usage in percent
+func (b *blister) percent() int64 {
+	if b.maxTime <= 0 {
+		return 0
+	}
+	return b.nbHour * 100 / b.maxTime
+}
+
+// blisters return the usage of all blisters
+func (h *TFPBoard) blisters() []*blister {
+	return []*blister{
+		{name: blisterUVC1, nbHour: h.state.UVC1BlisterNbHour, maxTime: h.config.UVC1BlisterMaxTime, alert: &h.state.UVC1BlisterAlert},
+		{name: blisterUVC2, nbHour: h.state.UVC2BlisterNbHour, maxTime: h.config.UVC2BlisterMaxTime, alert: &h.state.UVC2BlisterAlert},
+		{name: blisterOzone, nbHour: h.state.OzoneBlisterNbHour, maxTime: h.config.OzoneBlisterMaxTime, alert: &h.state.OzoneBlisterAlert},
+	}
+}
+
+// isBlisterExpired return true if the blister used by UVC relay is expired and auto cutoff is enabled
+// On ozone mode, the UVC2 relay power the ozone blister
+func (h *TFPBoard) isBlisterExpired(uvc string) bool {
+	if !h.config.IsBlisterAutoCutoff {
+		return false
+	}
+
+	name := uvc
+	if uvc == blisterUVC2 && h.config.Mode == "ozone" {
+		name = blisterOzone
+	}
+
+	for _, b := range h.blisters() {
+		if b.name == name {
+			return b.maxTime > 0 && b.nbHour >= b.maxTime
+		}
+	}
+
+	return false
+}
+
+// cutoffExpiredUVC mark UVC with expired blister as stopped
+// It return true if state is updated
+func (h *TFPBoard) cutoffExpiredUVC() (isUpdated bool) {
+	if h.state.UVC1Running && h.isBlisterExpired(blisterUVC1) {
+		log.Info("UVC1 stay stopped because of blister is expired")
+		h.state.UVC1Running = false
+		isUpdated = true
+	}
+	if h.state.UVC2Running && h.isBlisterExpired(blisterUVC2) {
+		log.Info("UVC2 stay stopped because of blister is expired")
+		h.state.UVC2Running = false
+		isUpdated = true
+	}
+
+	return isUpdated
+}
+
+// handleBlisterAlerts send alert when blister usage reach a new threshold and stop expired UVC if needed
+// It return true if state is updated
+func (h *TFPBoard) handleBlisterAlerts(ctx context.Context) (isUpdated bool) {
+
+	thresholds := h.config.BlisterThresholds()
+
+	for _, b := range h.blisters() {
+		if b.maxTime <= 0 {
+			continue
+		}
+
+		percent := b.percent()
+		var reached int64
+		for _, threshold := range thresholds {
+			if percent >= threshold {
+				reached = threshold
+			}
+		}
+
+		if reached > *b.alert {
+			*b.alert = reached
+			isUpdated = true
+
+			log.Warnf("Blister %s reach %d%% of max usage", b.name, reached)
+
+			// Send event
+			helper.SendEventWithMessage(ctx, h.eventUsecase, h.name, helper.KindEventBlisterAlert, b.name,
+				fmt.Sprintf("The %s blister reach %d%% of max usage. It is used since %d hours on %d hours. You need to replace it before %s", b.name, reached, b.nbHour, b.maxTime, blisterReplacementDate(b).Format(time.RFC1123)),
+				reached,
+			)
+
+			// Publish internal event
+			h.Publish(EventBlisterAlert, b.name)
+		}
+	}
+
+	// Stop expired UVC
+	if h.isBlisterExpired(blisterUVC1) && h.relayUVC1.State() {
+		log.Info("Stop UVC1 because of blister is expired")
+		if err := h.StopUVC1(ctx); err != nil {
+			log.Errorf("Error when stop UVC1: %s", err.Error())
+		}
+	}
+	if h.isBlisterExpired(blisterUVC2) && h.relayUVC2.State() {
+		log.Info("Stop UVC2 because of blister is expired")
+		if err := h.StopUVC2(ctx); err != nil {
+			log.Errorf("Error when stop UVC2: %s", err.Error())
+		}
+	}
+
+	return isUpdated
+}
+
+// computeBlisters set the remaining hours and the estimated replacement date of each blister on state
+// The estimation consider the blister running all the time
+func (h *TFPBoard) computeBlisters(state *models.TFPState) {
+	for _, b := range h.blisters() {
+		if b.maxTime <= 0 {
+			continue
+		}
+
+		remaining := b.maxTime - b.nbHour
+		if remaining < 0 {
+			remaining = 0
+		}
+		replacementDate := blisterReplacementDate(b)
+
+		switch b.name {
+		case blisterUVC1:
+			state.UVC1BlisterRemainingHours = remaining
+			state.UVC1BlisterReplacementDate = replacementDate
+		case blisterUVC2:
+			state.UVC2BlisterRemainingHours = remaining
+			state.UVC2BlisterReplacementDate = replacementDate
+		case blisterOzone:
+			state.OzoneBlisterRemainingHours = remaining
+			state.OzoneBlisterReplacementDate = replacementDate
+		}
+	}
+}
+
+// blisterReplacementDate return the estimated date when blister must be replaced
+func blisterReplacementDate(b *blister) time.Time {
+	remaining := b.maxTime - b.nbHour
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return time.Now().Add(time.Duration(remaining) * time.Hour).Truncate(time.Hour)
+}
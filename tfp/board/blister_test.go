@@ -0,0 +1,116 @@
+package tfpboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *TFPBoardTestSuite) TestHandleBlisterAlerts() {
+	waitDuration := 100 * time.Millisecond
+	eventUsecase := &mock.MockEventUsecase{}
+	defaultEventUsecase := s.board.eventUsecase
+	s.board.eventUsecase = eventUsecase
+	defer func() { s.board.eventUsecase = defaultEventUsecase }()
+
+	s.board.config.Mode = "uvc"
+	s.board.config.UVC1BlisterMaxTime = 100
+	s.board.config.UVC2BlisterMaxTime = 100
+	s.board.config.OzoneBlisterMaxTime = 100
+	s.board.config.BlisterAlertThresholds = "100, 90,bad"
+
+	// No alert under threshold
+	s.board.state.UVC1BlisterNbHour = 89
+	assert.False(s.T(), s.board.handleBlisterAlerts(context.Background()))
+	assert.Equal(s.T(), 0, eventUsecase.Kinds(helper.KindEventBlisterAlert))
+
+	// Alert when reach 90%
+	s.board.state.UVC1BlisterNbHour = 90
+	status := mock.WaitEvent(s.board, EventBlisterAlert, waitDuration)
+	assert.True(s.T(), s.board.handleBlisterAlerts(context.Background()))
+	assert.True(s.T(), <-status)
+	assert.Equal(s.T(), int64(90), s.board.state.UVC1BlisterAlert)
+	assert.Equal(s.T(), 1, eventUsecase.Kinds(helper.KindEventBlisterAlert))
+
+	// Alert only one time per threshold
+	s.board.state.UVC1BlisterNbHour = 95
+	assert.False(s.T(), s.board.handleBlisterAlerts(context.Background()))
+	assert.Equal(s.T(), 1, eventUsecase.Kinds(helper.KindEventBlisterAlert))
+
+	// Alert when reach 100%, but not stop UVC if auto cutoff is disabled
+	err := s.board.StartPondPumpWithUVC(context.Background())
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.board.state.UVC1BlisterNbHour = 100
+	assert.True(s.T(), s.board.handleBlisterAlerts(context.Background()))
+	assert.Equal(s.T(), int64(100), s.board.state.UVC1BlisterAlert)
+	assert.Equal(s.T(), 2, eventUsecase.Kinds(helper.KindEventBlisterAlert))
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+
+	// Stop expired UVC when auto cutoff
+	s.board.config.IsBlisterAutoCutoff = true
+	s.board.handleBlisterAlerts(context.Background())
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayUVC2.Pin()))
+	assert.False(s.T(), s.board.state.UVC1Running)
+
+	// Can't start expired UVC
+	err = s.board.StartUVC1(context.Background())
+	assert.ErrorIs(s.T(), err, ErrBlisterExpired)
+
+	// Expired UVC1 not prevent to start UVC2 with pond pump
+	assert.NoError(s.T(), s.board.StartPondPumpWithUVC(context.Background()))
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayUVC2.Pin()))
+	assert.False(s.T(), s.board.state.UVC1Running)
+	assert.True(s.T(), s.board.state.UVC2Running)
+
+	// Expired UVC is not restored on start
+	s.board.state.UVC1Running = true
+	assert.True(s.T(), s.board.cutoffExpiredUVC())
+	assert.False(s.T(), s.board.state.UVC1Running)
+	assert.True(s.T(), s.board.state.UVC2Running)
+	assert.False(s.T(), s.board.cutoffExpiredUVC())
+
+	// On ozone mode, UVC2 relay use ozone blister
+	s.board.config.Mode = "ozone"
+	s.board.state.OzoneBlisterNbHour = 100
+	assert.True(s.T(), s.board.isBlisterExpired(blisterUVC2))
+	s.board.config.Mode = "uvc"
+	assert.False(s.T(), s.board.isBlisterExpired(blisterUVC2))
+
+	// Remaining hours and replacement date on state
+	state := s.board.State()
+	assert.Equal(s.T(), int64(0), state.UVC1BlisterRemainingHours)
+	assert.Equal(s.T(), int64(100), state.UVC2BlisterRemainingHours)
+	assert.WithinDuration(s.T(), time.Now().Add(100*time.Hour), state.UVC2BlisterReplacementDate, 1*time.Hour)
+}
+
+func (s *TFPBoardTestSuite) TestHandleBlisterTimeAlerts() {
+	eventUsecase := &mock.MockEventUsecase{}
+	defaultEventUsecase := s.board.eventUsecase
+	s.board.eventUsecase = eventUsecase
+	defer func() { s.board.eventUsecase = defaultEventUsecase }()
+
+	s.board.config.UVC1BlisterMaxTime = 100
+	s.board.config.BlisterAlertThresholds = "90"
+	s.board.state.UVC1BlisterNbHour = 90
+
+	// Alert is sent even if blister time is not counted
+	s.board.config.Mode = "none"
+	s.board.handleBlisterTime()
+	assert.Equal(s.T(), 1, eventUsecase.Kinds(helper.KindEventBlisterAlert))
+	assert.Equal(s.T(), int64(90), s.board.state.UVC1BlisterAlert)
+	assert.Equal(s.T(), int64(90), s.board.state.UVC1BlisterNbHour)
+
+	// Alert is sent on security
+	s.board.state.UVC1BlisterAlert = 0
+	s.board.state.IsSecurity = true
+	s.board.config.Mode = "uvc"
+	s.board.handleBlisterTime()
+	assert.Equal(s.T(), 2, eventUsecase.Kinds(helper.KindEventBlisterAlert))
+}
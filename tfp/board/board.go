@@ -6,9 +6,11 @@ import (
 
 	"github.com/disaster37/gobot-arest/v2/drivers/extra"
 	"github.com/disaster37/gobot-arest/v2/plateforms/arest"
+	"github.com/disaster37/gobot-fat/board"
 	"github.com/disaster37/gobot-fat/helper"
 	"github.com/disaster37/gobot-fat/mock"
 	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/tank"
 	"github.com/disaster37/gobot-fat/tfp"
 	"github.com/disaster37/gobot-fat/usecase"
 	log "github.com/sirupsen/logrus"
@@ -29,6 +31,11 @@ const (
 	EventUnsetDisableSecurity = "unset-disable-security"
 	EventSetEmergencyStop     = "set-emergency-stop"
 	EventUnsetEmergencyStop   = "unset-emergency-stop"
+	EventSetBacterium         = "set-bacterium"
+	EventUnsetBacterium       = "unset-bacterium"
+	EventBlisterAlert         = "blister-alert"
+	EventSetDryRun            = "set-dry-run"
+	EventUnsetDryRun          = "unset-dry-run"
 )
 
 // TFPAdaptor is TFP board interface
@@ -58,15 +65,21 @@ type TFPBoard struct {
 	valueRebooted      *extra.ValueDriver
 	functionRebooted   *extra.FunctionDriver
 	configHandler      *viper.Viper
+	pondTank           string
+	tankUsecase        tank.Usecase
 	isOnline           bool
 	isInitialized      bool
+	poll               *pollAdaptor
+	isBacteriumHoldOff bool
+	shutdownRelays     string
 	schedulingRoutines []*time.Ticker
 	globalEventer      gobot.Eventer
 	gobot.Eventer
 }
 
 // NewTFP create board to manage TFP
-func NewTFP(configHandler *viper.Viper, config *models.TFPConfig, state *models.TFPState, eventUsecase usecase.UsecaseCRUD, tfpStateUsecase usecase.UsecaseCRUD, eventer gobot.Eventer) (tfpBoard tfp.Board) {
+// The tank usecase permit to read the dry run state of pond tank on start
+func NewTFP(configHandler *viper.Viper, config *models.TFPConfig, state *models.TFPState, eventUsecase usecase.UsecaseCRUD, tfpStateUsecase usecase.UsecaseCRUD, tankUsecase tank.Usecase, eventer gobot.Eventer) (tfpBoard tfp.Board) {
 
 	//Create client
 	var c TFPAdaptor
@@ -78,11 +91,14 @@ func NewTFP(configHandler *viper.Viper, config *models.TFPConfig, state *models.
 		c = arest.NewHTTPAdaptor(configHandler.GetString("url"))
 	}
 
-	return newTFP(c, configHandler, config, state, eventUsecase, tfpStateUsecase, eventer, 1*time.Second)
+	return newTFP(c, configHandler, config, state, eventUsecase, tfpStateUsecase, tankUsecase, eventer, 1*time.Second)
 
 }
 
-func newTFP(board TFPAdaptor, configHandler *viper.Viper, config *models.TFPConfig, state *models.TFPState, eventUsecase usecase.UsecaseCRUD, tfpStateUsecase usecase.UsecaseCRUD, eventer gobot.Eventer, wait time.Duration) (tfpHandler tfp.Board) {
+func newTFP(board TFPAdaptor, configHandler *viper.Viper, config *models.TFPConfig, state *models.TFPState, eventUsecase usecase.UsecaseCRUD, tfpStateUsecase usecase.UsecaseCRUD, tankUsecase tank.Usecase, eventer gobot.Eventer, wait time.Duration) (tfpHandler tfp.Board) {
+
+	// Values are read through poll, to know when board answered for the last time
+	poll := newPollAdaptor(board, configHandler.GetString("name"))
 
 	// Create struct
 	tfpBoard := &TFPBoard{
@@ -91,23 +107,28 @@ func newTFP(board TFPAdaptor, configHandler *viper.Viper, config *models.TFPConf
 		stateUsecase:       tfpStateUsecase,
 		configHandler:      configHandler,
 		name:               configHandler.GetString("name"),
+		pondTank:           configHandler.GetString("pond_tank"),
+		tankUsecase:        tankUsecase,
 		config:             config,
 		state:              state,
 		isOnline:           false,
 		isInitialized:      false,
 		globalEventer:      eventer,
+		poll:               poll,
 		relayPompPond:      gpio.NewRelayDriver(board, configHandler.GetString("pin.relay.pond_pomp"), gpio.WithRelayInverted()),
 		relayPompWaterfall: gpio.NewRelayDriver(board, configHandler.GetString("pin.relay.waterfall_pomp")),
 		relayUVC1:          gpio.NewRelayDriver(board, configHandler.GetString("pin.relay.uvc1"), gpio.WithRelayInverted()),
 		relayUVC2:          gpio.NewRelayDriver(board, configHandler.GetString("pin.relay.uvc2"), gpio.WithRelayInverted()),
 		relayBubbleFilter:  gpio.NewRelayDriver(board, configHandler.GetString("pin.relay.filter_bubble"), gpio.WithRelayInverted()),
 		relayBubblePond:    gpio.NewRelayDriver(board, configHandler.GetString("pin.relay.pond_bubble"), gpio.WithRelayInverted()),
-		valueRebooted:      extra.NewValueDriver(board, "isRebooted", wait),
+		valueRebooted:      extra.NewValueDriver(poll, "isRebooted", wait),
 		functionRebooted:   extra.NewFunctionDriver(board, "acknoledgeRebooted", ""),
 		Eventer:            gobot.NewEventer(),
 		schedulingRoutines: make([]*time.Ticker, 0),
 	}
 
+	tfpBoard.setShutdownPolicy(configHandler)
+
 	tfpBoard.gobot = gobot.NewRobot(
 		tfpBoard.Name(),
 		[]gobot.Connection{tfpBoard.board},
@@ -137,6 +158,11 @@ func newTFP(board TFPAdaptor, configHandler *viper.Viper, config *models.TFPConf
 	tfpBoard.AddEvent(EventUnsetDisableSecurity)
 	tfpBoard.AddEvent(EventUnsetEmergencyStop)
 	tfpBoard.AddEvent(EventUnsetSecurity)
+	tfpBoard.AddEvent(EventSetBacterium)
+	tfpBoard.AddEvent(EventUnsetBacterium)
+	tfpBoard.AddEvent(EventBlisterAlert)
+	tfpBoard.AddEvent(EventSetDryRun)
+	tfpBoard.AddEvent(EventUnsetDryRun)
 
 	log.Infof("Board %s initialized successfully", tfpBoard.Name())
 
@@ -151,10 +177,19 @@ func (h *TFPBoard) Name() string {
 
 // Board get board info as object
 func (h *TFPBoard) Board() *models.Board {
-	return &models.Board{
-		Name:     h.name,
-		IsOnline: h.isOnline,
+	data := &models.Board{
+		Name:          h.name,
+		IsOnline:      h.isOnline,
+		IsInitialized: h.isInitialized,
 	}
+	h.poll.Fill(data)
+
+	return data
+}
+
+// Diagnostics return the board diagnostics
+func (h *TFPBoard) Diagnostics() *board.Diagnostics {
+	return h.poll.Diagnostics
 }
 
 // IsOnline permit to know is board is online
@@ -171,8 +206,11 @@ func (h *TFPBoard) Start(ctx context.Context) (err error) {
 		return err
 	}
 
+	// Pumps and UVC stay stopped while pond tank is too low
+	h.state.IsDryRun = h.isPondTankDryRun(ctx)
+
 	// Relay relayPompPond is Normaly Close
-	if h.state.PondPumpRunning {
+	if h.state.PondPumpRunning && !h.state.IsDryRun {
 		err = h.relayPompPond.On()
 	} else {
 		err = h.relayPompPond.Off()
@@ -181,8 +219,16 @@ func (h *TFPBoard) Start(ctx context.Context) (err error) {
 		return err
 	}
 
+	// UVC with expired blister are not restored
+	if h.cutoffExpiredUVC() {
+		if err = h.stateUsecase.Update(ctx, h.state); err != nil {
+			return err
+		}
+	}
+
 	// Relay relayUVC1 is Normaly Close
-	if h.state.UVC1Running {
+	// UVC stay stopped during bacterium period
+	if h.state.UVC1Running && !h.state.IsDryRun && !h.isBacteriumPeriod() {
 		err = h.relayUVC1.On()
 	} else {
 		err = h.relayUVC1.Off()
@@ -192,7 +238,7 @@ func (h *TFPBoard) Start(ctx context.Context) (err error) {
 	}
 
 	// Relay relayUVC2 is Normaly Close
-	if h.state.UVC2Running {
+	if h.state.UVC2Running && !h.state.IsDryRun && !h.isBacteriumPeriod() {
 		err = h.relayUVC2.On()
 	} else {
 		err = h.relayUVC2.Off()
@@ -222,7 +268,7 @@ func (h *TFPBoard) Start(ctx context.Context) (err error) {
 	}
 
 	// Relay relayPompWaterfall is Normaly Open
-	if h.state.WaterfallPumpRunning {
+	if h.state.WaterfallPumpRunning && !h.state.IsDryRun {
 		err = h.relayPompWaterfall.On()
 	} else {
 		err = h.relayPompWaterfall.Off()
@@ -244,6 +290,7 @@ func (h *TFPBoard) Start(ctx context.Context) (err error) {
 }
 
 // Stop stop the functions handle by board
+// Relays are left as they are, or forced off according to shutdown policy
 func (h *TFPBoard) Stop(ctx context.Context) (err error) {
 
 	// Internal event
@@ -255,6 +302,9 @@ func (h *TFPBoard) Stop(ctx context.Context) (err error) {
 	}
 	h.schedulingRoutines = make([]*time.Ticker, 0)
 
+	// Stop outputs
+	h.stopRelaysOnShutdown()
+
 	err = h.gobot.Stop()
 	if err != nil {
 		return err
@@ -262,6 +312,12 @@ func (h *TFPBoard) Stop(ctx context.Context) (err error) {
 
 	h.isOnline = false
 	h.isInitialized = false
+	h.isBacteriumHoldOff = false
+
+	// Persist final state
+	if err = h.stateUsecase.Update(ctx, h.state); err != nil {
+		log.Errorf("Error when save state of board %s: %s", h.name, err.Error())
+	}
 
 	// Send event
 	helper.SendEvent(ctx, h.eventUsecase, h.name, helper.KindEventStopBoard, h.name)
@@ -272,7 +328,11 @@ func (h *TFPBoard) Stop(ctx context.Context) (err error) {
 
 // State return the current state
 func (h *TFPBoard) State() models.TFPState {
-	return *h.state
+	state := *h.state
+	state.BacteriumRemainingTime = int64(h.bacteriumRemainingTime().Seconds())
+	h.computeBlisters(&state)
+
+	return state
 }
 
 // State return the current state
@@ -47,6 +47,7 @@ func (s *TFPBoardTestSuite) SetupTest() {
 
 	// State
 	s.board.state = &models.TFPState{}
+	s.board.isBacteriumHoldOff = false
 
 	// Config
 	s.board.config = &models.TFPConfig{
@@ -105,6 +106,8 @@ func (s *TFPBoardTestSuite) TestStartStopIsOnline() {
 func (s *TFPBoardTestSuite) TestGetBoard() {
 	assert.Equal(s.T(), "test", s.board.Board().Name)
 	assert.True(s.T(), s.board.Board().IsOnline)
+	assert.True(s.T(), s.board.Board().IsInitialized)
+	assert.Eventually(s.T(), func() bool { return s.board.Board().LastPoll != nil }, 5*time.Second, 100*time.Millisecond)
 }
 
 func (s *TFPBoardTestSuite) TestName() {
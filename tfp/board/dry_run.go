@@ -0,0 +1,96 @@
+package tfpboard
+
+import (
+	"context"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// isPondTank return true if the dry run event come from the tank where the pond pump take water
+func (h *TFPBoard) isPondTank(data interface{}) bool {
+	tank, ok := data.(*models.Tank)
+	if !ok || tank == nil || h.pondTank == "" {
+		return false
+	}
+
+	return tank.ID == h.pondTank
+}
+
+// isPondTankDryRun return the current dry run state of pond tank
+// The tank publish its dry run state on each reading, so this state is only needed until the next reading
+func (h *TFPBoard) isPondTankDryRun(ctx context.Context) bool {
+	if h.pondTank == "" || h.tankUsecase == nil {
+		return false
+	}
+
+	tank, err := h.tankUsecase.Tank(ctx, h.pondTank)
+	if err != nil {
+		log.Errorf("Error when read pond tank %s: %s", h.pondTank, err.Error())
+		return false
+	}
+	if tank == nil {
+		log.Warnf("Pond tank %s not found, dry run is not checked on start", h.pondTank)
+		return false
+	}
+
+	if tank.IsDryRun {
+		log.Warnf("Pond tank %s is too low, pumps stay stopped on board %s", h.pondTank, h.name)
+	}
+
+	return tank.IsDryRun
+}
+
+// setDryRun stop the pumps and UVC like security, until the pond tank level is restored
+// Pumps state is kept to restart them when unset dry run
+func (h *TFPBoard) setDryRun(ctx context.Context) {
+	if h.state.IsDryRun {
+		return
+	}
+	h.state.IsDryRun = true
+	log.Warnf("Pond tank %s is too low, stop pumps on board %s", h.pondTank, h.name)
+
+	// Stop UVC1
+	if err := h.relayUVC1.Off(); err != nil {
+		log.Errorf("Error when stop UVC1: %s", err.Error())
+	}
+
+	// Stop UVC2
+	if err := h.relayUVC2.Off(); err != nil {
+		log.Errorf("Error when stop UVC2: %s", err.Error())
+	}
+
+	// Stop pond pump
+	if err := h.relayPompPond.Off(); err != nil {
+		log.Errorf("Error when stop pond pump: %s", err.Error())
+	}
+
+	// Stop waterfall pump
+	if err := h.relayPompWaterfall.Off(); err != nil {
+		log.Errorf("Error when stop waterfall pump: %s", err.Error())
+	}
+
+	// Send event
+	helper.SendEvent(ctx, h.eventUsecase, h.name, helper.KindEventSetDryRun, h.name)
+
+	// Publish internal event
+	h.Publish(EventSetDryRun, nil)
+}
+
+// unsetDryRun restart the pumps and UVC as expected by state
+func (h *TFPBoard) unsetDryRun(ctx context.Context) {
+	if !h.state.IsDryRun {
+		return
+	}
+	h.state.IsDryRun = false
+	log.Infof("Pond tank %s level is restored, restart pumps on board %s", h.pondTank, h.name)
+
+	h.handleUnsetSecurityOrEmergencyStop()
+
+	// Send event
+	helper.SendEvent(ctx, h.eventUsecase, h.name, helper.KindEventUnsetDryRun, h.name)
+
+	// Publish internal event
+	h.Publish(EventUnsetDryRun, nil)
+}
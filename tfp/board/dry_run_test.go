@@ -0,0 +1,88 @@
+package tfpboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/disaster37/gobot-fat/helper"
+	"github.com/disaster37/gobot-fat/mock"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *TFPBoardTestSuite) TestDryRun() {
+	waitDuration := 100 * time.Millisecond
+	s.board.state.PondPumpRunning = true
+	s.board.state.WaterfallPumpRunning = true
+	s.board.state.UVC1Running = true
+	s.board.state.UVC2Running = true
+	s.adaptor.SetDigitalPinState(s.board.relayPompPond.Pin(), 0)
+	s.adaptor.SetDigitalPinState(s.board.relayPompWaterfall.Pin(), 1)
+	s.adaptor.SetDigitalPinState(s.board.relayUVC1.Pin(), 0)
+	s.adaptor.SetDigitalPinState(s.board.relayUVC2.Pin(), 0)
+
+	// Dry run from other tank is ignored
+	status := mock.WaitEvent(s.board, EventSetDryRun, waitDuration)
+	s.board.globalEventer.Publish(helper.SetDryRun, &models.Tank{ID: "tank_garden"})
+	assert.False(s.T(), <-status)
+	assert.False(s.T(), s.board.state.IsDryRun)
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayPompPond.Pin()))
+
+	// Dry run from pond tank stop pumps and UVC
+	status = mock.WaitEvent(s.board, EventSetDryRun, waitDuration)
+	s.board.globalEventer.Publish(helper.SetDryRun, &models.Tank{ID: "tank_pond", IsDryRun: true})
+	assert.True(s.T(), <-status)
+	assert.True(s.T(), s.board.state.IsDryRun)
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayPompPond.Pin()))
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayPompWaterfall.Pin()))
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayUVC2.Pin()))
+	assert.True(s.T(), s.board.state.PondPumpRunning)
+	assert.True(s.T(), s.board.state.WaterfallPumpRunning)
+
+	// Pumps can't be started during dry run
+	assert.ErrorIs(s.T(), s.board.StartPondPump(context.Background()), ErrRelayCanNotStart)
+	assert.ErrorIs(s.T(), s.board.StartWaterfallPump(context.Background()), ErrRelayCanNotStart)
+
+	// Level restored, pumps and UVC resume
+	status = mock.WaitEvent(s.board, EventUnsetDryRun, waitDuration)
+	s.board.globalEventer.Publish(helper.UnsetDryRun, &models.Tank{ID: "tank_pond"})
+	assert.True(s.T(), <-status)
+	assert.False(s.T(), s.board.state.IsDryRun)
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayPompPond.Pin()))
+	assert.Equal(s.T(), 1, s.adaptor.GetDigitalPinState(s.board.relayPompWaterfall.Pin()))
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayUVC1.Pin()))
+	assert.Equal(s.T(), 0, s.adaptor.GetDigitalPinState(s.board.relayUVC2.Pin()))
+}
+
+type fakeTankUsecase struct {
+	tank.Usecase
+	tanks map[string]*models.Tank
+}
+
+func (h *fakeTankUsecase) Tank(ctx context.Context, name string) (*models.Tank, error) {
+	return h.tanks[name], nil
+}
+
+func (s *TFPBoardTestSuite) TestDryRunOnStart() {
+	board, adaptor := initTestBoard()
+	board.state.PondPumpRunning = true
+	board.state.WaterfallPumpRunning = true
+	board.state.UVC1Running = true
+	board.tankUsecase = &fakeTankUsecase{tanks: map[string]*models.Tank{
+		"tank_pond": {ID: "tank_pond", IsDryRun: true},
+	}}
+
+	// Pond tank is too low, pumps and UVC stay stopped
+	assert.NoError(s.T(), board.Start(context.Background()))
+	assert.True(s.T(), board.state.IsDryRun)
+	assert.Equal(s.T(), 1, adaptor.GetDigitalPinState(board.relayPompPond.Pin()))
+	assert.Equal(s.T(), 0, adaptor.GetDigitalPinState(board.relayPompWaterfall.Pin()))
+	assert.Equal(s.T(), 1, adaptor.GetDigitalPinState(board.relayUVC1.Pin()))
+	assert.NoError(s.T(), board.Stop(context.Background()))
+
+	// Pond tank not found
+	board.tankUsecase = &fakeTankUsecase{}
+	assert.False(s.T(), board.isPondTankDryRun(context.Background()))
+}
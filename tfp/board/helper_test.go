@@ -19,6 +19,7 @@ func initTestBoard() (*TFPBoard, *mock.MockPlateform) {
 	configHandler.Set("pin.relay.pond_bubble", 4)
 	configHandler.Set("pin.relay.filter_bubble", 5)
 	configHandler.Set("pin.relay.waterfall_pomp", 6)
+	configHandler.Set("pond_tank", "tank_pond")
 	configTFP := &models.TFPConfig{
 		IsWaterfallAuto: false,
 	}
@@ -31,7 +32,7 @@ func initTestBoard() (*TFPBoard, *mock.MockPlateform) {
 	// Return the right type for drivers
 	mockBoard.SetValueReadState("isRebooted", false)
 
-	board := newTFP(mockBoard, configHandler, configTFP, stateTFP, eventUsecaseMock, usecaseTFPMock, eventer, 1*time.Millisecond)
+	board := newTFP(mockBoard, configHandler, configTFP, stateTFP, eventUsecaseMock, usecaseTFPMock, nil, eventer, 1*time.Millisecond)
 
 	return board.(*TFPBoard), mockBoard
 }
@@ -0,0 +1,31 @@
+package tfpboard
+
+import (
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+)
+
+// pollAdaptor record each read of values on board on diagnostics
+type pollAdaptor struct {
+	TFPAdaptor
+	*board.Diagnostics
+}
+
+func newPollAdaptor(adaptor TFPAdaptor, name string) *pollAdaptor {
+	return &pollAdaptor{
+		TFPAdaptor:  adaptor,
+		Diagnostics: board.NewDiagnostics(name),
+	}
+}
+
+// ValueRead read value on board and record its latency and when it succeed
+func (h *pollAdaptor) ValueRead(name string) (val interface{}, err error) {
+	start := time.Now()
+	val, err = h.TFPAdaptor.ValueRead(name)
+	h.Requested(time.Since(start))
+	if err == nil {
+		h.Polled()
+	}
+	return val, err
+}
@@ -21,6 +21,9 @@ func (h *TFPBoard) work() {
 	// Handle config
 	h.on(h.globalEventer, tfpconfig.NewTFPConfig, func(s interface{}) {
 		tfpConfig := s.(*models.TFPConfig)
+		if tfpConfig.ID != h.config.ID {
+			return
+		}
 		log.Debugf("New config received for board %s, we update it", h.name)
 
 		h.config = tfpConfig
@@ -33,10 +36,16 @@ func (h *TFPBoard) work() {
 	h.on(h.globalEventer, tfpstate.NewTFPState, func(s interface{}) {
 
 		tfpState := s.(*models.TFPState)
+		if tfpState.ID != h.state.ID {
+			return
+		}
 		log.Debugf("New state received for board %s, we update it", h.name)
 		h.state.UVC1BlisterNbHour = tfpState.UVC1BlisterNbHour
 		h.state.UVC2BlisterNbHour = tfpState.UVC2BlisterNbHour
 		h.state.OzoneBlisterNbHour = tfpState.OzoneBlisterNbHour
+		h.state.UVC1BlisterAlert = tfpState.UVC1BlisterAlert
+		h.state.UVC2BlisterAlert = tfpState.UVC2BlisterAlert
+		h.state.OzoneBlisterAlert = tfpState.OzoneBlisterAlert
 
 		// Publish internal event
 		h.Publish(EventNewState, h.state)
@@ -51,6 +60,7 @@ func (h *TFPBoard) work() {
 		if isRebooted {
 			// Board rebooted
 			log.Infof("Detect board %s is rebooted", h.name)
+			h.poll.Rebooted()
 
 			// Force reconnect to init pin and set output as expected
 			if err := h.board.Reconnect(); err != nil {
@@ -72,9 +82,16 @@ func (h *TFPBoard) work() {
 
 	// Handle board error / offline
 	h.on(h.valueRebooted, extra.Error, func(s interface{}) {
+		err := s.(error)
+
+		// Count only when board go offline, error is fired on each read while it's offline
+		if h.isOnline {
+			h.poll.Offline(err)
+		} else {
+			h.poll.Failed(err)
+		}
 		h.isOnline = false
 
-		err := s.(error)
 		log.Errorf("Board %s is offline: %s", h.name, err.Error())
 
 		// Send event
@@ -212,6 +229,28 @@ func (h *TFPBoard) work() {
 
 	})
 
+	// Handle set dry run from pond tank
+	h.on(h.globalEventer, helper.SetDryRun, func(s interface{}) {
+		if !h.isPondTank(s) {
+			return
+		}
+
+		h.setDryRun(helper.WithOrigin(ctx, helper.OriginAuto))
+	})
+
+	// Handle unset dry run from pond tank
+	h.on(h.globalEventer, helper.UnsetDryRun, func(s interface{}) {
+		if !h.isPondTank(s) {
+			return
+		}
+
+		h.unsetDryRun(helper.WithOrigin(ctx, helper.OriginAuto))
+	})
+
+	// Handle bacterium period
+	h.handleBacteriumPeriod()
+	h.schedulingRoutines = append(h.schedulingRoutines, gobot.Every(1*time.Minute, h.handleBacteriumPeriod))
+
 	// Handle blister time
 	h.schedulingRoutines = append(h.schedulingRoutines, gobot.Every(1*time.Hour, h.handleBlisterTime))
 
@@ -226,41 +265,43 @@ func (h *TFPBoard) work() {
 
 // handleBlisterTime permit to increment the number of hour of each blister enabled
 func (h *TFPBoard) handleBlisterTime() {
-	ctx := context.Background()
+	ctx := helper.WithOrigin(context.Background(), helper.OriginAuto)
 	isUpdated := false
 
 	// If we can start relay, All UVC are already stopped
-	if !h.canStartRelay() {
-		return
+	// Alerts are checked whatever the mode, so expired blister are always notified
+	if h.canStartRelay() && !h.isBacteriumPeriod() {
+		switch h.config.Mode {
+		case "ozone":
+			log.Debug("Ozone mode detected")
+			if h.state.UVC1Running {
+				h.state.UVC1BlisterNbHour++
+				isUpdated = true
+			}
+			if h.state.UVC2Running {
+				h.state.OzoneBlisterNbHour++
+				isUpdated = true
+			}
+		case "uvc":
+			log.Debug("UVC mode detected")
+			if h.state.UVC1Running {
+				h.state.UVC1BlisterNbHour++
+				isUpdated = true
+			}
+			if h.state.UVC2Running {
+				h.state.UVC2BlisterNbHour++
+				isUpdated = true
+			}
+		case "none":
+			log.Debug("None mode detected")
+		default:
+			log.Warn("Can't detect mode")
+		}
 	}
 
-	switch h.config.Mode {
-	case "ozone":
-		log.Debug("Ozone mode detected")
-		if h.state.UVC1Running {
-			h.state.UVC1BlisterNbHour++
-			isUpdated = true
-		}
-		if h.state.UVC2Running {
-			h.state.OzoneBlisterNbHour++
-			isUpdated = true
-		}
-	case "uvc":
-		log.Debug("UVC mode detected")
-		if h.state.UVC1Running {
-			h.state.UVC1BlisterNbHour++
-			isUpdated = true
-		}
-		if h.state.UVC2Running {
-			h.state.UVC2BlisterNbHour++
-			isUpdated = true
-		}
-	case "none":
-		log.Debug("None mode detected")
-		return
-	default:
-		log.Warn("Can't detect mode")
-		return
+	// Alert when blister reach threshold
+	if h.handleBlisterAlerts(ctx) {
+		isUpdated = true
 	}
 
 	if isUpdated {
@@ -273,7 +314,7 @@ func (h *TFPBoard) handleBlisterTime() {
 
 // handleWaterfall auto permit to start and stop waterfall automatically
 func (h *TFPBoard) handleWaterfallAuto() {
-	ctx := context.Background()
+	ctx := helper.WithOrigin(context.Background(), helper.OriginAuto)
 
 	if h.config.IsWaterfallAuto {
 		startDate, err := time.Parse("15:04", h.config.StartTimeWaterfall)
@@ -376,7 +417,7 @@ func (h *TFPBoard) on(driver gobot.Eventer, event string, f func(data interface{
 
 func (h *TFPBoard) handleUnsetSecurityOrEmergencyStop() {
 
-	ctx := context.Background()
+	ctx := helper.WithOrigin(context.Background(), helper.OriginAuto)
 
 	// We can start bubbles
 	if !h.state.IsEmergencyStopped {
@@ -408,14 +449,14 @@ func (h *TFPBoard) handleUnsetSecurityOrEmergencyStop() {
 		}
 
 		// UVC1
-		if h.state.UVC1Running {
+		if h.state.UVC1Running && !h.isBacteriumPeriod() && !h.isBlisterExpired(blisterUVC1) {
 			if err := h.StartUVC1(ctx); err != nil {
 				log.Errorf("When start UVC1: %s", err.Error())
 			}
 		}
 
 		// UVC2
-		if h.state.UVC2Running {
+		if h.state.UVC2Running && !h.isBacteriumPeriod() && !h.isBlisterExpired(blisterUVC2) {
 			if err := h.StartUVC2(ctx); err != nil {
 				log.Errorf("When start UVC2: %s", err.Error())
 			}
@@ -25,6 +25,16 @@ func (s *TFPBoardTestSuite) TestWork() {
 	s.board.globalEventer.Publish(tfpconfig.NewTFPConfig, newConfig)
 	assert.True(s.T(), <-status)
 
+	// Check config of other TFP is skipped
+	otherConfig := &models.TFPConfig{
+		UVC1BlisterMaxTime: 2000,
+	}
+	otherConfig.ID = s.board.config.ID + 1
+	status = mock.WaitEvent(s.board.Eventer, EventNewConfig, waitDuration)
+	s.board.globalEventer.Publish(tfpconfig.NewTFPConfig, otherConfig)
+	assert.False(s.T(), <-status)
+	assert.Equal(s.T(), int64(1000), s.board.config.UVC1BlisterMaxTime)
+
 	// Check update local state on event
 	newState := &models.TFPState{
 		OzoneBlisterNbHour: 100,
@@ -11,14 +11,14 @@ import (
 var ErrRelayCanNotStart = errors.New("relay can't start because of current state")
 
 func (h *TFPBoard) canStartRelay() bool {
-	if !h.state.IsEmergencyStopped && (!h.state.IsSecurity || h.state.IsDisableSecurity) {
+	if !h.state.IsEmergencyStopped && !h.state.IsDryRun && (!h.state.IsSecurity || h.state.IsDisableSecurity) {
 		return true
 	}
 	return false
 }
 
 // StartPondPump permit to run pond pump
-// The pump start only if no emergency and no security
+// The pump start only if no emergency, no security and enough water on pond tank
 func (h *TFPBoard) StartPondPump(ctx context.Context) error {
 	if h.canStartRelay() {
 		log.Debug("Start pond pump")
@@ -52,6 +52,16 @@ func (h *TFPBoard) StartPondPump(ctx context.Context) error {
 // The UVC start only if no emergency and no security
 func (h *TFPBoard) StartUVC1(ctx context.Context) error {
 
+	if h.isBacteriumPeriod() {
+		log.Info("UVC1 not started because of bacterium period")
+		return ErrBacteriumPeriod
+	}
+
+	if h.isBlisterExpired(blisterUVC1) {
+		log.Info("UVC1 not started because of blister is expired")
+		return ErrBlisterExpired
+	}
+
 	if h.canStartRelay() && h.state.PondPumpRunning {
 		log.Debug("Start UVC1")
 		err := h.relayUVC1.On()
@@ -83,6 +93,16 @@ func (h *TFPBoard) StartUVC1(ctx context.Context) error {
 // StartUVC2 permit to run UVC2
 // The UVC start only if no emergency and no security
 func (h *TFPBoard) StartUVC2(ctx context.Context) error {
+	if h.isBacteriumPeriod() {
+		log.Info("UVC2 not started because of bacterium period")
+		return ErrBacteriumPeriod
+	}
+
+	if h.isBlisterExpired(blisterUVC2) {
+		log.Info("UVC2 not started because of blister is expired")
+		return ErrBlisterExpired
+	}
+
 	if h.canStartRelay() && h.state.PondPumpRunning {
 		log.Debug("Start UVC2")
 		err := h.relayUVC2.On()
@@ -120,12 +140,22 @@ func (h *TFPBoard) StartPondPumpWithUVC(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
-		err = h.StartUVC1(ctx)
-		if err != nil {
+
+		// UVC can't start during bacterium period
+		if h.isBacteriumPeriod() {
+			log.Info("Start pond pump without UVC because of bacterium period")
+			return nil
+		}
+
+		// Each expired blister is skipped, the other UVC still start
+		if h.isBlisterExpired(blisterUVC1) {
+			log.Info("Start pond pump without UVC1 because of blister is expired")
+		} else if err = h.StartUVC1(ctx); err != nil {
 			return err
 		}
-		err = h.StartUVC2(ctx)
-		if err != nil {
+		if h.isBlisterExpired(blisterUVC2) {
+			log.Info("Start pond pump without UVC2 because of blister is expired")
+		} else if err = h.StartUVC2(ctx); err != nil {
 			return err
 		}
 
@@ -0,0 +1,35 @@
+package tfpboard
+
+import (
+	"github.com/disaster37/gobot-fat/board"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gobot.io/x/gobot/v2/drivers/gpio"
+)
+
+// setShutdownPolicy read the `shutdown.relays` board setting, keep by default to not stop filtration on restart
+func (h *TFPBoard) setShutdownPolicy(configHandler *viper.Viper) {
+	h.shutdownRelays = board.ShutdownRelays(configHandler, board.ShutdownRelaysKeep)
+}
+
+// stopRelaysOnShutdown force relays off when shutdown policy ask it
+// The state is not changed, so relays are restored as before when board start again
+func (h *TFPBoard) stopRelaysOnShutdown() {
+	if h.shutdownRelays != board.ShutdownRelaysOff {
+		return
+	}
+
+	relays := []*gpio.RelayDriver{
+		h.relayPompPond,
+		h.relayPompWaterfall,
+		h.relayUVC1,
+		h.relayUVC2,
+		h.relayBubblePond,
+		h.relayBubbleFilter,
+	}
+	for _, relay := range relays {
+		if err := relay.Off(); err != nil {
+			log.Errorf("Error when stop relay %s on board %s: %s", relay.Pin(), h.name, err.Error())
+		}
+	}
+}
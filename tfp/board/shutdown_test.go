@@ -0,0 +1,29 @@
+package tfpboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/disaster37/gobot-fat/board"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownPolicy(t *testing.T) {
+
+	// Relays are kept by default
+	tfpBoard, adaptor := initTestBoard()
+	assert.Equal(t, board.ShutdownRelaysKeep, tfpBoard.shutdownRelays)
+	assert.NoError(t, tfpBoard.Start(context.Background()))
+	assert.NoError(t, tfpBoard.StartPondPump(context.Background()))
+	assert.NoError(t, tfpBoard.Stop(context.Background()))
+	assert.Equal(t, 0, adaptor.GetDigitalPinState(tfpBoard.relayPompPond.Pin()))
+
+	// Relays are forced off
+	tfpBoard, adaptor = initTestBoard()
+	tfpBoard.shutdownRelays = board.ShutdownRelaysOff
+	assert.NoError(t, tfpBoard.Start(context.Background()))
+	assert.NoError(t, tfpBoard.StartPondPump(context.Background()))
+	assert.NoError(t, tfpBoard.Stop(context.Background()))
+	assert.Equal(t, 1, adaptor.GetDigitalPinState(tfpBoard.relayPompPond.Pin()))
+	assert.Equal(t, 1, adaptor.GetDigitalPinState(tfpBoard.relayUVC1.Pin()))
+}
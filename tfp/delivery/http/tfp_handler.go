@@ -39,6 +39,7 @@ func NewTFPHandler(e *echo.Group, us tfp.Usecase) {
 	e.POST("/tfps/action/change_ozone_blister", handler.ChangeOzoneBlister)
 	e.POST("/tfps/action/enable_waterfall_auto", handler.EnableWaterfallAuto)
 	e.POST("/tfps/action/disable_waterfall_auto", handler.DisableWaterfallAuto)
+	e.POST("/tfps/action/bacterium_introduced", handler.BacteriumIntroduced)
 	e.GET("/tfps/io", handler.GetIO)
 	e.GET("/tfps", handler.GetState)
 
@@ -80,6 +81,7 @@ func (h TFPHandler) GetIO(c echo.Context) error {
 	io, err := h.dUsecase.GetIO(ctx)
 	if err != nil {
 		log.Errorf("Error when get TFP IO: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -105,6 +107,7 @@ func (h TFPHandler) StartPondPump(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post start_pond_pump: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -129,6 +132,7 @@ func (h TFPHandler) StartPondPumpWithUVC(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post start_pond_pump: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -141,6 +145,7 @@ func (h TFPHandler) StartPondPumpWithUVC(c echo.Context) error {
 	err = h.dUsecase.UVC1(ctx, true)
 	if err != nil {
 		log.Errorf("Error when post start_uvc1: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -153,6 +158,7 @@ func (h TFPHandler) StartPondPumpWithUVC(c echo.Context) error {
 	err = h.dUsecase.UVC2(ctx, true)
 	if err != nil {
 		log.Errorf("Error when post start_uvc2: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -177,6 +183,7 @@ func (h TFPHandler) StopPondPump(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post stop_pond_pump: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -201,6 +208,7 @@ func (h TFPHandler) StartWaterfallPump(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post start_waterfall_pump: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -225,6 +233,7 @@ func (h TFPHandler) StopWaterfallPump(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post stop_waterfall_pump: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -249,6 +258,7 @@ func (h TFPHandler) StartUVC1(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post start_uvc1: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -273,6 +283,7 @@ func (h TFPHandler) StopUVC1(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post stop_uvc1: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -297,6 +308,7 @@ func (h TFPHandler) StartUVC2(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post start_uvc2: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -321,6 +333,7 @@ func (h TFPHandler) StopUVC2(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post stop_uvc2: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -345,6 +358,7 @@ func (h TFPHandler) StartPondBubble(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post start_pond_bubble: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -369,6 +383,7 @@ func (h TFPHandler) StopPondBubble(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post stop_pond_bubble: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -393,6 +408,7 @@ func (h TFPHandler) StartFilterBubble(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post start_filter_bubble: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -417,6 +433,7 @@ func (h TFPHandler) StopFilterBubble(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post stop_filter_bubble: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -441,6 +458,7 @@ func (h TFPHandler) ChangeUVC1Blister(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post change_uvc1_blister: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -465,6 +483,7 @@ func (h TFPHandler) ChangeUVC2Blister(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post change_uvc2_blister: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -489,6 +508,7 @@ func (h TFPHandler) ChangeOzoneBlister(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post change_ozone_blister: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -513,6 +533,7 @@ func (h TFPHandler) EnableWaterfallAuto(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post enable_waterfall_auto: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -537,6 +558,7 @@ func (h TFPHandler) DisableWaterfallAuto(c echo.Context) error {
 
 	if err != nil {
 		log.Errorf("Error when post disable_waterfall_auto: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
 				Status: fmt.Sprintf("%d", http.StatusBadRequest),
@@ -548,3 +570,28 @@ func (h TFPHandler) DisableWaterfallAuto(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// BacteriumIntroduced stop UVC during the bacterium period
+func (h TFPHandler) BacteriumIntroduced(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	err := h.dUsecase.BacteriumIntroduced(ctx)
+
+	if err != nil {
+		log.Errorf("Error when post bacterium_introduced: %s", err.Error())
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  "Error when introduce bacterium",
+				Detail: err.Error(),
+			},
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -18,6 +18,7 @@ type Usecase interface {
 	UVC2BlisterNew(ctx context.Context) error
 	OzoneBlisterNew(ctx context.Context) error
 	WaterfallAuto(ctx context.Context, status bool) error
+	BacteriumIntroduced(ctx context.Context) error
 	GetState(ctx context.Context) (models.TFPState, error)
 	GetIO(ctx context.Context) (models.TFPIO, error)
 }
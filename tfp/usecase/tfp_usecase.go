@@ -6,8 +6,6 @@ import (
 
 	"github.com/disaster37/gobot-fat/models"
 	"github.com/disaster37/gobot-fat/tfp"
-	"github.com/disaster37/gobot-fat/tfpconfig"
-	"github.com/disaster37/gobot-fat/tfpstate"
 	"github.com/disaster37/gobot-fat/usecase"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -85,7 +83,7 @@ func (h *tfpUsecase) WaterfallPump(c context.Context, status bool) error {
 }
 
 func (h *tfpUsecase) UVC1(c context.Context, status bool) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	ctx, cancel := context.WithTimeout(c, time.Second*30)
 	defer cancel()
 
 	if status {
@@ -215,14 +213,23 @@ func (h *tfpUsecase) WaterfallAuto(c context.Context, status bool) error {
 
 }
 
+// BacteriumIntroduced stop UVC during the bacterium period
+func (h *tfpUsecase) BacteriumIntroduced(c context.Context) error {
+	ctx, cancel := context.WithTimeout(c, h.contextTimeout)
+	defer cancel()
+
+	log.Debugf("Bacterium introduced is required by API")
+	return h.tfp.BacteriumIntroduced(ctx)
+}
+
 func (h *tfpUsecase) blisterNew(ctx context.Context, blisterName string) error {
 	state := &models.TFPState{}
-	if err := h.state.Get(ctx, tfpstate.ID, state); err != nil {
+	if err := h.state.Get(ctx, h.tfp.State().ID, state); err != nil {
 		return err
 	}
 
 	config := &models.TFPConfig{}
-	if err := h.config.Get(ctx, tfpconfig.ID, config); err != nil {
+	if err := h.config.Get(ctx, h.tfp.Config().ID, config); err != nil {
 		return err
 	}
 
@@ -230,12 +237,15 @@ func (h *tfpUsecase) blisterNew(ctx context.Context, blisterName string) error {
 	case blisterUVC1:
 		config.UVC1BlisterTime = time.Now()
 		state.UVC1BlisterNbHour = 0
+		state.UVC1BlisterAlert = 0
 	case blisterUVC2:
 		config.UVC2BlisterTime = time.Now()
 		state.UVC2BlisterNbHour = 0
+		state.UVC2BlisterAlert = 0
 	case blisterOzone:
 		config.OzoneBlisterTime = time.Now()
 		state.OzoneBlisterNbHour = 0
+		state.OzoneBlisterAlert = 0
 	default:
 		return errors.Errorf("Blister %s not found", blisterName)
 
@@ -260,7 +270,7 @@ func (h *tfpUsecase) GetIO(ctx context.Context) (models.TFPIO, error) {
 func (h *tfpUsecase) waterfallAuto(ctx context.Context, state bool) error {
 
 	config := &models.TFPConfig{}
-	if err := h.config.Get(ctx, tfpconfig.ID, config); err != nil {
+	if err := h.config.Get(ctx, h.tfp.Config().ID, config); err != nil {
 		return err
 	}
 
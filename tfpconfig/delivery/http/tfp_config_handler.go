@@ -6,8 +6,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/disaster37/gobot-fat/helper"
 	"github.com/disaster37/gobot-fat/models"
-	"github.com/disaster37/gobot-fat/tfpconfig"
+	"github.com/disaster37/gobot-fat/repository"
 	"github.com/disaster37/gobot-fat/usecase"
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
@@ -17,12 +18,15 @@ import (
 // TFPConfigHandler  represent the httphandler for tfp_config
 type TFPConfigHandler struct {
 	us usecase.UsecaseCRUD
+	id uint
 }
 
 // NewTFPConfigHandler will initialize the TFP_config/ resources endpoint
-func NewTFPConfigHandler(e *echo.Group, us usecase.UsecaseCRUD) {
+// The routes without ID use the TFP config with id
+func NewTFPConfigHandler(e *echo.Group, us usecase.UsecaseCRUD, id uint) {
 	handler := &TFPConfigHandler{
 		us: us,
+		id: id,
 	}
 	e.GET("/tfp-configs", handler.Get)
 	e.PATCH("/tfp-configs/:id", handler.Update)
@@ -38,7 +42,7 @@ func (h *TFPConfigHandler) Get(c echo.Context) error {
 	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
 
 	data := &models.TFPConfig{}
-	if err := h.us.Get(ctx, tfpconfig.ID, data); err != nil {
+	if err := h.us.Get(ctx, h.id, data); err != nil {
 		log.Errorf("Error when get tfp_config: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -50,19 +54,32 @@ func (h *TFPConfigHandler) Get(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(data.Version))
 	c.Response().WriteHeader(http.StatusOK)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), data)
 }
 
 // UpdateOld permit to update the current TFP config without set ID
 func (h *TFPConfigHandler) UpdateOld(c echo.Context) error {
-	var err error
 	ctx := c.Request().Context()
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
 
+	// Version read by client, to not overwrite another update
+	version, isVersion, err := helper.ExpectedVersion(c.Request())
+	if err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  "Error when update tfp_config",
+				Detail: err.Error(),
+			},
+		})
+	}
+
 	config := &models.TFPConfig{}
 	if err = jsonapi.UnmarshalPayload(c.Request().Body, config); err != nil {
 		c.Response().WriteHeader(http.StatusBadRequest)
@@ -75,11 +92,31 @@ func (h *TFPConfigHandler) UpdateOld(c echo.Context) error {
 		})
 	}
 
-	config.ID = tfpconfig.ID
+	config.ID = h.id
+
+	if err = models.Validate(config); err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return helper.MarshalValidationErrors(c.Response(), "Error when update tfp_config", err)
+	}
 
 	log.Debugf("Data: %+v", config)
 
-	if err = h.us.Update(ctx, config); err != nil {
+	if isVersion {
+		err = h.us.UpdateIfVersion(ctx, config, version)
+	} else {
+		err = h.us.Update(ctx, config)
+	}
+	if err != nil {
+		if repository.IsVersionConflictError(err) {
+			c.Response().WriteHeader(http.StatusConflict)
+			return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+				{
+					Status: fmt.Sprintf("%d", http.StatusConflict),
+					Title:  "Error when update tfp_config",
+					Detail: err.Error(),
+				},
+			})
+		}
 		log.Errorf("Error when update tfp_config: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -91,6 +128,7 @@ func (h *TFPConfigHandler) UpdateOld(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(config.Version))
 	c.Response().WriteHeader(http.StatusCreated)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), config)
 }
@@ -104,8 +142,9 @@ func (h *TFPConfigHandler) Update(c echo.Context) error {
 	}
 	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
 
-	config := &models.TFPConfig{}
-	if err = jsonapi.UnmarshalPayload(c.Request().Body, config); err != nil {
+	// Version read by client, to not overwrite another update
+	version, isVersion, err := helper.ExpectedVersion(c.Request())
+	if err != nil {
 		c.Response().WriteHeader(http.StatusBadRequest)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
 			{
@@ -115,6 +154,7 @@ func (h *TFPConfigHandler) Update(c echo.Context) error {
 			},
 		})
 	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.Response().WriteHeader(http.StatusBadRequest)
@@ -126,11 +166,65 @@ func (h *TFPConfigHandler) Update(c echo.Context) error {
 			},
 		})
 	}
+
+	// Only attributes sent by client are updated
+	config := &models.TFPConfig{}
+	if err = h.us.Get(ctx, uint(id), config); err != nil {
+		if repository.IsRecordNotFoundError(err) {
+			c.Response().WriteHeader(http.StatusNotFound)
+			return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+				{
+					Status: fmt.Sprintf("%d", http.StatusNotFound),
+					Title:  "Error when update tfp_config",
+					Detail: err.Error(),
+				},
+			})
+		}
+		log.Errorf("Error when get tfp_config: %s", err.Error())
+		c.Response().WriteHeader(http.StatusInternalServerError)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusInternalServerError),
+				Title:  "Error when update tfp_config",
+				Detail: err.Error(),
+			},
+		})
+	}
+	if err = jsonapi.UnmarshalPayload(c.Request().Body, config); err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  "Error when update tfp_config",
+				Detail: err.Error(),
+			},
+		})
+	}
 	config.ID = uint(id)
 
+	if err = models.Validate(config); err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return helper.MarshalValidationErrors(c.Response(), "Error when update tfp_config", err)
+	}
+
 	log.Debugf("Data: %+v", config)
 
-	if err = h.us.Update(ctx, config); err != nil {
+	if isVersion {
+		err = h.us.UpdateIfVersion(ctx, config, version)
+	} else {
+		err = h.us.Update(ctx, config)
+	}
+	if err != nil {
+		if repository.IsVersionConflictError(err) {
+			c.Response().WriteHeader(http.StatusConflict)
+			return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+				{
+					Status: fmt.Sprintf("%d", http.StatusConflict),
+					Title:  "Error when update tfp_config",
+					Detail: err.Error(),
+				},
+			})
+		}
 		log.Errorf("Error when update tfp_config: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -142,6 +236,7 @@ func (h *TFPConfigHandler) Update(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(config.Version))
 	c.Response().WriteHeader(http.StatusCreated)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), config)
 }
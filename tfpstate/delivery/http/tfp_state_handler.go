@@ -6,8 +6,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/disaster37/gobot-fat/helper"
 	"github.com/disaster37/gobot-fat/models"
-	"github.com/disaster37/gobot-fat/tfpstate"
+	"github.com/disaster37/gobot-fat/repository"
 	"github.com/disaster37/gobot-fat/usecase"
 	"github.com/google/jsonapi"
 	"github.com/labstack/echo/v4"
@@ -17,12 +18,15 @@ import (
 // TFPStateHandler  represent the httphandler for tfp_state
 type TFPStateHandler struct {
 	us usecase.UsecaseCRUD
+	id uint
 }
 
 // NewTFPStateHandler will initialize the TFP_state/ resources endpoint
-func NewTFPStateHandler(e *echo.Group, us usecase.UsecaseCRUD) {
+// The routes without ID use the TFP state with id
+func NewTFPStateHandler(e *echo.Group, us usecase.UsecaseCRUD, id uint) {
 	handler := &TFPStateHandler{
 		us: us,
+		id: id,
 	}
 	e.GET("/tfp-states", handler.Get)
 	e.PATCH("/tfp-states/:id", handler.Update)
@@ -39,7 +43,7 @@ func (h *TFPStateHandler) Get(c echo.Context) error {
 	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
 
 	state := &models.TFPState{}
-	if err := h.us.Get(ctx, tfpstate.ID, state); err != nil {
+	if err := h.us.Get(ctx, h.id, state); err != nil {
 		log.Errorf("Error when get tfp_state: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -51,6 +55,7 @@ func (h *TFPStateHandler) Get(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(state.Version))
 	c.Response().WriteHeader(http.StatusOK)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), state)
 }
@@ -58,13 +63,25 @@ func (h *TFPStateHandler) Get(c echo.Context) error {
 // Update permit to update the current TFP state
 // We can only update field about nbHourUVC / nbHourOzone
 func (h *TFPStateHandler) UpdateOld(c echo.Context) error {
-	var err error
 	ctx := c.Request().Context()
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
 
+	// Version read by client, to not overwrite another update
+	version, isVersion, err := helper.ExpectedVersion(c.Request())
+	if err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  "Error when update tfp_state",
+				Detail: err.Error(),
+			},
+		})
+	}
+
 	// Get the expected TFPState
 	expectedState := &models.TFPState{}
 	if err = jsonapi.UnmarshalPayload(c.Request().Body, expectedState); err != nil {
@@ -77,12 +94,12 @@ func (h *TFPStateHandler) UpdateOld(c echo.Context) error {
 			},
 		})
 	}
-	expectedState.ID = tfpstate.ID
+	expectedState.ID = h.id
 	log.Debugf("Expected TFPState: %+v", expectedState)
 
 	// Get the current TFPState
 	currentState := &models.TFPState{}
-	if err := h.us.Get(ctx, tfpstate.ID, currentState); err != nil {
+	if err := h.us.Get(ctx, h.id, currentState); err != nil {
 		return err
 	}
 	log.Debugf("Current TFPState: %+v", expectedState)
@@ -93,7 +110,22 @@ func (h *TFPStateHandler) UpdateOld(c echo.Context) error {
 	currentState.UVC2BlisterNbHour = expectedState.UVC2BlisterNbHour
 	log.Debugf("Final TFPState: %+v", currentState)
 
-	if err = h.us.Update(ctx, currentState); err != nil {
+	if isVersion {
+		err = h.us.UpdateIfVersion(ctx, currentState, version)
+	} else {
+		err = h.us.Update(ctx, currentState)
+	}
+	if err != nil {
+		if repository.IsVersionConflictError(err) {
+			c.Response().WriteHeader(http.StatusConflict)
+			return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+				{
+					Status: fmt.Sprintf("%d", http.StatusConflict),
+					Title:  "Error when update tfp_state",
+					Detail: err.Error(),
+				},
+			})
+		}
 		log.Errorf("Error when update tfp_state: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -105,6 +137,7 @@ func (h *TFPStateHandler) UpdateOld(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(currentState.Version))
 	c.Response().WriteHeader(http.StatusCreated)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), currentState)
 }
@@ -118,6 +151,19 @@ func (h *TFPStateHandler) Update(c echo.Context) error {
 	}
 	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
 
+	// Version read by client, to not overwrite another update
+	version, isVersion, err := helper.ExpectedVersion(c.Request())
+	if err != nil {
+		c.Response().WriteHeader(http.StatusBadRequest)
+		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+			{
+				Status: fmt.Sprintf("%d", http.StatusBadRequest),
+				Title:  "Error when update tfp_state",
+				Detail: err.Error(),
+			},
+		})
+	}
+
 	// Get the expected TFPState
 	expectedState := &models.TFPState{}
 	if err = jsonapi.UnmarshalPayload(c.Request().Body, expectedState); err != nil {
@@ -157,7 +203,22 @@ func (h *TFPStateHandler) Update(c echo.Context) error {
 	currentState.UVC2BlisterNbHour = expectedState.UVC2BlisterNbHour
 	log.Debugf("Final TFPState: %+v", currentState)
 
-	if err = h.us.Update(ctx, currentState); err != nil {
+	if isVersion {
+		err = h.us.UpdateIfVersion(ctx, currentState, version)
+	} else {
+		err = h.us.Update(ctx, currentState)
+	}
+	if err != nil {
+		if repository.IsVersionConflictError(err) {
+			c.Response().WriteHeader(http.StatusConflict)
+			return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+				{
+					Status: fmt.Sprintf("%d", http.StatusConflict),
+					Title:  "Error when update tfp_state",
+					Detail: err.Error(),
+				},
+			})
+		}
 		log.Errorf("Error when update tfp_state: %s", err.Error())
 		c.Response().WriteHeader(http.StatusInternalServerError)
 		return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
@@ -169,6 +230,7 @@ func (h *TFPStateHandler) Update(c echo.Context) error {
 		})
 	}
 
+	c.Response().Header().Set(helper.HeaderETag, helper.ETag(currentState.Version))
 	c.Response().WriteHeader(http.StatusCreated)
 	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), currentState)
 }
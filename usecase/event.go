@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/disaster37/gobot-fat/metrics"
 	"github.com/disaster37/gobot-fat/models"
 	"github.com/disaster37/gobot-fat/repository"
 	log "github.com/sirupsen/logrus"
@@ -57,6 +58,7 @@ func (h *UsecaseEvent) Create(ctx context.Context, data interface{}) error {
 
 	err := h.ElasticRepo.Create(ctx, data)
 	if err != nil {
+		metrics.EventFailures.Inc()
 		return err
 	}
 	log.Infof("Create data successfully")
@@ -86,6 +88,33 @@ func (h *UsecaseEvent) Update(ctx context.Context, data interface{}) error {
 	return nil
 }
 
+// UpdateIfVersion permit to update object only if it was not updated since the version was read
+func (h *UsecaseEvent) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+
+	if data == nil {
+		return errors.New("data can't be null")
+	}
+
+	elasticRepo, ok := h.ElasticRepo.(repository.ConditionalRepository)
+	if !ok {
+		return errors.New("Repository not support conditional update")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	// Manage version
+	data.(models.Model).SetVersion(version + 1)
+
+	err := elasticRepo.UpdateIfVersion(ctx, data, version)
+	if err != nil {
+		return err
+	}
+	log.Infof("Update data  successfully")
+
+	return nil
+}
+
 // Init permit to init data or refresh data from repostory
 func (h *UsecaseEvent) Init(ctx context.Context, data interface{}) error {
 
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/metrics"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// OverflowDropOldest remove the oldest event when queue is full
+	OverflowDropOldest = "drop_oldest"
+
+	// OverflowDropNew refuse the new event when queue is full
+	OverflowDropNew = "drop_new"
+
+	// DefaultEventQueueSize is the default number of events that can wait on queue
+	DefaultEventQueueSize = 1000
+
+	// DefaultEventBatchSize is the default number of events sent with one bulk request
+	DefaultEventBatchSize = 100
+
+	// DefaultEventFlushInterval is the default maximum time that event wait on queue
+	DefaultEventFlushInterval = 5 * time.Second
+)
+
+// ErrEventQueueFull is returned when event is dropped because of queue is full
+var ErrEventQueueFull = errors.New("Event queue is full")
+
+// UsecaseBulkEvent represent event usecase that store events on background by batch
+// Create never wait Elasticsearch, so boards are not slowed down by it
+type UsecaseBulkEvent struct {
+	*UsecaseEvent
+	repos     []repository.Repository
+	queue     chan interface{}
+	batchSize int
+	interval  time.Duration
+	overflow  string
+	chStop    chan bool
+	wg        sync.WaitGroup
+	isStarted bool
+	sync.Mutex
+}
+
+// NewBulkEventUsecase permit to create new usecase that send events by batch on background.
+// The batch is sent when it reach batchSize events or when interval is elapsed.
+// The overflow policy is used when queue is full, drop_oldest by default.
+// Default values are used when sizes or interval are not set.
+// Events are stored on repo and on other repositories, like SQL and Elasticsearch.
+func NewBulkEventUsecase(repo repository.Repository, timeout time.Duration, queueSize int, batchSize int, interval time.Duration, overflow string, otherRepos ...repository.Repository) *UsecaseBulkEvent {
+	if queueSize <= 0 {
+		queueSize = DefaultEventQueueSize
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultEventBatchSize
+	}
+	if interval <= 0 {
+		interval = DefaultEventFlushInterval
+	}
+	if overflow != OverflowDropNew {
+		overflow = OverflowDropOldest
+	}
+
+	return &UsecaseBulkEvent{
+		UsecaseEvent: &UsecaseEvent{
+			ElasticRepo:    repo,
+			contextTimeout: timeout,
+		},
+		repos:     append([]repository.Repository{repo}, otherRepos...),
+		queue:     make(chan interface{}, queueSize),
+		batchSize: batchSize,
+		interval:  interval,
+		overflow:  overflow,
+	}
+}
+
+// Create add event on queue without waiting it's stored
+func (h *UsecaseBulkEvent) Create(ctx context.Context, data interface{}) error {
+
+	if data == nil {
+		return errors.New("data can't be null")
+	}
+
+	// Init version
+	data.(models.Model).SetVersion(0)
+
+	select {
+	case h.queue <- data:
+		return nil
+	default:
+	}
+
+	// Queue is full
+	if h.overflow == OverflowDropOldest {
+		select {
+		case <-h.queue:
+			metrics.EventsDropped.Inc()
+			log.Warn("Event queue is full, drop the oldest event")
+		default:
+		}
+		select {
+		case h.queue <- data:
+			return nil
+		default:
+		}
+	}
+
+	metrics.EventsDropped.Inc()
+	return ErrEventQueueFull
+}
+
+// Start send events on background
+func (h *UsecaseBulkEvent) Start(ctx context.Context) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.isStarted {
+		return
+	}
+	h.chStop = make(chan bool)
+	h.isStarted = true
+
+	h.wg.Add(1)
+	go h.run(h.chStop)
+}
+
+// Stop send the events that wait on queue, then stop background worker
+func (h *UsecaseBulkEvent) Stop(ctx context.Context) {
+	h.Lock()
+	defer h.Unlock()
+
+	if !h.isStarted {
+		return
+	}
+	close(h.chStop)
+	h.wg.Wait()
+	h.isStarted = false
+}
+
+// Len return the number of events that wait on queue
+func (h *UsecaseBulkEvent) Len() int {
+	return len(h.queue)
+}
+
+// Cap return the number of events that can wait on queue
+func (h *UsecaseBulkEvent) Cap() int {
+	return cap(h.queue)
+}
+
+// run read the queue and send events by batch
+func (h *UsecaseBulkEvent) run(chStop chan bool) {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	batch := make([]interface{}, 0, h.batchSize)
+	for {
+		select {
+		case data := <-h.queue:
+			batch = append(batch, data)
+			if len(batch) >= h.batchSize {
+				h.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				h.flush(batch)
+				batch = batch[:0]
+			}
+		case <-chStop:
+			// Send all events before stop
+			for {
+				select {
+				case data := <-h.queue:
+					batch = append(batch, data)
+					if len(batch) >= h.batchSize {
+						h.flush(batch)
+						batch = batch[:0]
+					}
+				default:
+					if len(batch) > 0 {
+						h.flush(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush store events on all repositories
+func (h *UsecaseBulkEvent) flush(batch []interface{}) {
+	for _, repo := range h.repos {
+		h.flushRepository(repo, batch)
+	}
+}
+
+// flushRepository store events with bulk request when repository support it
+func (h *UsecaseBulkEvent) flushRepository(repo repository.Repository, batch []interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.contextTimeout)
+	defer cancel()
+
+	if bulkRepo, ok := repo.(repository.BulkRepository); ok {
+		failed, err := bulkRepo.Bulk(ctx, batch)
+		if err != nil {
+			metrics.EventFailures.Add(float64(len(failed)))
+			log.Errorf("Error when store %d events: %s", len(failed), err.Error())
+		}
+		log.Debugf("Store %d events", len(batch)-len(failed))
+		return
+	}
+
+	for _, data := range batch {
+		if err := repo.Create(ctx, data); err != nil {
+			metrics.EventFailures.Inc()
+			log.Errorf("Error when store event: %s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// bulkRepository record bulk requests and can block them
+type bulkRepository struct {
+	repository.MockBase
+	batches [][]interface{}
+	chBlock chan bool
+	sync.Mutex
+}
+
+func (m *bulkRepository) Bulk(ctx context.Context, listData []interface{}) ([]interface{}, error) {
+	if m.chBlock != nil {
+		<-m.chBlock
+	}
+	m.Lock()
+	defer m.Unlock()
+	batch := make([]interface{}, len(listData))
+	copy(batch, listData)
+	m.batches = append(m.batches, batch)
+	return nil, nil
+}
+
+func (m *bulkRepository) sizes() []int {
+	m.Lock()
+	defer m.Unlock()
+	sizes := make([]int, 0, len(m.batches))
+	for _, batch := range m.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func TestBulkEventFlushOnSize(t *testing.T) {
+	repo := &bulkRepository{}
+	us := NewBulkEventUsecase(repo, 1*time.Second, 10, 2, 1*time.Hour, OverflowDropOldest)
+	us.Start(context.Background())
+	defer us.Stop(context.Background())
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, us.Create(context.Background(), &models.Event{EventKind: "wash"}))
+	}
+
+	assert.Eventually(t, func() bool { return len(repo.sizes()) == 2 }, 1*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{2, 2}, repo.sizes())
+}
+
+func TestBulkEventFlushOnInterval(t *testing.T) {
+	repo := &bulkRepository{}
+	us := NewBulkEventUsecase(repo, 1*time.Second, 10, 100, 50*time.Millisecond, OverflowDropOldest)
+	us.Start(context.Background())
+	defer us.Stop(context.Background())
+
+	assert.NoError(t, us.Create(context.Background(), &models.Event{EventKind: "wash"}))
+	assert.Eventually(t, func() bool { return len(repo.sizes()) == 1 }, 1*time.Second, 10*time.Millisecond)
+
+	// When data is nil
+	assert.Error(t, us.Create(context.Background(), nil))
+}
+
+func TestBulkEventFlushOnStop(t *testing.T) {
+	repo := &bulkRepository{}
+	us := NewBulkEventUsecase(repo, 1*time.Second, 10, 100, 1*time.Hour, OverflowDropOldest)
+	us.Start(context.Background())
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, us.Create(context.Background(), &models.Event{EventKind: "wash"}))
+	}
+	us.Stop(context.Background())
+
+	assert.Equal(t, []int{3}, repo.sizes())
+	assert.Equal(t, 0, us.Len())
+}
+
+func TestBulkEventOverflow(t *testing.T) {
+
+	// Drop new events when queue is full
+	us := NewBulkEventUsecase(&bulkRepository{}, 1*time.Second, 2, 100, 1*time.Hour, OverflowDropNew)
+	assert.NoError(t, us.Create(context.Background(), &models.Event{EventKind: "first"}))
+	assert.NoError(t, us.Create(context.Background(), &models.Event{EventKind: "second"}))
+	assert.ErrorIs(t, us.Create(context.Background(), &models.Event{EventKind: "third"}), ErrEventQueueFull)
+	assert.Equal(t, "first", (<-us.queue).(*models.Event).EventKind)
+
+	// Drop oldest events when queue is full
+	us = NewBulkEventUsecase(&bulkRepository{}, 1*time.Second, 2, 100, 1*time.Hour, "")
+	assert.NoError(t, us.Create(context.Background(), &models.Event{EventKind: "first"}))
+	assert.NoError(t, us.Create(context.Background(), &models.Event{EventKind: "second"}))
+	assert.NoError(t, us.Create(context.Background(), &models.Event{EventKind: "third"}))
+	assert.Equal(t, 2, us.Len())
+	assert.Equal(t, "second", (<-us.queue).(*models.Event).EventKind)
+}
+
+func TestBulkEventNotBlockOnSlowRepository(t *testing.T) {
+	repo := &bulkRepository{chBlock: make(chan bool)}
+	us := NewBulkEventUsecase(repo, 1*time.Second, 5, 1, 1*time.Hour, OverflowDropOldest)
+	us.Start(context.Background())
+
+	// Create return immediately even if repository is blocked
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, us.Create(context.Background(), &models.Event{EventKind: "wash"}))
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	close(repo.chBlock)
+	us.Stop(context.Background())
+	assert.NotEmpty(t, repo.sizes())
+}
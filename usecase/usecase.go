@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/disaster37/gobot-fat/metrics"
 	"github.com/disaster37/gobot-fat/models"
 	"github.com/disaster37/gobot-fat/repository"
 	log "github.com/sirupsen/logrus"
@@ -17,6 +18,7 @@ type UsecaseCRUD interface {
 	Get(ctx context.Context, id uint, data interface{}) error
 	List(ctx context.Context, listData interface{}) error
 	Update(ctx context.Context, data interface{}) error
+	UpdateIfVersion(ctx context.Context, data interface{}, version int64) error
 	Create(ctx context.Context, data interface{}) error
 	Init(ctx context.Context, data interface{}) error
 }
@@ -74,12 +76,14 @@ func (h *UsecaseCRUDGeneric) Create(ctx context.Context, data interface{}) error
 
 	err := h.SQLRepo.Create(ctx, data)
 	if err != nil {
+		metrics.RepositoryFailures.WithLabelValues("sql", "create").Inc()
 		return err
 	}
 	log.Infof("Create data on SQL backend successfully")
 
 	err = h.ElasticRepo.Create(ctx, data)
 	if err != nil {
+		metrics.RepositoryFailures.WithLabelValues("elasticsearch", "create").Inc()
 		log.Errorf("Create Data on Elasticsearch backend failed: %s", err.Error())
 	} else {
 		log.Infof("Create data on Elasticsearch backend successfully")
@@ -105,12 +109,14 @@ func (h *UsecaseCRUDGeneric) Update(ctx context.Context, data interface{}) error
 
 	err := h.SQLRepo.Update(ctx, data)
 	if err != nil {
+		metrics.RepositoryFailures.WithLabelValues("sql", "update").Inc()
 		return err
 	}
 	log.Infof("Update data on SQL backend successfully")
 
 	err = h.ElasticRepo.Update(ctx, data)
 	if err != nil {
+		metrics.RepositoryFailures.WithLabelValues("elasticsearch", "update").Inc()
 		log.Errorf("Update data on Elasticsearch backend failed: %s", err.Error())
 	} else {
 		log.Infof("Update data on Elasticsearch backend successfully")
@@ -121,6 +127,68 @@ func (h *UsecaseCRUDGeneric) Update(ctx context.Context, data interface{}) error
 	return nil
 }
 
+// UpdateIfVersion permit to update object on all repository only if it was not updated since the version was read
+// The version is checked on SQL, then on Elasticsearch with its sequence number.
+// It return repository.ErrVersionConflict when another writer updated it before on SQL.
+// SQL is the reference: on Elasticsearch conflict, the current SQL record is written on Elasticsearch.
+func (h *UsecaseCRUDGeneric) UpdateIfVersion(ctx context.Context, data interface{}, version int64) error {
+
+	if data == nil {
+		return errors.New("data can't be null")
+	}
+
+	sqlRepo, ok := h.SQLRepo.(repository.ConditionalRepository)
+	if !ok {
+		return errors.New("SQL repository not support conditional update")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	// Manage version
+	data.(models.Model).SetVersion(version + 1)
+
+	err := sqlRepo.UpdateIfVersion(ctx, data, version)
+	if err != nil {
+		if !repository.IsVersionConflictError(err) {
+			metrics.RepositoryFailures.WithLabelValues("sql", "update").Inc()
+		}
+		return err
+	}
+	log.Infof("Update data on SQL backend successfully")
+
+	if elasticRepo, ok := h.ElasticRepo.(repository.ConditionalRepository); ok {
+		err = elasticRepo.UpdateIfVersion(ctx, data, version)
+		if repository.IsVersionConflictError(err) || repository.IsRecordNotFoundError(err) {
+			log.Warnf("Elasticsearch not have the version %d, write the current SQL record on it: %s", version, err.Error())
+			err = h.syncElastic(ctx, data)
+		}
+	} else {
+		err = h.ElasticRepo.Update(ctx, data)
+	}
+	if err != nil {
+		metrics.RepositoryFailures.WithLabelValues("elasticsearch", "update").Inc()
+		log.Errorf("Update data on Elasticsearch backend failed: %s", err.Error())
+	} else {
+		log.Infof("Update data on Elasticsearch backend successfully")
+	}
+
+	h.Publish(h.eventName, data)
+
+	return nil
+}
+
+// syncElastic write the current SQL record on Elasticsearch without condition
+// The record is read again, because of another writer can update it since data was written
+func (h *UsecaseCRUDGeneric) syncElastic(ctx context.Context, data interface{}) error {
+	current := reflect.New(reflect.TypeOf(data).Elem()).Interface()
+	if err := h.SQLRepo.Get(ctx, data.(models.Model).GetID(), current); err != nil {
+		return err
+	}
+
+	return h.ElasticRepo.Update(ctx, current)
+}
+
 // Init permit to init data or refresh data from repostory
 func (h *UsecaseCRUDGeneric) Init(ctx context.Context, data interface{}) error {
 
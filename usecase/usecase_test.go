@@ -234,6 +234,82 @@ func TestUpdate(t *testing.T) {
 	assert.Equal(t, result, sqlMock.ExpectResult())
 }
 
+func TestUpdateIfVersion(t *testing.T) {
+	sqlMock := repository.NewMock()
+	elasticMock := repository.NewMock()
+
+	eventer := gobot.NewEventer()
+	eventer.AddEvent("test")
+
+	us := NewUsecase(sqlMock, elasticMock, time.Duration(10*time.Second), eventer, "test")
+
+	// When no data
+	err := us.UpdateIfVersion(context.Background(), nil, 1)
+	assert.Error(t, err)
+
+	// When data
+	result := &models.DFPConfig{
+		WashingDuration: 10,
+	}
+	result.ID = 1
+
+	err = us.UpdateIfVersion(context.Background(), result, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), result.Version)
+	isCalled, reason := sqlMock.ExpectCall("UpdateIfVersion", result, int64(3))
+	assert.True(t, isCalled)
+	if reason != "" {
+		t.Error(reason)
+	}
+	isCalled, reason = elasticMock.ExpectCall("UpdateIfVersion", result, int64(3))
+	assert.True(t, isCalled)
+	if reason != "" {
+		t.Error(reason)
+	}
+
+	// When Elasticsearch not have the version, the current SQL record is written on it
+	current := &models.DFPConfig{
+		WashingDuration: 20,
+	}
+	current.ID = 1
+	current.Version = 5
+	sqlMock.TestGet(func(ctx context.Context, id uint, data interface{}) error {
+		*data.(*models.DFPConfig) = *current
+		return nil
+	})
+	elasticMock.TestUpdateIfVersion(func(ctx context.Context, data interface{}, version int64) error {
+		return repository.ErrVersionConflict
+	})
+	err = us.UpdateIfVersion(context.Background(), result, 3)
+	assert.NoError(t, err)
+	isCalled, reason = elasticMock.ExpectCall("Update", current)
+	assert.True(t, isCalled)
+	if reason != "" {
+		t.Error(reason)
+	}
+	sqlMock = repository.NewMock()
+	elasticMock = repository.NewMock()
+	us = NewUsecase(sqlMock, elasticMock, time.Duration(10*time.Second), eventer, "test")
+
+	// When version conflict on SQL
+	sqlMock.SetError(repository.ErrVersionConflict)
+	elasticMock.SetData(nil)
+	err = us.UpdateIfVersion(context.Background(), result, 3)
+	assert.True(t, repository.IsVersionConflictError(err))
+	assert.Nil(t, elasticMock.ExpectResult())
+
+	// When Elastic repo return error
+	sqlMock.SetError(nil)
+	elasticMock.SetError(errors.New("Elasticsearch is down"))
+	err = us.UpdateIfVersion(context.Background(), result, 3)
+	assert.NoError(t, err)
+
+	// When SQL repo not support conditional update
+	us = NewUsecase(repository.NewMockBase(), elasticMock, time.Duration(10*time.Second), eventer, "test")
+	err = us.UpdateIfVersion(context.Background(), result, 3)
+	assert.Error(t, err)
+}
+
 func TestCreate(t *testing.T) {
 	sqlMock := repository.NewMock()
 	elasticMock := repository.NewMock()
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/user"
+	"github.com/google/jsonapi"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// UserHandler represent the httphandler for user
+type UserHandler struct {
+	us user.Usecase
+}
+
+// NewUserHandler will initialize the users/ resources endpoint
+// The middlewares are applied on all routes, to only permit admin to manage users
+func NewUserHandler(e *echo.Group, us user.Usecase, m ...echo.MiddlewareFunc) {
+	handler := &UserHandler{
+		us: us,
+	}
+	e.GET("/users", handler.List, m...)
+	e.GET("/users/:id", handler.Get, m...)
+	e.POST("/users", handler.Create, m...)
+	e.PATCH("/users/:id", handler.Update, m...)
+	e.DELETE("/users/:id", handler.Delete, m...)
+}
+
+// List will get all users
+func (h *UserHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	users, err := h.us.List(ctx)
+	if err != nil {
+		return h.error(c, "Error when list users", err)
+	}
+
+	c.Response().WriteHeader(http.StatusOK)
+	return jsonapi.MarshalPayload(c.Response(), users)
+}
+
+// Get will get one user
+func (h *UserHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return h.error(c, "Error when get user", errors.Wrap(user.ErrInvalidUser, err.Error()))
+	}
+
+	data, err := h.us.Get(ctx, uint(id))
+	if err != nil {
+		return h.error(c, "Error when get user", err)
+	}
+
+	c.Response().WriteHeader(http.StatusOK)
+	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), data)
+}
+
+// Create will add new user
+func (h *UserHandler) Create(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	data := &models.User{}
+	if err := jsonapi.UnmarshalPayload(c.Request().Body, data); err != nil {
+		return h.error(c, "Error when create user", errors.Wrap(user.ErrInvalidUser, err.Error()))
+	}
+
+	if err := h.us.Create(ctx, data); err != nil {
+		return h.error(c, "Error when create user", err)
+	}
+
+	c.Response().WriteHeader(http.StatusCreated)
+	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), data)
+}
+
+// Update will change user role, username or password
+func (h *UserHandler) Update(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+
+	data := &models.User{}
+	if err := jsonapi.UnmarshalPayload(c.Request().Body, data); err != nil {
+		return h.error(c, "Error when update user", errors.Wrap(user.ErrInvalidUser, err.Error()))
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return h.error(c, "Error when update user", errors.Wrap(user.ErrInvalidUser, err.Error()))
+	}
+	data.ID = uint(id)
+
+	if err = h.us.Update(ctx, data); err != nil {
+		return h.error(c, "Error when update user", err)
+	}
+
+	c.Response().WriteHeader(http.StatusOK)
+	return jsonapi.MarshalOnePayloadEmbedded(c.Response(), data)
+}
+
+// Delete will remove user
+func (h *UserHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+		return h.error(c, "Error when delete user", errors.Wrap(user.ErrInvalidUser, err.Error()))
+	}
+
+	if err = h.us.Delete(ctx, uint(id)); err != nil {
+		c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+		return h.error(c, "Error when delete user", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// error write the jsonapi error with the status that match the error
+func (h *UserHandler) error(c echo.Context, title string, err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, user.ErrInvalidUser):
+		status = http.StatusBadRequest
+	case errors.Is(err, user.ErrUserAlreadyExist), errors.Is(err, user.ErrLastAdmin):
+		status = http.StatusConflict
+	case repository.IsRecordNotFoundError(err):
+		status = http.StatusNotFound
+	default:
+		log.Errorf("%s: %s", title, err.Error())
+	}
+
+	c.Response().WriteHeader(status)
+	return jsonapi.MarshalErrors(c.Response(), []*jsonapi.ErrorObject{
+		{
+			Status: fmt.Sprintf("%d", status),
+			Title:  title,
+			Detail: err.Error(),
+		},
+	})
+}
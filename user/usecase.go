@@ -0,0 +1,40 @@
+package user
+
+import (
+	"context"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidUser is returned when user can't be saved because of bad value
+	ErrInvalidUser = errors.New("Invalid user")
+
+	// ErrUserAlreadyExist is returned when username is already used
+	ErrUserAlreadyExist = errors.New("User already exist")
+
+	// ErrLastAdmin is returned when try to delete or downgrade the last admin
+	ErrLastAdmin = errors.New("At least one admin is needed")
+
+	// ErrBadCredentials is returned when username or password not match
+	ErrBadCredentials = errors.New("Bad credentials")
+)
+
+// Usecase represent the user usecase
+type Usecase interface {
+	Get(ctx context.Context, id uint) (*models.User, error)
+	List(ctx context.Context) ([]*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id uint) error
+
+	// GetByUsername return the user with username
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+
+	// Authenticate return the user if password match
+	Authenticate(ctx context.Context, username string, password string) (*models.User, error)
+
+	// Init create the admin user when there are no user yet
+	Init(ctx context.Context, username string, password string) error
+}
@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/user"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared when user not exist, so the response time not reveal the existing usernames
+const dummyHash = "$2a$10$JqNy0L2HWLg0OvR4hfURd.berJ9v1EQFTs432NlXkgOC92gNPutDG"
+
+type userUsecase struct {
+	repo           repository.Repository
+	contextTimeout time.Duration
+	mu             sync.Mutex
+}
+
+// NewUserUsecase will create new userUsecase object of user.Usecase interface
+// Users are only stored on SQL repository because of they contain password hash
+func NewUserUsecase(repo repository.Repository, timeout time.Duration) user.Usecase {
+	return &userUsecase{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+// Get return the user with ID
+func (h *userUsecase) Get(ctx context.Context, id uint) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	data, err := h.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	data.Password = ""
+
+	return data, nil
+}
+
+// GetByUsername return the user with username
+func (h *userUsecase) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	data, err := h.byUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, repository.ErrRecordNotFoundError
+	}
+	data.Password = ""
+
+	return data, nil
+}
+
+// List return all users
+func (h *userUsecase) List(ctx context.Context) ([]*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	return h.list(ctx)
+}
+
+// Create add new user and hash its password
+func (h *userUsecase) Create(ctx context.Context, data *models.User) error {
+	if data == nil {
+		return errors.New("User can't be null")
+	}
+	if data.Username == "" {
+		return errors.Wrap(user.ErrInvalidUser, "username is required")
+	}
+	if data.Password == "" {
+		return errors.Wrap(user.ErrInvalidUser, "password is required")
+	}
+	if !models.IsValidRole(data.Role) {
+		return errors.Wrapf(user.ErrInvalidUser, "role must be %s, %s or %s", models.RoleAdmin, models.RoleOperator, models.RoleReadOnly)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	users, err := h.list(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if u.Username == data.Username {
+			return user.ErrUserAlreadyExist
+		}
+	}
+
+	if err = hashPassword(data); err != nil {
+		return err
+	}
+	data.ID = 0
+	data.SetVersion(0)
+
+	// Username is unique, so the deleted user is restored
+	deleted, err := h.deleted(ctx, data.Username)
+	if err != nil {
+		return err
+	}
+	if deleted != nil {
+		data.ID = deleted.ID
+		data.CreatedAt = deleted.CreatedAt
+		data.DeletedAt = nil
+		data.SetVersion(deleted.GetVersion() + 1)
+		err = h.repo.Update(ctx, data)
+	} else {
+		err = h.repo.Create(ctx, data)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("User %s created with role %s", data.Username, data.Role)
+
+	return nil
+}
+
+// Update change user. The password is changed only if provided
+func (h *userUsecase) Update(ctx context.Context, data *models.User) error {
+	if data == nil {
+		return errors.New("User can't be null")
+	}
+	if data.Username == "" {
+		return errors.Wrap(user.ErrInvalidUser, "username is required")
+	}
+	if !models.IsValidRole(data.Role) {
+		return errors.Wrapf(user.ErrInvalidUser, "role must be %s, %s or %s", models.RoleAdmin, models.RoleOperator, models.RoleReadOnly)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	current, err := h.get(ctx, data.ID)
+	if err != nil {
+		return err
+	}
+
+	users, err := h.list(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if u.Username == data.Username && u.ID != data.ID {
+			return user.ErrUserAlreadyExist
+		}
+	}
+	if current.Role == models.RoleAdmin && data.Role != models.RoleAdmin && countAdmins(users) <= 1 {
+		return user.ErrLastAdmin
+	}
+
+	if data.Password != "" {
+		if err = hashPassword(data); err != nil {
+			return err
+		}
+	} else {
+		data.PasswordHash = current.PasswordHash
+	}
+	data.CreatedAt = current.CreatedAt
+	data.SetVersion(current.GetVersion() + 1)
+
+	if err = h.repo.Update(ctx, data); err != nil {
+		return err
+	}
+	log.Infof("User %s updated", data.Username)
+
+	return nil
+}
+
+// Delete remove user, it is kept on repository as deleted
+func (h *userUsecase) Delete(ctx context.Context, id uint) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	current, err := h.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if current.Role == models.RoleAdmin {
+		users, err := h.list(ctx)
+		if err != nil {
+			return err
+		}
+		if countAdmins(users) <= 1 {
+			return user.ErrLastAdmin
+		}
+	}
+
+	if err := h.repo.Delete(ctx, id, current); err != nil {
+		return err
+	}
+	log.Infof("User %s deleted", current.Username)
+
+	return nil
+}
+
+// Authenticate return the user if password match
+func (h *userUsecase) Authenticate(ctx context.Context, username string, password string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	u, err := h.byUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if u == nil {
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(password))
+		return nil, user.ErrBadCredentials
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return nil, user.ErrBadCredentials
+	}
+
+	return u, nil
+}
+
+// Init create the admin user when there are no user yet
+// It permit to keep the account from config file on first start
+func (h *userUsecase) Init(ctx context.Context, username string, password string) error {
+	initCtx, cancel := context.WithTimeout(ctx, h.contextTimeout)
+	defer cancel()
+
+	users, err := h.list(initCtx)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return nil
+	}
+
+	log.Infof("No user found, create admin user %s", username)
+	return h.Create(ctx, &models.User{
+		Username: username,
+		Password: password,
+		Role:     models.RoleAdmin,
+	})
+}
+
+// get return the user with ID, deleted users are not found
+func (h *userUsecase) get(ctx context.Context, id uint) (*models.User, error) {
+	data := &models.User{}
+	if err := h.repo.Get(ctx, id, data); err != nil {
+		return nil, err
+	}
+	if data.DeletedAt != nil {
+		return nil, repository.ErrRecordNotFoundError
+	}
+
+	return data, nil
+}
+
+// list return the users that are not deleted
+func (h *userUsecase) list(ctx context.Context) ([]*models.User, error) {
+	users := make([]*models.User, 0)
+	if err := h.repo.List(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	current := make([]*models.User, 0, len(users))
+	for _, u := range users {
+		if u.DeletedAt == nil {
+			current = append(current, u)
+		}
+	}
+
+	return current, nil
+}
+
+// byUsername return the user with username that is not deleted, or nil if not exist
+// It only read this user when repository support search
+func (h *userUsecase) byUsername(ctx context.Context, username string) (*models.User, error) {
+	users := make([]*models.User, 0, 1)
+	if repo, ok := h.repo.(repository.SearchRepository); ok {
+		if _, err := repo.Search(ctx, &repository.Query{
+			Filters: map[string]interface{}{
+				"username": username,
+			},
+			Size: 1,
+		}, &users); err != nil {
+			return nil, err
+		}
+	} else if err := h.repo.List(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if u.DeletedAt == nil && u.Username == username {
+			return u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// deleted return the deleted user with username, or nil if not exist
+func (h *userUsecase) deleted(ctx context.Context, username string) (*models.User, error) {
+	users := make([]*models.User, 0)
+	if err := h.repo.List(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if u.DeletedAt != nil && u.Username == username {
+			return u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func hashPassword(data *models.User) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	data.PasswordHash = string(hash)
+	data.Password = ""
+
+	return nil
+}
+
+func countAdmins(users []*models.User) int {
+	nb := 0
+	for _, u := range users {
+		if u.Role == models.RoleAdmin {
+			nb++
+		}
+	}
+
+	return nb
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/repository"
+	"github.com/disaster37/gobot-fat/user"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryRepository store users on memory
+type memoryRepository struct {
+	users  map[uint]models.User
+	lastID uint
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		users: map[uint]models.User{},
+	}
+}
+
+func (m *memoryRepository) Get(ctx context.Context, id uint, data interface{}) error {
+	u, ok := m.users[id]
+	if !ok {
+		return repository.ErrRecordNotFoundError
+	}
+	*data.(*models.User) = u
+	return nil
+}
+
+func (m *memoryRepository) List(ctx context.Context, listData interface{}) error {
+	users := listData.(*[]*models.User)
+	for id := uint(1); id <= m.lastID; id++ {
+		if u, ok := m.users[id]; ok {
+			*users = append(*users, &u)
+		}
+	}
+	return nil
+}
+
+func (m *memoryRepository) Update(ctx context.Context, data interface{}) error {
+	u := data.(*models.User)
+	m.users[u.ID] = *u
+	return nil
+}
+
+func (m *memoryRepository) Create(ctx context.Context, data interface{}) error {
+	u := data.(*models.User)
+	m.lastID++
+	u.ID = m.lastID
+	m.users[u.ID] = *u
+	return nil
+}
+
+// Delete keep user as deleted, like SQL repository
+func (m *memoryRepository) Delete(ctx context.Context, id uint, data interface{}) error {
+	u, ok := m.users[id]
+	if !ok || u.DeletedAt != nil {
+		return repository.ErrRecordNotFoundError
+	}
+	now := time.Now()
+	u.DeletedAt = &now
+	m.users[id] = u
+	return nil
+}
+
+func TestUserUsecase(t *testing.T) {
+	repo := newMemoryRepository()
+	us := NewUserUsecase(repo, 10*time.Second)
+	ctx := context.Background()
+
+	// Init create admin only once
+	assert.NoError(t, us.Init(ctx, "admin", "admin"))
+	assert.NoError(t, us.Init(ctx, "admin2", "admin2"))
+	users, err := us.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, models.RoleAdmin, users[0].Role)
+	assert.NotEqual(t, "admin", users[0].PasswordHash)
+	assert.Empty(t, users[0].Password)
+
+	// Authenticate
+	u, err := us.Authenticate(ctx, "admin", "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", u.Username)
+	_, err = us.Authenticate(ctx, "admin", "bad")
+	assert.True(t, errors.Is(err, user.ErrBadCredentials))
+	_, err = us.Authenticate(ctx, "unknown", "admin")
+	assert.True(t, errors.Is(err, user.ErrBadCredentials))
+
+	// Create
+	operator := &models.User{Username: "operator", Password: "secret", Role: models.RoleOperator}
+	assert.NoError(t, us.Create(ctx, operator))
+	assert.Equal(t, uint(2), operator.ID)
+	assert.Empty(t, operator.Password)
+	err = us.Create(ctx, &models.User{Username: "operator", Password: "secret", Role: models.RoleOperator})
+	assert.True(t, errors.Is(err, user.ErrUserAlreadyExist))
+	err = us.Create(ctx, &models.User{Username: "bad", Password: "secret", Role: "bad"})
+	assert.True(t, errors.Is(err, user.ErrInvalidUser))
+	err = us.Create(ctx, &models.User{Username: "bad", Role: models.RoleReadOnly})
+	assert.True(t, errors.Is(err, user.ErrInvalidUser))
+
+	// Update role keep password
+	assert.NoError(t, us.Update(ctx, &models.User{ID: 2, Username: "operator", Role: models.RoleReadOnly}))
+	u, err = us.Authenticate(ctx, "operator", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleReadOnly, u.Role)
+	assert.Equal(t, int64(1), u.Version)
+
+	// Update password
+	assert.NoError(t, us.Update(ctx, &models.User{ID: 2, Username: "operator", Password: "new", Role: models.RoleReadOnly}))
+	_, err = us.Authenticate(ctx, "operator", "new")
+	assert.NoError(t, err)
+
+	// Update not found user
+	err = us.Update(ctx, &models.User{ID: 10, Username: "unknown", Role: models.RoleReadOnly})
+	assert.True(t, errors.Is(err, repository.ErrRecordNotFoundError))
+
+	// Can't remove last admin
+	err = us.Update(ctx, &models.User{ID: 1, Username: "admin", Role: models.RoleOperator})
+	assert.True(t, errors.Is(err, user.ErrLastAdmin))
+	err = us.Delete(ctx, 1)
+	assert.True(t, errors.Is(err, user.ErrLastAdmin))
+
+	// Get by username
+	u, err = us.GetByUsername(ctx, "operator")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), u.ID)
+	_, err = us.GetByUsername(ctx, "unknown")
+	assert.True(t, errors.Is(err, repository.ErrRecordNotFoundError))
+
+	// Delete keep user as deleted
+	assert.NoError(t, us.Delete(ctx, 2))
+	_, err = us.Get(ctx, 2)
+	assert.True(t, errors.Is(err, repository.ErrRecordNotFoundError))
+	_, err = us.GetByUsername(ctx, "operator")
+	assert.True(t, errors.Is(err, repository.ErrRecordNotFoundError))
+	_, err = us.Authenticate(ctx, "operator", "new")
+	assert.True(t, errors.Is(err, user.ErrBadCredentials))
+	assert.NotNil(t, repo.users[2].DeletedAt)
+	err = us.Delete(ctx, 2)
+	assert.True(t, errors.Is(err, repository.ErrRecordNotFoundError))
+	users, err = us.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	// Deleted user is restored when created again
+	operator = &models.User{Username: "operator", Password: "other", Role: models.RoleOperator}
+	assert.NoError(t, us.Create(ctx, operator))
+	assert.Equal(t, uint(2), operator.ID)
+	u, err = us.Authenticate(ctx, "operator", "other")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleOperator, u.Role)
+}
+
+func TestUserUsecaseSQL(t *testing.T) {
+	conn, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "gobot-fat.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.AutoMigrate(&models.User{}); err != nil {
+		t.Fatal(err)
+	}
+	us := NewUserUsecase(repository.NewSQLRepository(conn), 10*time.Second)
+	ctx := context.Background()
+	assert.NoError(t, us.Init(ctx, "admin", "admin"))
+	operator := &models.User{Username: "operator", Password: "operator", Role: models.RoleOperator}
+	assert.NoError(t, us.Create(ctx, operator))
+
+	// User is read by username
+	u, err := us.GetByUsername(ctx, "operator")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleOperator, u.Role)
+	u, err = us.Authenticate(ctx, "operator", "operator")
+	assert.NoError(t, err)
+	assert.Equal(t, operator.ID, u.ID)
+
+	// Deleted or unknown user is not found
+	assert.NoError(t, us.Delete(ctx, operator.ID))
+	_, err = us.GetByUsername(ctx, "operator")
+	assert.True(t, repository.IsRecordNotFoundError(err))
+	_, err = us.Authenticate(ctx, "operator", "operator")
+	assert.ErrorIs(t, err, user.ErrBadCredentials)
+	_, err = us.Authenticate(ctx, "unknown", "operator")
+	assert.ErrorIs(t, err, user.ErrBadCredentials)
+}
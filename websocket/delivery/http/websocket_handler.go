@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	ws "golang.org/x/net/websocket"
+)
+
+// WebsocketHandler represent the websocket handler
+type WebsocketHandler struct {
+	dUsecase    websocket.Usecase
+	checkOrigin func(req *http.Request) bool
+}
+
+// NewWebsocketHandler will initialize the websocket endpoint
+// The checkOrigin return false to reject connection from other web site
+func NewWebsocketHandler(e *echo.Group, us websocket.Usecase, checkOrigin func(req *http.Request) bool) {
+	handler := &WebsocketHandler{
+		dUsecase:    us,
+		checkOrigin: checkOrigin,
+	}
+	e.GET("/ws", handler.Feed)
+}
+
+// Feed push board changes to client
+// Client can filter boards and topics with query parameters `boards` and `topics` (comma separated),
+// or by sending subscribe request like `{"action": "subscribe", "boards": ["tfp"], "topics": ["state", "io"]}`
+func (h *WebsocketHandler) Feed(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	server := ws.Server{
+		// Browsers not apply CORS on websocket, so any web site can open it with the token
+		Handshake: func(config *ws.Config, req *http.Request) error {
+			if !h.checkOrigin(req) {
+				log.Warnf("Websocket connection refused from origin %s", req.Header.Get(echo.HeaderOrigin))
+				return errors.New("Origin not allowed")
+			}
+			return nil
+		},
+		Handler: func(conn *ws.Conn) {
+			defer func() { _ = conn.Close() }()
+
+			subscriber := h.dUsecase.Subscribe()
+			defer h.dUsecase.Unsubscribe(subscriber)
+			subscriber.SetFilter(splitParam(c.QueryParam("boards")), splitParam(c.QueryParam("topics")))
+
+			if err := h.sendSnapshots(ctx, conn, subscriber); err != nil {
+				log.Errorf("Error when send snapshots on websocket: %s", err.Error())
+				return
+			}
+
+			// Read client requests
+			chClosed := make(chan bool)
+			go func() {
+				defer close(chClosed)
+				for {
+					request := &models.WebsocketRequest{}
+					if err := ws.JSON.Receive(conn, request); err != nil {
+						log.Debugf("Websocket client disconnected: %s", err.Error())
+						return
+					}
+
+					switch request.Action {
+					case websocket.ActionSubscribe:
+						subscriber.SetFilter(request.Boards, request.Topics)
+						if err := h.sendSnapshots(ctx, conn, subscriber); err != nil {
+							log.Errorf("Error when send snapshots on websocket: %s", err.Error())
+							return
+						}
+					default:
+						log.Warnf("Websocket action %s not supported", request.Action)
+					}
+				}
+			}()
+
+			// Push messages
+			for {
+				select {
+				case <-chClosed:
+					return
+				case msg, ok := <-subscriber.Messages():
+					if !ok {
+						return
+					}
+					if err := ws.JSON.Send(conn, msg); err != nil {
+						log.Errorf("Error when send message on websocket: %s", err.Error())
+						return
+					}
+				}
+			}
+		},
+	}
+
+	server.ServeHTTP(c.Response(), c.Request())
+
+	return nil
+}
+
+// sendSnapshots send the current data that match subscriber filters
+func (h *WebsocketHandler) sendSnapshots(ctx context.Context, conn *ws.Conn, subscriber *websocket.Subscriber) error {
+	messages, err := h.dUsecase.Snapshots(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if subscriber.Match(msg) {
+			if err = ws.JSON.Send(conn, msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func splitParam(param string) []string {
+	if param == "" {
+		return nil
+	}
+
+	return strings.Split(param, ",")
+}
@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+	"github.com/disaster37/gobot-fat/dfp"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/tank"
+	"github.com/disaster37/gobot-fat/tfp"
+	"github.com/disaster37/gobot-fat/websocket"
+	log "github.com/sirupsen/logrus"
+	"gobot.io/x/gobot/v2"
+)
+
+type websocketUsecase struct {
+	boardUsecase  board.Usecase
+	globalEventer gobot.Eventer
+	interval      time.Duration
+	subscribers   map[*websocket.Subscriber]bool
+	lastMessages  map[string][]byte
+	watchedBoards map[board.Board]chan bool
+	chStop        chan bool
+	isStarted     bool
+	sync.Mutex
+}
+
+// NewWebsocketUsecase will create new websocketUsecase object of websocket.Usecase interface
+// It watch events from global eventer and from each board eventer. It also check each interval the board data,
+// because of some changes like relay state are not notified by event.
+func NewWebsocketUsecase(boardUsecase board.Usecase, eventer gobot.Eventer, interval time.Duration) websocket.Usecase {
+	return &websocketUsecase{
+		boardUsecase:  boardUsecase,
+		globalEventer: eventer,
+		interval:      interval,
+		subscribers:   make(map[*websocket.Subscriber]bool),
+		lastMessages:  make(map[string][]byte),
+		watchedBoards: make(map[board.Board]chan bool),
+	}
+}
+
+// Start watch boards and push changes to subscribers
+func (h *websocketUsecase) Start(ctx context.Context) {
+	h.Lock()
+	if h.isStarted {
+		h.Unlock()
+		return
+	}
+	h.isStarted = true
+	h.chStop = make(chan bool)
+	h.Unlock()
+
+	// Changes on global eventer (new state, new config, security...) can impact all boards
+	h.watch(h.globalEventer, h.chStop, func(evt *gobot.Event) {
+		h.refreshAll(evt.Name)
+	})
+
+	h.syncBoards()
+	h.refreshAll("")
+
+	go func() {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				h.Stop(context.Background())
+				return
+			case <-h.chStop:
+				return
+			case <-ticker.C:
+				h.syncBoards()
+				h.refreshAll("")
+			}
+		}
+	}()
+
+	log.Info("Websocket usecase started")
+}
+
+// Stop watching boards
+func (h *websocketUsecase) Stop(ctx context.Context) {
+	h.Lock()
+	defer h.Unlock()
+
+	if !h.isStarted {
+		return
+	}
+
+	close(h.chStop)
+	h.watchedBoards = make(map[board.Board]chan bool)
+	h.isStarted = false
+
+	log.Info("Websocket usecase stopped")
+}
+
+// Subscribe register a new subscriber
+func (h *websocketUsecase) Subscribe() *websocket.Subscriber {
+	h.Lock()
+	defer h.Unlock()
+
+	subscriber := websocket.NewSubscriber(100)
+	h.subscribers[subscriber] = true
+
+	return subscriber
+}
+
+// Unsubscribe remove subscriber and close it
+func (h *websocketUsecase) Unsubscribe(subscriber *websocket.Subscriber) {
+	h.Lock()
+	defer h.Unlock()
+
+	delete(h.subscribers, subscriber)
+	subscriber.Close()
+}
+
+// Snapshots return the current data of all boards
+func (h *websocketUsecase) Snapshots(ctx context.Context) ([]*models.WebsocketMessage, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		messages := make([]*models.WebsocketMessage, 0)
+		for _, b := range h.boardUsecase.Boards() {
+			messages = append(messages, snapshots(ctx, b, "")...)
+		}
+
+		return messages, nil
+	}
+}
+
+// syncBoards start to watch board eventer for new boards
+func (h *websocketUsecase) syncBoards() {
+	h.Lock()
+	defer h.Unlock()
+
+	if !h.isStarted {
+		return
+	}
+
+	boards := h.boardUsecase.Boards()
+	currentBoards := make(map[board.Board]bool, len(boards))
+	for _, b := range boards {
+		currentBoards[b] = true
+		if _, ok := h.watchedBoards[b]; ok {
+			continue
+		}
+
+		chStop := make(chan bool)
+		h.watchedBoards[b] = chStop
+		if eventer, ok := b.(gobot.Eventer); ok {
+			currentBoard := b
+			h.watch(eventer, chStop, func(evt *gobot.Event) {
+				h.refresh(currentBoard, evt.Name)
+			})
+		}
+		log.Debugf("Websocket watch board %s", b.Name())
+	}
+
+	// Stop watching removed boards
+	for b, chStop := range h.watchedBoards {
+		if !currentBoards[b] {
+			close(chStop)
+			delete(h.watchedBoards, b)
+		}
+	}
+}
+
+// watch read eventer on background while stop channel is not closed
+func (h *websocketUsecase) watch(eventer gobot.Eventer, chStop chan bool, f func(evt *gobot.Event)) {
+	chGlobalStop := h.chStop
+	go func() {
+		out := eventer.Subscribe()
+		defer eventer.Unsubscribe(out)
+
+		for {
+			select {
+			case <-chStop:
+				return
+			case <-chGlobalStop:
+				return
+			case evt := <-out:
+				f(evt)
+			}
+		}
+	}()
+}
+
+// refreshAll compute new messages for all boards
+func (h *websocketUsecase) refreshAll(eventName string) {
+	for _, b := range h.boardUsecase.Boards() {
+		h.refresh(b, eventName)
+	}
+}
+
+// refresh compute new messages for board and send them if data changed since the last time
+func (h *websocketUsecase) refresh(b board.Board, eventName string) {
+	messages := snapshots(context.Background(), b, eventName)
+
+	h.Lock()
+	defer h.Unlock()
+
+	for _, msg := range messages {
+		data, err := json.Marshal(msg.Data)
+		if err != nil {
+			log.Errorf("Error when marshal websocket message for board %s: %s", msg.Board, err.Error())
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", msg.Board, msg.Topic)
+		if string(h.lastMessages[key]) == string(data) {
+			continue
+		}
+		h.lastMessages[key] = data
+
+		for subscriber := range h.subscribers {
+			if subscriber.Match(msg) && !subscriber.Send(msg) {
+				log.Warnf("Websocket message dropped for slow subscriber: %s", key)
+			}
+		}
+	}
+}
+
+// snapshots return the current data of board for each topic
+func snapshots(ctx context.Context, b board.Board, eventName string) []*models.WebsocketMessage {
+	now := time.Now()
+	newMessage := func(topic string, data interface{}) *models.WebsocketMessage {
+		return &models.WebsocketMessage{
+			Topic:     topic,
+			Board:     b.Name(),
+			Event:     eventName,
+			Timestamp: now,
+			Data:      data,
+		}
+	}
+
+	messages := []*models.WebsocketMessage{
+		newMessage(websocket.TopicBoard, b.Board()),
+	}
+
+	switch currentBoard := b.(type) {
+	case dfp.Board:
+		state := currentBoard.State()
+		io := currentBoard.IO()
+		messages = append(messages, newMessage(websocket.TopicState, &state), newMessage(websocket.TopicIO, &io))
+	case tfp.Board:
+		state := currentBoard.State()
+		io := currentBoard.IO()
+		messages = append(messages, newMessage(websocket.TopicState, &state), newMessage(websocket.TopicIO, &io))
+	case tank.Board:
+		data, err := currentBoard.GetData(ctx)
+		if err != nil {
+			log.Errorf("Error when get tank data on board %s: %s", b.Name(), err.Error())
+			break
+		}
+		if data != nil {
+			tankData := *data
+			messages = append(messages, newMessage(websocket.TopicTank, &tankData))
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/disaster37/gobot-fat/board"
+	boardUsecase "github.com/disaster37/gobot-fat/board/usecase"
+	"github.com/disaster37/gobot-fat/models"
+	"github.com/disaster37/gobot-fat/websocket"
+	"github.com/stretchr/testify/assert"
+	"gobot.io/x/gobot/v2"
+)
+
+type fakeTankBoard struct {
+	name     string
+	isOnline bool
+	data     *models.Tank
+	gobot.Eventer
+}
+
+func (h *fakeTankBoard) IsOnline() bool                  { return h.isOnline }
+func (h *fakeTankBoard) Start(ctx context.Context) error { return nil }
+func (h *fakeTankBoard) Stop(ctx context.Context) error  { return nil }
+func (h *fakeTankBoard) Name() string                    { return h.name }
+func (h *fakeTankBoard) Board() *models.Board {
+	return &models.Board{Name: h.name, IsOnline: h.isOnline}
+}
+func (h *fakeTankBoard) Diagnostics() *board.Diagnostics {
+	return board.NewDiagnostics(h.name)
+}
+func (h *fakeTankBoard) GetData(ctx context.Context) (*models.Tank, error) { return h.data, nil }
+
+func waitMessage(subscriber *websocket.Subscriber, topic string, timeout time.Duration) *models.WebsocketMessage {
+	for {
+		select {
+		case msg := <-subscriber.Messages():
+			if msg.Topic == topic {
+				return msg
+			}
+		case <-time.After(timeout):
+			return nil
+		}
+	}
+}
+
+func TestWebsocketUsecase(t *testing.T) {
+	ctx := context.Background()
+	eventer := gobot.NewEventer()
+	tankBoard := &fakeTankBoard{
+		name:     "tank",
+		isOnline: true,
+		data:     &models.Tank{ID: "tank", Level: 10},
+		Eventer:  gobot.NewEventer(),
+	}
+	boardU := boardUsecase.NewBoardUsecase(nil, 10*time.Second)
+	boardU.AddBoard(tankBoard)
+
+	us := NewWebsocketUsecase(boardU, eventer, 10*time.Millisecond)
+	subscriber := us.Subscribe()
+	subscriber.SetFilter([]string{"tank"}, []string{websocket.TopicTank})
+	us.Start(ctx)
+	defer us.Stop(ctx)
+
+	// Initial data
+	msg := waitMessage(subscriber, websocket.TopicTank, 100*time.Millisecond)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, "tank", msg.Board)
+		assert.Equal(t, 10, msg.Data.(*models.Tank).Level)
+	}
+
+	// Push only when data change
+	msg = waitMessage(subscriber, websocket.TopicTank, 50*time.Millisecond)
+	assert.Nil(t, msg)
+
+	// Push when board event
+	tankBoard.data.Level = 20
+	tankBoard.Publish("new-distance", nil)
+	msg = waitMessage(subscriber, websocket.TopicTank, 100*time.Millisecond)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, 20, msg.Data.(*models.Tank).Level)
+	}
+
+	// Filter on topic
+	tankBoard.isOnline = false
+	msg = waitMessage(subscriber, websocket.TopicBoard, 50*time.Millisecond)
+	assert.Nil(t, msg)
+
+	subscriber.SetFilter(nil, nil)
+	tankBoard.isOnline = true
+	msg = waitMessage(subscriber, websocket.TopicBoard, 100*time.Millisecond)
+	if assert.NotNil(t, msg) {
+		assert.True(t, msg.Data.(*models.Board).IsOnline)
+	}
+
+	// Snapshots
+	messages, err := us.Snapshots(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+
+	// Unsubscribe close channel
+	us.Unsubscribe(subscriber)
+	_, ok := <-subscriber.Messages()
+	assert.False(t, ok)
+}
@@ -1 +1,131 @@
 package websocket
+
+import (
+	"context"
+	"sync"
+
+	"github.com/disaster37/gobot-fat/models"
+)
+
+const (
+	// TopicState is the DFP / TFP state
+	TopicState = "state"
+
+	// TopicIO is the DFP / TFP inputs and outputs
+	TopicIO = "io"
+
+	// TopicTank is the tank level
+	TopicTank = "tank"
+
+	// TopicBoard is the board online / offline status
+	TopicBoard = "board"
+
+	// ActionSubscribe is the client action to change subscription
+	ActionSubscribe = "subscribe"
+)
+
+// Usecase is the websocket usecase interface
+type Usecase interface {
+	// Start watch boards and push changes to subscribers
+	Start(ctx context.Context)
+
+	// Stop watching boards
+	Stop(ctx context.Context)
+
+	// Subscribe register a new subscriber
+	Subscribe() *Subscriber
+
+	// Unsubscribe remove subscriber and close it
+	Unsubscribe(subscriber *Subscriber)
+
+	// Snapshots return the current data of all boards
+	Snapshots(ctx context.Context) ([]*models.WebsocketMessage, error)
+}
+
+// Subscriber receive messages that match its filters
+type Subscriber struct {
+	boards    map[string]bool
+	topics    map[string]bool
+	chMessage chan *models.WebsocketMessage
+	isClosed  bool
+	sync.RWMutex
+}
+
+// NewSubscriber create new subscriber without filter
+func NewSubscriber(size int) *Subscriber {
+	return &Subscriber{
+		boards:    make(map[string]bool),
+		topics:    make(map[string]bool),
+		chMessage: make(chan *models.WebsocketMessage, size),
+	}
+}
+
+// SetFilter permit to follow only some boards and topics
+// Empty list follow all
+func (h *Subscriber) SetFilter(boards []string, topics []string) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.boards = make(map[string]bool)
+	for _, board := range boards {
+		if board != "" {
+			h.boards[board] = true
+		}
+	}
+
+	h.topics = make(map[string]bool)
+	for _, topic := range topics {
+		if topic != "" {
+			h.topics[topic] = true
+		}
+	}
+}
+
+// Match return true if message match subscriber filters
+func (h *Subscriber) Match(msg *models.WebsocketMessage) bool {
+	h.RLock()
+	defer h.RUnlock()
+
+	if len(h.boards) > 0 && !h.boards[msg.Board] {
+		return false
+	}
+	if len(h.topics) > 0 && !h.topics[msg.Topic] {
+		return false
+	}
+
+	return true
+}
+
+// Send push message to subscriber without blocking
+// It return false if message is dropped because of subscriber is too slow or closed
+func (h *Subscriber) Send(msg *models.WebsocketMessage) bool {
+	h.RLock()
+	defer h.RUnlock()
+
+	if h.isClosed {
+		return false
+	}
+
+	select {
+	case h.chMessage <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// Messages return the channel to read messages
+func (h *Subscriber) Messages() <-chan *models.WebsocketMessage {
+	return h.chMessage
+}
+
+// Close close the messages channel
+func (h *Subscriber) Close() {
+	h.Lock()
+	defer h.Unlock()
+
+	if !h.isClosed {
+		h.isClosed = true
+		close(h.chMessage)
+	}
+}